@@ -0,0 +1,237 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"go-products.com/m/internal/config"
+	"go-products.com/m/internal/product/infrastructure/persistance/migrations"
+	"go-products.com/m/internal/shared/database"
+	"go-products.com/m/internal/shared/logging"
+)
+
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// environment what every command runs with, output is written to stdout and logs to stderr except for serve, which
+// logs to stdout as it always did
+type environment struct {
+	config config.Config
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+}
+
+type command struct {
+	usage       string
+	description string
+	run         func(ctx context.Context, env environment, args []string) error
+}
+
+var commands = map[string]command{
+	"serve": {
+		usage:       "serve",
+		description: "migrate the database and run the HTTP server, the default command",
+		run:         serve,
+	},
+	"migrate": {
+		usage:       "migrate up|down [--steps n]|status",
+		description: "apply, revert or list schema migrations",
+		run:         migrate,
+	},
+	"seed": {
+		usage:       "seed --file path [--type products|categories] [--mode insert|upsert]",
+		description: "import products or categories from a JSON file",
+		run:         seed,
+	},
+	"export": {
+		usage:       "export [--format json|csv] [--output path]",
+		description: "dump every product, the JSON output can be seeded back",
+		run:         export,
+	},
+	"products": {
+		usage:       "products get <sku> | list [--category c] [--price-less-than p] [--status s] [--limit n]",
+		description: "query products with their discounted prices",
+		run:         products,
+	},
+	"keys": {
+		usage:       "keys issue --name name --role viewer|editor|admin | revoke <id> | list",
+		description: "manage the API keys allowed to call protected routes",
+		run:         keys,
+	},
+	"discount": {
+		usage:       "discount preview --sku sku",
+		description: "explain which discount rules apply to a product",
+		run:         discount,
+	},
+}
+
+// errUsage the command was called with wrong arguments, the usage is printed and the exit code is 2
+type errUsage struct {
+	message string
+}
+
+func (e errUsage) Error() string {
+	return e.message
+}
+
+func usageErrorf(format string, args ...any) error {
+	return errUsage{message: fmt.Sprintf(format, args...)}
+}
+
+// Run executes the command given in args, without the program name, and returns the exit code. Configuration flags
+// go before the command, serve runs when there is none
+func Run(ctx context.Context, name string, args []string, stdout, stderr io.Writer, lookupEnv func(string) (string, bool)) int {
+	cfg, flags, err := config.Load(name, args, stderr, lookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		printUsage(stderr, name)
+
+		return exitOK
+	}
+
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return exitUsage
+	}
+
+	if flags.PrintConfig {
+		if err := cfg.Print(stdout); err != nil {
+			fmt.Fprintln(stderr, err)
+
+			return exitError
+		}
+
+		return exitOK
+	}
+
+	commandName, commandArgs := "serve", flags.Args
+	if len(commandArgs) > 0 {
+		commandName, commandArgs = commandArgs[0], commandArgs[1:]
+	}
+
+	cmd, ok := commands[commandName]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n", commandName)
+		printUsage(stderr, name)
+
+		return exitUsage
+	}
+
+	logOutput := stderr
+	if commandName == "serve" {
+		logOutput = stdout
+	}
+
+	logger, err := logging.New(logOutput, logging.Options{Format: cfg.Log.Format, Level: cfg.Log.Level})
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return exitUsage
+	}
+
+	env := environment{config: cfg, logger: logger, stdout: stdout, stderr: stderr}
+	err = cmd.run(logging.WithLogger(ctx, logger), env, commandArgs)
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+
+	var usageErr errUsage
+	if errors.As(err, &usageErr) {
+		fmt.Fprintf(stderr, "%s\nusage: %s %s\n", usageErr, name, cmd.usage)
+
+		return exitUsage
+	}
+
+	if err != nil {
+		logger.Error(commandName+" failed", slog.Any("error", err))
+
+		return exitError
+	}
+
+	return exitOK
+}
+
+func printUsage(output io.Writer, name string) {
+	names := make([]string, 0, len(commands))
+	for commandName := range commands {
+		names = append(names, commandName)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(output, "usage: %s [flags] [command]\n\ncommands:\n", name)
+	for _, commandName := range names {
+		fmt.Fprintf(output, "  %-10s %s\n", commandName, commands[commandName].description)
+	}
+	fmt.Fprintf(output, "\nrun %s --help for the flags\n", name)
+}
+
+// newFlagSet flags of a command, parse errors are reported as usage errors
+func newFlagSet(env environment, name string) *flag.FlagSet {
+	flagSet := flag.NewFlagSet(name, flag.ContinueOnError)
+	flagSet.SetOutput(env.stderr)
+
+	return flagSet
+}
+
+func parseFlags(flagSet *flag.FlagSet, args []string) error {
+	if err := flagSet.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+
+		return errUsage{message: err.Error()}
+	}
+
+	return nil
+}
+
+// openDatabase opens the configured database without migrating it
+func openDatabase(env environment) (*sql.DB, error) {
+	db, err := database.GenerateDatabaseConnection(database.DatabaseConnection{
+		DatabaseName:       env.config.Database.Path,
+		MaxOpenConnections: env.config.Database.MaxOpenConnections,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to database: %w", err)
+	}
+
+	return db, nil
+}
+
+// openMigratedDatabase opens the configured database, commands other than serve and migrate refuse to run against an
+// outdated schema instead of migrating it behind the user's back
+func openMigratedDatabase(ctx context.Context, env environment) (*sql.DB, error) {
+	db, err := openDatabase(env)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := migrations.CheckProductsDatabase(ctx, db); err != nil {
+		_ = db.Close()
+
+		return nil, fmt.Errorf("database %s is not up to date, run migrate up: %w", env.config.Database.Path, err)
+	}
+
+	return db, nil
+}
+
+func closeDatabase(env environment, db *sql.DB) {
+	if err := db.Close(); err != nil {
+		env.logger.Error("closing database", slog.Any("error", err))
+	}
+}
+
+func joinArgs(args []string) string {
+	return strings.Join(args, " ")
+}
@@ -0,0 +1,261 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/product/infrastructure/persistance"
+	"go-products.com/m/internal/product/infrastructure/persistance/migrations"
+	"go-products.com/m/internal/shared/auth"
+	"go-products.com/m/internal/shared/database"
+)
+
+func noEnv(string) (string, bool) {
+	return "", false
+}
+
+// run executes the command against databasePath and returns the exit code with stdout and stderr
+func run(databasePath string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	args = append([]string{"--database.path=" + databasePath, "--log.level=warn"}, args...)
+	code := Run(context.Background(), "products", args, &stdout, &stderr, noEnv)
+
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun_Migrate(t *testing.T) {
+	assertions := require.New(t)
+	databasePath := filepath.Join(t.TempDir(), "products.db")
+
+	code, stdout, _ := run(databasePath, "migrate", "status")
+	assertions.Equal(exitOK, code)
+	rows := migrationRows(t, stdout)
+	assertions.GreaterOrEqual(len(rows), 2)
+	for _, row := range rows {
+		assertions.Equal("pending", row[2], "migration %s", row[0])
+	}
+
+	// the newest migrations are reverted and applied again, whichever they are
+	previous, latest := strings.Join(rows[len(rows)-2][:2], " "), strings.Join(rows[len(rows)-1][:2], " ")
+
+	code, _, stderr := run(databasePath, "products", "list")
+	assertions.Equal(exitError, code)
+	assertions.Contains(stderr, "run migrate up")
+
+	code, stdout, _ = run(databasePath, "migrate", "up")
+	assertions.Equal(exitOK, code)
+	assertions.Equal(len(rows), strings.Count(stdout, "applied "))
+	assertions.Contains(stdout, "applied "+latest+"\n")
+
+	code, stdout, _ = run(databasePath, "migrate", "down", "--steps", "2")
+	assertions.Equal(exitOK, code)
+	assertions.Equal("reverted "+latest+"\nreverted "+previous+"\n", stdout)
+
+	code, stdout, _ = run(databasePath, "migrate", "status")
+	assertions.Equal(exitOK, code)
+	for i, row := range migrationRows(t, stdout) {
+		assertions.Equal(i >= len(rows)-2, row[2] == "pending", "migration %s", row[0])
+	}
+
+	code, stdout, _ = run(databasePath, "migrate", "up")
+	assertions.Equal(exitOK, code)
+	assertions.Equal("applied "+previous+"\napplied "+latest+"\n", stdout)
+}
+
+// migrationRows the version, name and applied at columns of every migration listed by migrate status
+func migrationRows(t *testing.T, stdout string) [][]string {
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Equal(t, []string{"VERSION", "NAME", "APPLIED", "AT"}, strings.Fields(lines[0]))
+
+	rows := make([][]string, 0, len(lines)-1)
+	for _, line := range lines[1:] {
+		row := strings.Fields(line)
+		require.Len(t, row, 3, "status line %q", line)
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+func TestRun_SeedExportAndQuery(t *testing.T) {
+	assertions := require.New(t)
+	directory := t.TempDir()
+	databasePath := filepath.Join(directory, "products.db")
+
+	code, _, _ := run(databasePath, "migrate", "up")
+	assertions.Equal(exitOK, code)
+
+	code, _, stderr := run(databasePath, "seed", "--type=categories", "--file=testdata/categories.json")
+	assertions.Equal(exitOK, code, stderr)
+
+	code, _, stderr = run(databasePath, "seed", "--file=testdata/products.json")
+	assertions.Equal(exitOK, code, stderr)
+
+	code, _, stderr = run(databasePath, "seed", "--file=testdata/products_update.json", "--mode=upsert")
+	assertions.Equal(exitOK, code, stderr)
+
+	code, stdout, _ := run(databasePath, "products", "get", "000001")
+	assertions.Equal(exitOK, code)
+	assertions.Contains(stdout, "000001  BV Lean leather ankle boots  boots     discontinued  85000  30%       59499        EUR")
+
+	code, stdout, _ = run(databasePath, "products", "list", "--category=sandals", "--status=draft")
+	assertions.Equal(exitOK, code)
+	assertions.Contains(stdout, "000006")
+	assertions.NotContains(stdout, "000004")
+
+	code, stdout, _ = run(databasePath, "discount", "preview", "--sku=000003")
+	assertions.Equal(exitOK, code)
+	assertions.Contains(stdout, "category  boots    any     30%       yes")
+	assertions.Contains(stdout, "sku       000003   any     15%       no")
+	assertions.Contains(stdout, "final price 49700 EUR")
+
+	exportPath := filepath.Join(directory, "export.json")
+	code, _, stderr = run(databasePath, "export", "--format=json", "--output="+exportPath)
+	assertions.Equal(exitOK, code, stderr)
+
+	exported := readProducts(t, exportPath)
+	assertions.Len(exported, 6)
+	assertions.Contains(exported, domain.CreateProductDTO{
+		Sku: "000001", Name: "BV Lean leather ankle boots", Category: "boots", Price: 85000, Status: "discontinued",
+	})
+
+	// the export seeds an empty database with the same catalog
+	restoredPath := filepath.Join(directory, "restored.db")
+	code, _, _ = run(restoredPath, "migrate", "up")
+	assertions.Equal(exitOK, code)
+	code, _, _ = run(restoredPath, "seed", "--type=categories", "--file=testdata/categories.json")
+	assertions.Equal(exitOK, code)
+	code, _, stderr = run(restoredPath, "seed", "--file="+exportPath)
+	assertions.Equal(exitOK, code, stderr)
+
+	code, stdout, _ = run(restoredPath, "export")
+	assertions.Equal(exitOK, code)
+
+	var restored []domain.CreateProductDTO
+	assertions.NoError(json.Unmarshal([]byte(stdout), &restored))
+	assertions.ElementsMatch(exported, restored)
+
+	code, stdout, _ = run(databasePath, "export", "--format=csv")
+	assertions.Equal(exitOK, code)
+	assertions.Contains(stdout, "sku,name,category,price,currency,status,version\n")
+	assertions.Contains(stdout, "000001,BV Lean leather ankle boots,boots,85000,EUR,discontinued,2\n")
+}
+
+// discount rules are seeded by the commands writing to the database, reading commands never write
+func TestRun_DiscountRulesSeeding(t *testing.T) {
+	assertions := require.New(t)
+	databasePath := filepath.Join(t.TempDir(), "products.db")
+
+	db, err := database.GenerateDatabaseConnection(database.DatabaseConnection{DatabaseName: databasePath}, nil)
+	assertions.NoError(err)
+	defer db.Close()
+
+	_, err = migrations.Up(context.Background(), db)
+	assertions.NoError(err)
+
+	rules := persistance.NewDiscountRulesSQLiteRepository(db)
+
+	code, _, stderr := run(databasePath, "products", "list")
+	assertions.Equal(exitOK, code, stderr)
+
+	stored, err := rules.GetDiscountRules(context.Background())
+	assertions.NoError(err)
+	assertions.Empty(stored)
+
+	code, _, stderr = run(databasePath, "migrate", "up")
+	assertions.Equal(exitOK, code, stderr)
+
+	stored, err = rules.GetDiscountRules(context.Background())
+	assertions.NoError(err)
+	defaults := domain.DefaultDiscountPolicy()
+	assertions.Len(stored, len(defaults.Categories)+len(defaults.Skus))
+}
+
+func TestRun_Keys(t *testing.T) {
+	assertions := require.New(t)
+	databasePath := filepath.Join(t.TempDir(), "products.db")
+
+	code, _, _ := run(databasePath, "migrate", "up")
+	assertions.Equal(exitOK, code)
+
+	code, stdout, stderr := run(databasePath, "keys", "issue", "--name=ci", "--role=editor")
+	assertions.Equal(exitOK, code, stderr)
+	token := strings.TrimSpace(stdout)
+	assertions.True(strings.HasPrefix(token, auth.APIKeyPrefix))
+
+	db, err := database.GenerateDatabaseConnection(database.DatabaseConnection{DatabaseName: databasePath}, nil)
+	assertions.NoError(err)
+	defer db.Close()
+
+	authenticator := auth.NewAPIKeyAuthenticator(auth.NewAPIKeysSQLiteRepository(db))
+	principal, err := authenticator.Authenticate(context.Background(), token)
+	assertions.NoError(err)
+	assertions.Equal(auth.RoleEditor, principal.Role)
+
+	code, stdout, _ = run(databasePath, "keys", "list")
+	assertions.Equal(exitOK, code)
+	assertions.Contains(stdout, principal.Subject+"  ci    editor")
+	assertions.NotContains(stdout, token)
+
+	code, stdout, _ = run(databasePath, "keys", "revoke", principal.Subject)
+	assertions.Equal(exitOK, code)
+	assertions.Equal("revoked "+principal.Subject+"\n", stdout)
+
+	_, err = authenticator.Authenticate(context.Background(), token)
+	assertions.ErrorIs(err, auth.ErrInvalidCredentials)
+
+	code, _, stderr = run(databasePath, "keys", "revoke", principal.Subject)
+	assertions.Equal(exitError, code)
+	assertions.Contains(stderr, "no active key with id "+principal.Subject)
+}
+
+func TestRun_UsageErrors(t *testing.T) {
+	assertions := require.New(t)
+	databasePath := filepath.Join(t.TempDir(), "products.db")
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "Unknown command", args: []string{"deploy"}, wantErr: `unknown command "deploy"`},
+		{name: "Missing migrate action", args: []string{"migrate"}, wantErr: "missing migrate action"},
+		{name: "Invalid steps", args: []string{"migrate", "down", "--steps=0"}, wantErr: "--steps must be positive"},
+		{name: "Missing seed file", args: []string{"seed"}, wantErr: "--file is required"},
+		{name: "Invalid seed mode", args: []string{"seed", "--file=x.json", "--mode=merge"}, wantErr: `invalid seed mode "merge"`},
+		{name: "Upserting categories", args: []string{"seed", "--file=x.json", "--type=categories", "--mode=upsert"}, wantErr: "categories only support insert mode"},
+		{name: "Invalid export format", args: []string{"export", "--format=xml"}, wantErr: `invalid format "xml"`},
+		{name: "Missing sku", args: []string{"products", "get"}, wantErr: "products get takes exactly one sku"},
+		{name: "Missing discount sku", args: []string{"discount", "preview"}, wantErr: "--sku is required"},
+		{name: "Invalid pricing channel", args: []string{"discount", "preview", "--sku=000003", "--channel=store"}, wantErr: "channel must be one of web, app or wholesale"},
+		{name: "Missing key name", args: []string{"keys", "issue"}, wantErr: "--name is required"},
+		{name: "Invalid key role", args: []string{"keys", "issue", "--name=ci", "--role=root"}, wantErr: `invalid role "root"`},
+		{name: "Unknown flag", args: []string{"products", "list", "--color=red"}, wantErr: "flag provided but not defined: -color"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, stderr := run(databasePath, tt.args...)
+
+			assertions.Equal(exitUsage, code)
+			assertions.Contains(stderr, tt.wantErr)
+		})
+	}
+}
+
+func readProducts(t *testing.T, path string) []domain.CreateProductDTO {
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var products []domain.CreateProductDTO
+	require.NoError(t, json.Unmarshal(content, &products))
+
+	return products
+}
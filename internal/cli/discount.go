@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"text/tabwriter"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/product/infrastructure/persistance"
+	"go-products.com/m/internal/product/infrastructure/persistance/migrations"
+	"go-products.com/m/internal/product/use_cases"
+)
+
+func discount(ctx context.Context, env environment, args []string) error {
+	if len(args) == 0 || args[0] != "preview" {
+		return usageErrorf("missing discount action, only preview is supported")
+	}
+
+	flagSet := newFlagSet(env, "discount preview")
+	sku := flagSet.String("sku", "", "product sku")
+	pricingFlags := newPricingFlags(flagSet)
+	if err := parseFlags(flagSet, args[1:]); err != nil {
+		return err
+	}
+
+	if *sku == "" {
+		return usageErrorf("--sku is required")
+	}
+
+	pricing, err := pricingFlags.pricingContext()
+	if err != nil {
+		return err
+	}
+
+	db, err := openMigratedDatabase(ctx, env)
+	if err != nil {
+		return err
+	}
+	defer closeDatabase(env, db)
+
+	product, err := use_cases.NewGetProductUseCase(persistance.NewProductsSQLiteRepository(db), discountPolicies(db)).
+		Execute(ctx, *sku, pricing)
+	if err != nil {
+		return err
+	}
+
+	applied := product.Discount
+
+	fmt.Fprintf(env.stdout, "%s %s, category %s, price %d %s\n\n", product.Sku, product.Name, product.Category,
+		product.Price, product.Currency)
+
+	if len(applied.Breakdown) == 0 {
+		fmt.Fprintln(env.stdout, "no discount rule matches, the price is unchanged")
+
+		return nil
+	}
+
+	writer := tabwriter.NewWriter(env.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "RULE\tMATCHES\tTARGET\tDISCOUNT\tAPPLIED")
+	for _, outcome := range applied.Breakdown {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n", outcome.Rule, outcome.Key, formatTarget(outcome.Target),
+			outcome.FormattedPercentage(), yesNo(outcome.Applied))
+	}
+
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.stdout, "\nthe biggest discount wins, final price %d %s\n", applied.FinalPrice, product.Currency)
+
+	return nil
+}
+
+// pricingFlags who prices are computed for, as the server resolves it from headers
+type pricingFlags struct {
+	channel *string
+	segment *string
+	country *string
+}
+
+func newPricingFlags(flagSet *flag.FlagSet) pricingFlags {
+	return pricingFlags{
+		channel: flagSet.String("channel", "", "sales channel, web, app or wholesale"),
+		segment: flagSet.String("segment", "", "customer segment"),
+		country: flagSet.String("country", "", "ISO 3166-1 country code"),
+	}
+}
+
+func (f pricingFlags) pricingContext() (domain.PricingContext, error) {
+	pricing, err := domain.NewPricingContext(*f.channel, *f.segment, *f.country)
+	if err != nil {
+		return domain.PricingContext{}, usageErrorf("%s", err)
+	}
+
+	return pricing, nil
+}
+
+// formatTarget the pricing context a discount is restricted to, any when it is granted everywhere
+func formatTarget(target domain.PricingContext) string {
+	if target.IsZero() {
+		return "any"
+	}
+
+	return target.String()
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+
+	return "no"
+}
+
+// discountPolicies the stored discount rules, as the server computes prices with them. Read commands never seed them,
+// databases serve, migrate up or seed did not run on price without discounts
+func discountPolicies(db *sql.DB) domain.DiscountPolicySource {
+	return domain.DiscountPolicySourceFunc(
+		use_cases.NewGetDiscountPolicyUseCase(persistance.NewDiscountRulesSQLiteRepository(db)).Execute,
+	)
+}
+
+// seedDiscountRules stores the rules of the configured policy in databases discount rules were never stored in
+func seedDiscountRules(ctx context.Context, env environment, db *sql.DB) error {
+	err := migrations.InitDiscountRules(ctx, persistance.NewDiscountRulesSQLiteRepository(db), env.config.DiscountPolicy())
+	if err != nil {
+		return fmt.Errorf("seeding discount rules: %w", err)
+	}
+
+	return nil
+}
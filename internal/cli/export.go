@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/product/infrastructure/persistance"
+)
+
+const (
+	exportFormatJSON = "json"
+	exportFormatCSV  = "csv"
+)
+
+func export(ctx context.Context, env environment, args []string) (err error) {
+	flagSet := newFlagSet(env, "export")
+	format := flagSet.String("format", exportFormatJSON, "output format, json or csv")
+	output := flagSet.String("output", "", "file written, stdout when empty")
+	if err := parseFlags(flagSet, args); err != nil {
+		return err
+	}
+
+	if *format != exportFormatJSON && *format != exportFormatCSV {
+		return usageErrorf("invalid format %q, must be %s or %s", *format, exportFormatJSON, exportFormatCSV)
+	}
+
+	db, err := openMigratedDatabase(ctx, env)
+	if err != nil {
+		return err
+	}
+	defer closeDatabase(env, db)
+
+	// every product is exported whatever its status so the file restores the whole catalog, prices are exported as
+	// stored so the products are read without discounts
+	products, err := persistance.NewProductsSQLiteRepository(db).GetProducts(ctx, domain.ProductsFilters{})
+	if err != nil {
+		return err
+	}
+
+	writer := env.stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+
+		writer = file
+	}
+
+	if *format == exportFormatCSV {
+		return exportCSV(writer, products)
+	}
+
+	return exportJSON(writer, products)
+}
+
+// exportJSON writes the products in the format read by seed
+func exportJSON(writer io.Writer, products []domain.Product) error {
+	dtos := make([]domain.CreateProductDTO, 0, len(products))
+	for _, product := range products {
+		dtos = append(dtos, domain.CreateProductDTO{
+			Sku:      product.Sku,
+			Name:     product.Name,
+			Category: product.Category,
+			Price:    product.Price,
+			Status:   string(product.Status),
+		})
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(dtos)
+}
+
+func exportCSV(writer io.Writer, products []domain.Product) error {
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write([]string{"sku", "name", "category", "price", "currency", "status", "version"}); err != nil {
+		return err
+	}
+
+	for _, product := range products {
+		err := csvWriter.Write([]string{
+			product.Sku,
+			product.Name,
+			product.Category,
+			strconv.Itoa(product.Price),
+			product.Currency,
+			string(product.Status),
+			strconv.Itoa(product.Version),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		return fmt.Errorf("writing csv: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"go-products.com/m/internal/shared/auth"
+)
+
+func keys(ctx context.Context, env environment, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("missing keys action")
+	}
+
+	switch action, args := args[0], args[1:]; action {
+	case "issue":
+		return issueKey(ctx, env, args)
+	case "revoke":
+		return revokeKey(ctx, env, args)
+	case "list":
+		return listKeys(ctx, env, args)
+	default:
+		return usageErrorf("unknown keys action %q", action)
+	}
+}
+
+func issueKey(ctx context.Context, env environment, args []string) error {
+	flagSet := newFlagSet(env, "keys issue")
+	name := flagSet.String("name", "", "who the key is issued to")
+	role := flagSet.String("role", string(auth.RoleViewer), "viewer, editor or admin")
+	if err := parseFlags(flagSet, args); err != nil {
+		return err
+	}
+
+	if *name == "" {
+		return usageErrorf("--name is required")
+	}
+
+	keyRole, err := auth.ParseRole(*role)
+	if err != nil {
+		return usageErrorf("%s", err)
+	}
+
+	db, err := openMigratedDatabase(ctx, env)
+	if err != nil {
+		return err
+	}
+	defer closeDatabase(env, db)
+
+	key, token, err := auth.NewAPIKey(*name, keyRole)
+	if err != nil {
+		return err
+	}
+
+	if err := auth.NewAPIKeysSQLiteRepository(db).CreateAPIKey(ctx, key); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.stderr, "issued key %s for %s with role %s, it won't be shown again\n", key.ID, key.Name, key.Role)
+	fmt.Fprintln(env.stdout, token)
+
+	return nil
+}
+
+func revokeKey(ctx context.Context, env environment, args []string) error {
+	flagSet := newFlagSet(env, "keys revoke")
+	if err := parseFlags(flagSet, args); err != nil {
+		return err
+	}
+
+	if flagSet.NArg() != 1 {
+		return usageErrorf("keys revoke takes exactly one key id")
+	}
+
+	db, err := openMigratedDatabase(ctx, env)
+	if err != nil {
+		return err
+	}
+	defer closeDatabase(env, db)
+
+	err = auth.NewAPIKeysSQLiteRepository(db).RevokeAPIKey(ctx, flagSet.Arg(0), time.Now())
+	if errors.Is(err, auth.ErrAPIKeyNotFound) {
+		return fmt.Errorf("no active key with id %s", flagSet.Arg(0))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.stdout, "revoked %s\n", flagSet.Arg(0))
+
+	return nil
+}
+
+func listKeys(ctx context.Context, env environment, args []string) error {
+	flagSet := newFlagSet(env, "keys list")
+	if err := parseFlags(flagSet, args); err != nil {
+		return err
+	}
+
+	db, err := openMigratedDatabase(ctx, env)
+	if err != nil {
+		return err
+	}
+	defer closeDatabase(env, db)
+
+	apiKeys, err := auth.NewAPIKeysSQLiteRepository(db).GetAPIKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(env.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tNAME\tROLE\tCREATED AT\tREVOKED AT")
+	for _, key := range apiKeys {
+		revokedAt := "-"
+		if key.RevokedAt != nil {
+			revokedAt = key.RevokedAt.Format(time.RFC3339)
+		}
+
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n", key.ID, key.Name, key.Role, key.CreatedAt.Format(time.RFC3339), revokedAt)
+	}
+
+	return writer.Flush()
+}
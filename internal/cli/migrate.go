@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"go-products.com/m/internal/product/infrastructure/persistance/migrations"
+)
+
+func migrate(ctx context.Context, env environment, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("missing migrate action")
+	}
+
+	action, args := args[0], args[1:]
+
+	flagSet := newFlagSet(env, "migrate "+action)
+	steps := 1
+	if action == "down" {
+		flagSet.IntVar(&steps, "steps", steps, "number of migrations reverted, newest first")
+	}
+
+	if err := parseFlags(flagSet, args); err != nil {
+		return err
+	}
+
+	if flagSet.NArg() > 0 {
+		return usageErrorf("unexpected arguments %s", joinArgs(flagSet.Args()))
+	}
+
+	db, err := openDatabase(env)
+	if err != nil {
+		return err
+	}
+	defer closeDatabase(env, db)
+
+	switch action {
+	case "up":
+		applied, err := migrations.Up(ctx, db)
+		printMigrations(env, "applied", applied)
+		if err != nil {
+			return err
+		}
+
+		return seedDiscountRules(ctx, env, db)
+	case "down":
+		if steps <= 0 {
+			return usageErrorf("--steps must be positive")
+		}
+
+		reverted, err := migrations.Down(ctx, db, steps)
+		printMigrations(env, "reverted", reverted)
+
+		return err
+	case "status":
+		statuses, err := migrations.Status(ctx, db)
+		if err != nil {
+			return err
+		}
+
+		writer := tabwriter.NewWriter(env.stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.UTC().Format(time.RFC3339)
+			}
+
+			fmt.Fprintf(writer, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+
+		return writer.Flush()
+	default:
+		return usageErrorf("unknown migrate action %q", action)
+	}
+}
+
+func printMigrations(env environment, verb string, applied []migrations.Migration) {
+	if len(applied) == 0 {
+		fmt.Fprintf(env.stdout, "no migrations %s\n", verb)
+
+		return
+	}
+
+	for _, migration := range applied {
+		fmt.Fprintf(env.stdout, "%s %d %s\n", verb, migration.Version, migration.Name)
+	}
+}
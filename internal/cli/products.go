@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/product/infrastructure/persistance"
+	"go-products.com/m/internal/product/use_cases"
+)
+
+func products(ctx context.Context, env environment, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("missing products action")
+	}
+
+	switch action, args := args[0], args[1:]; action {
+	case "get":
+		return getProduct(ctx, env, args)
+	case "list":
+		return listProducts(ctx, env, args)
+	default:
+		return usageErrorf("unknown products action %q", action)
+	}
+}
+
+func getProduct(ctx context.Context, env environment, args []string) error {
+	flagSet := newFlagSet(env, "products get")
+	pricingFlags := newPricingFlags(flagSet)
+	if err := parseFlags(flagSet, args); err != nil {
+		return err
+	}
+
+	if flagSet.NArg() != 1 {
+		return usageErrorf("products get takes exactly one sku")
+	}
+
+	pricing, err := pricingFlags.pricingContext()
+	if err != nil {
+		return err
+	}
+
+	db, err := openMigratedDatabase(ctx, env)
+	if err != nil {
+		return err
+	}
+	defer closeDatabase(env, db)
+
+	product, err := use_cases.NewGetProductUseCase(persistance.NewProductsSQLiteRepository(db), discountPolicies(db)).
+		Execute(ctx, flagSet.Arg(0), pricing)
+	if err != nil {
+		return err
+	}
+
+	return printProducts(env.stdout, []domain.PricedProduct{*product})
+}
+
+func listProducts(ctx context.Context, env environment, args []string) error {
+	flagSet := newFlagSet(env, "products list")
+	category := flagSet.String("category", "", "category, its descendants are included")
+	priceLessThan := flagSet.Int("price-less-than", 0, "maximum price, exclusive, 0 disables the filter")
+	status := flagSet.String("status", "", "product status, every status when empty")
+	limit := flagSet.Int("limit", env.config.Catalog.DefaultLimit, "maximum number of products, 0 lists all of them")
+	pricingFlags := newPricingFlags(flagSet)
+	if err := parseFlags(flagSet, args); err != nil {
+		return err
+	}
+
+	if flagSet.NArg() > 0 {
+		return usageErrorf("unexpected arguments %s", joinArgs(flagSet.Args()))
+	}
+
+	var filters domain.ProductsFilters
+	if *category != "" {
+		filters.Category = category
+	}
+
+	if *priceLessThan > 0 {
+		filters.PriceLessThan = priceLessThan
+	}
+
+	if *status != "" {
+		productStatus, err := domain.ParseProductStatus(*status)
+		if err != nil {
+			return usageErrorf("invalid status %q", *status)
+		}
+
+		filters.Status = &productStatus
+	}
+
+	if *limit < 0 {
+		return usageErrorf("--limit can't be negative")
+	}
+
+	if *limit > 0 {
+		filters.Limit = limit
+	}
+
+	pricing, err := pricingFlags.pricingContext()
+	if err != nil {
+		return err
+	}
+
+	db, err := openMigratedDatabase(ctx, env)
+	if err != nil {
+		return err
+	}
+	defer closeDatabase(env, db)
+
+	products, err := use_cases.NewGetProductsUseCase(persistance.NewProductsSQLiteRepository(db), discountPolicies(db)).
+		Execute(ctx, filters, pricing)
+	if err != nil {
+		return err
+	}
+
+	return printProducts(env.stdout, products)
+}
+
+func printProducts(output io.Writer, products []domain.PricedProduct) error {
+	writer := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "SKU\tNAME\tCATEGORY\tSTATUS\tPRICE\tDISCOUNT\tFINAL PRICE\tCURRENCY")
+	for _, product := range products {
+		percentage, ok := product.Discount.FormattedPercentage()
+		if !ok {
+			percentage = "-"
+		}
+
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%d\t%s\t%d\t%s\n", product.Sku, product.Name, product.Category,
+			product.Status, product.Price, percentage, product.Discount.FinalPrice, product.Currency)
+	}
+
+	return writer.Flush()
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"context"
+
+	"go-products.com/m/internal/product/infrastructure/persistance"
+	"go-products.com/m/internal/product/infrastructure/persistance/migrations"
+)
+
+const (
+	seedTypeProducts   = "products"
+	seedTypeCategories = "categories"
+)
+
+func seed(ctx context.Context, env environment, args []string) error {
+	flagSet := newFlagSet(env, "seed")
+	file := flagSet.String("file", "", "JSON file with an array of products or categories")
+	seedType := flagSet.String("type", seedTypeProducts, "what the file contains, products or categories")
+	mode := flagSet.String("mode", string(migrations.SeedModeInsert), "insert skips existing products, upsert updates them")
+	if err := parseFlags(flagSet, args); err != nil {
+		return err
+	}
+
+	if *file == "" {
+		return usageErrorf("--file is required")
+	}
+
+	seedMode, err := migrations.ParseSeedMode(*mode)
+	if err != nil {
+		return usageErrorf("%s", err)
+	}
+
+	if *seedType != seedTypeProducts && *seedType != seedTypeCategories {
+		return usageErrorf("invalid type %q, must be %s or %s", *seedType, seedTypeProducts, seedTypeCategories)
+	}
+
+	// categories can't be updated, existing ones are always skipped
+	if *seedType == seedTypeCategories && seedMode != migrations.SeedModeInsert {
+		return usageErrorf("categories only support %s mode", migrations.SeedModeInsert)
+	}
+
+	db, err := openMigratedDatabase(ctx, env)
+	if err != nil {
+		return err
+	}
+	defer closeDatabase(env, db)
+
+	if err := seedDiscountRules(ctx, env, db); err != nil {
+		return err
+	}
+
+	if *seedType == seedTypeCategories {
+		return migrations.InitCategories(ctx, persistance.NewCategoriesSQLiteRepository(db), *file)
+	}
+
+	return migrations.SeedProducts(ctx, persistance.NewProductsSQLiteRepository(db), *file, seedMode)
+}
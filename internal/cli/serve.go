@@ -0,0 +1,261 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"go-products.com/m/internal"
+	"go-products.com/m/internal/config"
+	"go-products.com/m/internal/product/infrastructure/handler"
+	"go-products.com/m/internal/product/infrastructure/persistance"
+	"go-products.com/m/internal/product/infrastructure/persistance/migrations"
+	"go-products.com/m/internal/shared/api"
+	"go-products.com/m/internal/shared/auth"
+	"go-products.com/m/internal/shared/database"
+	"go-products.com/m/internal/shared/health"
+	"go-products.com/m/internal/shared/metrics"
+	"go-products.com/m/internal/shared/ratelimit"
+	"go-products.com/m/internal/shared/tracing"
+)
+
+// serve runs the server until SIGINT or SIGTERM is received or something fails, everything started is stopped before
+// returning
+func serve(ctx context.Context, env environment, args []string) error {
+	if len(args) > 0 {
+		return usageErrorf("serve takes no arguments, got %s", joinArgs(args))
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	cfg, logger := env.config, env.logger
+	slog.SetDefault(logger)
+
+	tracingOptions := tracing.Options{
+		ServiceName: cfg.Tracing.ServiceName,
+		Exporter:    cfg.Tracing.Exporter,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	}
+	exporter, err := tracing.NewExporter(ctx, tracingOptions)
+	if err != nil {
+		return fmt.Errorf("configuring tracing exporter: %w", err)
+	}
+
+	tracerProvider := tracing.NewTracerProvider(exporter, tracingOptions)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cfg.HTTP.ShutdownTimeout)
+		defer cancel()
+
+		if err := tracerProvider.Shutdown(shutdownCtx); err != nil {
+			logger.Error("flushing traces", slog.Any("error", err))
+		}
+	}()
+
+	db, err := database.GenerateDatabaseConnection(database.DatabaseConnection{
+		DatabaseName:       cfg.Database.Path,
+		MaxOpenConnections: cfg.Database.MaxOpenConnections,
+	}, migrations.CreateProductsDatabase)
+	if err != nil {
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+
+	defer func() {
+		if err := db.Close(); err != nil {
+			logger.Error("closing database", slog.Any("error", err))
+		}
+	}()
+
+	database.RegisterPoolMetrics(metrics.Default, db)
+
+	authenticator, err := newAuthenticator(ctx, cfg, db)
+	if err != nil {
+		return err
+	}
+
+	categoryRepository := persistance.NewCategoriesSQLiteRepository(db)
+	productRepository := persistance.NewProductsSQLiteRepository(db)
+	discountRulesRepository := persistance.NewDiscountRulesSQLiteRepository(db)
+
+	// rules are seeded before listening so prices are never computed without them
+	if err := seedDiscountRules(ctx, env, db); err != nil {
+		return err
+	}
+
+	seed := health.NewGate()
+	readiness := health.NewChecks()
+	readiness.Add("database", health.Ping(db))
+	readiness.Add("migrations", func(ctx context.Context) error {
+		err := migrations.CheckProductsDatabase(ctx, db)
+		if errors.Is(err, migrations.ErrPendingMigrations) {
+			return health.WithReason("pending_migrations", err)
+		}
+
+		return err
+	})
+	readiness.Add("seed", seed.Check)
+
+	// workers run in the background until they finish or ctx is cancelled, they are waited for before the database
+	// is closed
+	workersCtx, cancelWorkers := context.WithCancel(ctx)
+	var workers sync.WaitGroup
+	defer workers.Wait()
+	defer cancelWorkers()
+
+	errs := make(chan error, 2)
+
+	// seeding runs while the server is already listening, readiness fails until it completes
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+
+		if cfg.Seed.Categories != "" {
+			if err := migrations.InitCategories(workersCtx, categoryRepository, cfg.Seed.Categories); err != nil {
+				errs <- fmt.Errorf("seeding categories: %w", err)
+
+				return
+			}
+		}
+
+		if cfg.Seed.Products != "" {
+			if err := migrations.InitProducts(workersCtx, productRepository, cfg.Seed.Products); err != nil {
+				errs <- fmt.Errorf("seeding products: %w", err)
+
+				return
+			}
+		}
+
+		seed.Open()
+	}()
+
+	server := &http.Server{
+		Addr: cfg.HTTP.Address,
+		Handler: internal.SetupServer(internal.ServerDependencies{
+			ProductsRepository:       productRepository,
+			CategoriesRepository:     categoryRepository,
+			PriceHistoryRepository:   persistance.NewPriceHistorySQLiteRepository(db),
+			CatalogVersionRepository: persistance.NewCatalogVersionSQLiteRepository(db),
+			DiscountRulesRepository:  discountRulesRepository,
+			CachePolicy:              api.DefaultCachePolicy,
+			ProductsCache:            persistance.CacheOptions{Size: cfg.Catalog.CacheSize, TTL: cfg.Catalog.CacheTTL, LoadTimeout: cfg.HTTP.RequestTimeout},
+			Catalog: handler.CatalogOptions{
+				DefaultLimit: cfg.Catalog.DefaultLimit,
+				Discounts:    cfg.DiscountPolicy(),
+			},
+			RequestTimeout: cfg.HTTP.RequestTimeout,
+			ErrorFormat:    cfg.HTTP.ErrorFormat,
+			CORS:           api.DefaultCORSOptions,
+			Logger:         logger,
+			Authenticator:  authenticator,
+			// only tokens carry attributes, API keys have none
+			PricingAttributes: cfg.Auth.JWT.Enabled() && len(cfg.Auth.JWT.Attributes) > 0,
+			RateLimitStore:    newRateLimitStore(cfg, db),
+			RateLimits:        newRateLimitPolicy(cfg),
+			Metrics:           metrics.Default,
+			Readiness:         readiness,
+			Seed:              seed,
+			Shutdown:          workersCtx.Done(),
+		}),
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelWarn),
+	}
+
+	go func() {
+		logger.Info("server running", slog.String("address", server.Addr))
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			errs <- fmt.Errorf("serving http: %w", err)
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		logger.Info("shutdown requested")
+	case err = <-errs:
+	}
+
+	cancelWorkers()
+	shutdown(ctx, server, cfg.HTTP.ShutdownTimeout, logger)
+	logger.Info("server stopped")
+
+	return err
+}
+
+// shutdown stops accepting connections and drains in-flight requests, connections still open after timeout are dropped
+func shutdown(ctx context.Context, server *http.Server, timeout time.Duration, logger *slog.Logger) {
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("draining requests", slog.Any("error", err))
+		_ = server.Close()
+	}
+}
+
+// newAuthenticator accepts API keys and, when a key set is configured, JWTs. The key set is loaded before serving so
+// a wrong location is reported at startup
+func newAuthenticator(ctx context.Context, cfg config.Config, db *sql.DB) (auth.Authenticator, error) {
+	apiKeys := auth.NewAPIKeyAuthenticator(auth.NewAPIKeysSQLiteRepository(db))
+	if !cfg.Auth.JWT.Enabled() {
+		return apiKeys, nil
+	}
+
+	source := auth.JWKSFromFile(cfg.Auth.JWT.JWKSFile)
+	if cfg.Auth.JWT.JWKSURL != "" {
+		source = auth.JWKSFromURL(cfg.Auth.JWT.JWKSURL, nil)
+	}
+
+	keys := auth.NewJWKS(source, auth.JWKSOptions{
+		RefreshInterval:    cfg.Auth.JWT.RefreshInterval,
+		MinRefreshInterval: auth.DefaultJWKSOptions.MinRefreshInterval,
+	})
+	if err := keys.Refresh(ctx); err != nil {
+		return nil, fmt.Errorf("loading jwks: %w", err)
+	}
+
+	roles, err := cfg.Auth.JWT.Roles.Roles()
+	if err != nil {
+		return nil, err
+	}
+
+	return auth.FirstOf(apiKeys, auth.NewJWTAuthenticator(keys, auth.JWTOptions{
+		Issuer:     cfg.Auth.JWT.Issuer,
+		Audience:   cfg.Auth.JWT.Audience,
+		RolesClaim: cfg.Auth.JWT.RolesClaim,
+		Roles:      roles,
+		Attributes: cfg.Auth.JWT.Attributes,
+		Leeway:     cfg.Auth.JWT.Leeway,
+	})), nil
+}
+
+// newRateLimitStore keeps the buckets in the database when they must survive restarts
+func newRateLimitStore(cfg config.Config, db *sql.DB) ratelimit.Store {
+	if cfg.RateLimit.Store == ratelimit.StoreSQLite {
+		return ratelimit.NewSQLiteStore(db)
+	}
+
+	return ratelimit.NewMemoryStore()
+}
+
+func newRateLimitPolicy(cfg config.Config) api.RateLimitPolicy {
+	routes := make(map[string]ratelimit.Limit, len(cfg.RateLimit.Routes))
+	for route, limit := range cfg.RateLimit.Routes {
+		routes[route] = ratelimit.Limit{Rate: limit.Rate, Burst: limit.Burst}
+	}
+
+	return api.RateLimitPolicy{
+		Default:        ratelimit.Limit{Rate: cfg.RateLimit.Rate, Burst: cfg.RateLimit.Burst},
+		Routes:         routes,
+		Authentication: ratelimit.Limit{Rate: cfg.RateLimit.Authentication.Rate, Burst: cfg.RateLimit.Authentication.Burst},
+		ClientIPHeader: cfg.RateLimit.ClientIPHeader,
+	}
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdown(t *testing.T) {
+	// startServer serves requests blocking until release is closed, started receives every request once it arrives
+	startServer := func(t *testing.T, release <-chan struct{}) (*http.Server, string, <-chan struct{}) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		started := make(chan struct{}, 1)
+		server := &http.Server{Handler: http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			started <- struct{}{}
+			<-release
+			_, _ = io.WriteString(response, "done")
+		})}
+		go func() { _ = server.Serve(listener) }()
+
+		return server, listener.Addr().String(), started
+	}
+
+	// get reports whether a request made in the background was answered
+	get := func(address string) <-chan error {
+		result := make(chan error, 1)
+		go func() {
+			response, err := http.Get("http://" + address)
+			if err != nil {
+				result <- err
+
+				return
+			}
+
+			defer response.Body.Close()
+			body, err := io.ReadAll(response.Body)
+			if err == nil && string(body) != "done" {
+				err = errors.New("unexpected body " + string(body))
+			}
+
+			result <- err
+		}()
+
+		return result
+	}
+
+	t.Run("In-flight requests complete while new connections are refused", func(t *testing.T) {
+		assertions := require.New(t)
+
+		release := make(chan struct{})
+		server, address, started := startServer(t, release)
+		inFlight := get(address)
+		<-started
+
+		stopped := make(chan struct{})
+		go func() {
+			shutdown(context.Background(), server, time.Minute, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			close(stopped)
+		}()
+
+		assertions.Eventually(func() bool {
+			connection, err := net.Dial("tcp", address)
+			if err == nil {
+				_ = connection.Close()
+			}
+
+			return err != nil
+		}, time.Second, time.Millisecond)
+
+		select {
+		case <-stopped:
+			assertions.Fail("shutdown returned before the in-flight request completed")
+		default:
+		}
+
+		close(release)
+		assertions.NoError(<-inFlight)
+		<-stopped
+	})
+
+	t.Run("Requests still running after the timeout are dropped", func(t *testing.T) {
+		assertions := require.New(t)
+
+		release := make(chan struct{})
+		defer close(release)
+		server, address, started := startServer(t, release)
+		inFlight := get(address)
+		<-started
+
+		var logs bytes.Buffer
+		start := time.Now()
+		shutdown(context.Background(), server, 50*time.Millisecond, slog.New(slog.NewTextHandler(&logs, nil)))
+
+		assertions.Less(time.Since(start), time.Second)
+		assertions.Error(<-inFlight)
+		assertions.Contains(logs.String(), `msg="draining requests"`)
+	})
+}
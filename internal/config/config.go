@@ -0,0 +1,289 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/shared/api"
+	"go-products.com/m/internal/shared/auth"
+	"go-products.com/m/internal/shared/logging"
+	"go-products.com/m/internal/shared/ratelimit"
+	"go-products.com/m/internal/shared/tracing"
+)
+
+// Config settings of the application. Values are taken, from lowest to highest precedence, from the defaults, the
+// YAML file given with --config, environment variables and flags
+type Config struct {
+	HTTP      HTTP      `yaml:"http"`
+	Database  Database  `yaml:"database"`
+	Seed      Seed      `yaml:"seed"`
+	Catalog   Catalog   `yaml:"catalog"`
+	Log       Log       `yaml:"log"`
+	Tracing   Tracing   `yaml:"tracing"`
+	Auth      Auth      `yaml:"auth"`
+	RateLimit RateLimit `yaml:"rate_limit"`
+}
+
+type HTTP struct {
+	Address           string        `yaml:"address"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+	// ShutdownTimeout how long in-flight requests are waited for once shutdown starts
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// RequestTimeout cancels the work of a request, 0 disables it
+	RequestTimeout time.Duration `yaml:"request_timeout"`
+	// ErrorFormat json or problem, clients accepting application/problem+json always get problem documents
+	ErrorFormat string `yaml:"error_format"`
+}
+
+type Database struct {
+	Path               string `yaml:"path"`
+	MaxOpenConnections int    `yaml:"max_open_connections"`
+}
+
+// Seed files loaded at startup, seeding is skipped for empty paths
+type Seed struct {
+	Categories string `yaml:"categories"`
+	Products   string `yaml:"products"`
+}
+
+type Catalog struct {
+	DefaultLimit int `yaml:"default_limit"`
+	// CacheSize maximum number of cached products queries, caching is disabled when it is 0
+	CacheSize int           `yaml:"cache_size"`
+	CacheTTL  time.Duration `yaml:"cache_ttl"`
+	Discounts Discounts     `yaml:"discounts"`
+}
+
+// Discounts rates as a fraction of the price
+type Discounts struct {
+	Categories Rates `yaml:"categories"`
+	Skus       Rates `yaml:"skus"`
+}
+
+type Log struct {
+	Format string `yaml:"format"`
+	Level  string `yaml:"level"`
+}
+
+type Tracing struct {
+	Exporter    string  `yaml:"exporter"`
+	ServiceName string  `yaml:"service_name"`
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// Auth API keys are always accepted, JWTs only when a key set is configured
+type Auth struct {
+	JWT JWT `yaml:"jwt"`
+}
+
+type JWT struct {
+	// JWKSFile and JWKSURL where the signing keys are read from, at most one of them can be set
+	JWKSFile string `yaml:"jwks_file"`
+	JWKSURL  string `yaml:"jwks_url"`
+	Issuer   string `yaml:"issuer"`
+	Audience string `yaml:"audience"`
+	// RolesClaim claim whose values are mapped to roles through Roles
+	RolesClaim string      `yaml:"roles_claim"`
+	Roles      RoleMapping `yaml:"roles"`
+	// Attributes maps claims to attributes of the caller, pricing reads the channel, segment and country attributes
+	Attributes AttributeMapping `yaml:"attributes"`
+	// RefreshInterval how often the keys are reloaded, tokens signed with unknown keys reload them sooner
+	RefreshInterval time.Duration `yaml:"refresh_interval"`
+	// Leeway tolerated clock skew with the issuer
+	Leeway time.Duration `yaml:"leeway"`
+}
+
+func (j JWT) Enabled() bool {
+	return j.JWKSFile != "" || j.JWKSURL != ""
+}
+
+// RateLimit token buckets per client and route, a zero rate or burst disables limiting
+type RateLimit struct {
+	// Store memory, or sqlite to keep the buckets across restarts
+	Store string  `yaml:"store"`
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+	// Routes limits replacing the default one, keyed by method and pattern, GET /api/v1/products
+	Routes map[string]RouteLimit `yaml:"routes"`
+	// Authentication limit per address of the requests carrying credentials, taken before they are checked
+	Authentication RouteLimit `yaml:"authentication"`
+	// ClientIPHeader header set by a trusted proxy with the client address, the connection address is used when empty
+	ClientIPHeader string `yaml:"client_ip_header"`
+}
+
+type RouteLimit struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
+
+func Default() Config {
+	discounts := domain.DefaultDiscountPolicy()
+
+	return Config{
+		HTTP: HTTP{
+			Address:           ":8080",
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       10 * time.Second,
+			WriteTimeout:      15 * time.Second,
+			IdleTimeout:       60 * time.Second,
+			ShutdownTimeout:   20 * time.Second,
+			RequestTimeout:    10 * time.Second,
+			ErrorFormat:       api.ErrorFormatJSON,
+		},
+		Database: Database{Path: "products.db"},
+		Seed: Seed{
+			Categories: "infra/migrations/categories.json",
+			Products:   "infra/migrations/data.json",
+		},
+		Catalog: Catalog{
+			DefaultLimit: 5,
+			CacheSize:    256,
+			CacheTTL:     time.Minute,
+			Discounts: Discounts{
+				Categories: discounts.Categories,
+				Skus:       discounts.Skus,
+			},
+		},
+		Log: Log{Format: logging.FormatText, Level: "info"},
+		Tracing: Tracing{
+			Exporter:    tracing.ExporterNone,
+			ServiceName: "go-products",
+			SampleRatio: 1,
+		},
+		Auth: Auth{
+			JWT: JWT{
+				RolesClaim: "roles",
+				Roles: RoleMapping{
+					string(auth.RoleViewer): string(auth.RoleViewer),
+					string(auth.RoleEditor): string(auth.RoleEditor),
+					string(auth.RoleAdmin):  string(auth.RoleAdmin),
+				},
+				Attributes: AttributeMapping{
+					domain.PricingChannel: domain.PricingChannel,
+					domain.PricingSegment: domain.PricingSegment,
+					domain.PricingCountry: domain.PricingCountry,
+				},
+				RefreshInterval: auth.DefaultJWKSOptions.RefreshInterval,
+				Leeway:          30 * time.Second,
+			},
+		},
+		RateLimit: RateLimit{
+			Store: ratelimit.StoreMemory,
+			Rate:  10,
+			Burst: 20,
+			Routes: map[string]RouteLimit{
+				"GET /api/v1/products": {Rate: 5, Burst: 10},
+			},
+			Authentication: RouteLimit{Rate: 20, Burst: 40},
+		},
+	}
+}
+
+// Validate reports every invalid setting at once
+func (c Config) Validate() error {
+	var errs []error
+	check := func(failed bool, format string, args ...any) {
+		if failed {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.HTTP.Address == "", "http.address is required")
+	check(c.HTTP.ReadHeaderTimeout <= 0, "http.read_header_timeout must be positive")
+	check(c.HTTP.ReadTimeout <= 0, "http.read_timeout must be positive")
+	check(c.HTTP.WriteTimeout <= 0, "http.write_timeout must be positive")
+	check(c.HTTP.IdleTimeout <= 0, "http.idle_timeout must be positive")
+	check(c.HTTP.ShutdownTimeout <= 0, "http.shutdown_timeout must be positive")
+	check(c.HTTP.RequestTimeout < 0, "http.request_timeout can't be negative")
+	check(c.HTTP.RequestTimeout > 0 && c.HTTP.WriteTimeout > 0 && c.HTTP.RequestTimeout >= c.HTTP.WriteTimeout,
+		"http.request_timeout must be lower than http.write_timeout so timed out requests can still be answered")
+	check(c.HTTP.ErrorFormat != api.ErrorFormatJSON && c.HTTP.ErrorFormat != api.ErrorFormatProblem,
+		"http.error_format must be %s or %s", api.ErrorFormatJSON, api.ErrorFormatProblem)
+
+	check(c.Database.Path == "", "database.path is required")
+	check(c.Database.MaxOpenConnections < 0, "database.max_open_connections can't be negative")
+
+	check(c.Catalog.DefaultLimit <= 0, "catalog.default_limit must be positive")
+	check(c.Catalog.CacheSize < 0, "catalog.cache_size can't be negative")
+	check(c.Catalog.CacheTTL < 0, "catalog.cache_ttl can't be negative")
+	if err := c.DiscountPolicy().Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("catalog.discounts: %w", err))
+	}
+
+	if _, err := logging.New(io.Discard, logging.Options{Format: c.Log.Format, Level: c.Log.Level}); err != nil {
+		errs = append(errs, fmt.Errorf("log: %w", err))
+	}
+
+	check(c.Tracing.Exporter != tracing.ExporterNone && c.Tracing.Exporter != tracing.ExporterOTLP,
+		"tracing.exporter must be %s or %s", tracing.ExporterNone, tracing.ExporterOTLP)
+	check(c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1, "tracing.sample_ratio must be between 0 and 1")
+
+	jwt := c.Auth.JWT
+	check(jwt.JWKSFile != "" && jwt.JWKSURL != "", "auth.jwt.jwks_file and auth.jwt.jwks_url can't be both set")
+	check(jwt.Enabled() && jwt.Issuer == "", "auth.jwt.issuer is required to accept JWTs")
+	check(jwt.Enabled() && jwt.Audience == "", "auth.jwt.audience is required to accept JWTs")
+	check(jwt.RefreshInterval <= 0, "auth.jwt.refresh_interval must be positive")
+	check(jwt.Leeway < 0, "auth.jwt.leeway can't be negative")
+	if _, err := jwt.Roles.Roles(); err != nil {
+		errs = append(errs, fmt.Errorf("auth.jwt.roles: %w", err))
+	}
+
+	claims := make([]string, 0, len(jwt.Attributes))
+	for claim := range jwt.Attributes {
+		claims = append(claims, claim)
+	}
+	slices.Sort(claims)
+	for _, claim := range claims {
+		check(!slices.Contains(domain.PricingFields(), jwt.Attributes[claim]), "auth.jwt.attributes: %q must be mapped to one of %s",
+			claim, strings.Join(domain.PricingFields(), ", "))
+	}
+
+	check(c.RateLimit.Store != ratelimit.StoreMemory && c.RateLimit.Store != ratelimit.StoreSQLite,
+		"rate_limit.store must be %s or %s", ratelimit.StoreMemory, ratelimit.StoreSQLite)
+	check(c.RateLimit.Rate < 0, "rate_limit.rate can't be negative")
+	check(c.RateLimit.Burst < 0, "rate_limit.burst can't be negative")
+	check(c.RateLimit.Authentication.Rate < 0, "rate_limit.authentication.rate can't be negative")
+	check(c.RateLimit.Authentication.Burst < 0, "rate_limit.authentication.burst can't be negative")
+	routes := make([]string, 0, len(c.RateLimit.Routes))
+	for route := range c.RateLimit.Routes {
+		routes = append(routes, route)
+	}
+	slices.Sort(routes)
+	for _, route := range routes {
+		method, path, found := strings.Cut(route, " ")
+		check(!found || method == "" || method != strings.ToUpper(method) || !strings.HasPrefix(path, "/"),
+			"rate_limit.routes: route %q must be written as METHOD /path", route)
+		check(c.RateLimit.Routes[route].Rate < 0, "rate_limit.routes: %s rate can't be negative", route)
+		check(c.RateLimit.Routes[route].Burst < 0, "rate_limit.routes: %s burst can't be negative", route)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c Config) DiscountPolicy() domain.DiscountPolicy {
+	return domain.DiscountPolicy{
+		Categories: c.Catalog.Discounts.Categories,
+		Skus:       c.Catalog.Discounts.Skus,
+	}
+}
+
+// Print writes the effective configuration as YAML, the output can be used as config file
+func (c Config) Print(writer io.Writer) error {
+	encoder := yaml.NewEncoder(writer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
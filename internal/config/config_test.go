@@ -0,0 +1,178 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func env(values map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := values[key]
+
+		return value, ok
+	}
+}
+
+func TestLoad(t *testing.T) {
+	assertions := require.New(t)
+
+	tests := []struct {
+		name   string
+		args   []string
+		env    map[string]string
+		assert func(config Config, flags Flags)
+	}{
+		{
+			name: "Defaults",
+			assert: func(config Config, flags Flags) {
+				assertions.Equal(Default(), config)
+				assertions.Equal(Flags{}, flags)
+			},
+		},
+		{
+			name: "File overrides defaults",
+			args: []string{"--config", "testdata/config.yaml"},
+			assert: func(config Config, flags Flags) {
+				assertions.Equal(":9090", config.HTTP.Address)
+				assertions.Equal(20*time.Second, config.HTTP.ReadTimeout)
+				assertions.Equal(15*time.Second, config.HTTP.WriteTimeout)
+				assertions.Equal("catalog.db", config.Database.Path)
+				assertions.Equal(10, config.Catalog.DefaultLimit)
+				assertions.Equal(Rates{"sandals": 0.1}, config.Catalog.Discounts.Categories)
+				assertions.Equal(Rates{"000003": 0.15}, config.Catalog.Discounts.Skus)
+				assertions.Equal("testdata/config.yaml", flags.File)
+			},
+		},
+		{
+			name: "Environment overrides file",
+			env: map[string]string{
+				"CONFIG_FILE":                  "testdata/config.yaml",
+				"HTTP_ADDRESS":                 ":7070",
+				"CATALOG_DISCOUNTS_CATEGORIES": "boots=0.2,sneakers=0.05",
+				"OTEL_SERVICE_NAME":            "catalog",
+				"AUTH_JWT_ATTRIBUTES":          "https://example.com/channel=channel,segment=segment",
+			},
+			assert: func(config Config, flags Flags) {
+				assertions.Equal(":7070", config.HTTP.Address)
+				assertions.Equal("catalog.db", config.Database.Path)
+				assertions.Equal(Rates{"boots": 0.2, "sneakers": 0.05}, config.Catalog.Discounts.Categories)
+				assertions.Equal("catalog", config.Tracing.ServiceName)
+				assertions.Equal(AttributeMapping{"https://example.com/channel": "channel", "segment": "segment"}, config.Auth.JWT.Attributes)
+			},
+		},
+		{
+			name: "Flags override environment",
+			args: []string{"--http.address=:6060", "--catalog.default-limit", "3", "--print-config"},
+			env:  map[string]string{"CONFIG_FILE": "testdata/config.yaml", "HTTP_ADDRESS": ":7070"},
+			assert: func(config Config, flags Flags) {
+				assertions.Equal(":6060", config.HTTP.Address)
+				assertions.Equal(3, config.Catalog.DefaultLimit)
+				assertions.Equal("catalog.db", config.Database.Path)
+				assertions.True(flags.PrintConfig)
+			},
+		},
+		{
+			name: "Command arguments are left after the flags",
+			args: []string{"--database.path=catalog.db", "migrate", "down", "--steps", "2"},
+			assert: func(config Config, flags Flags) {
+				assertions.Equal("catalog.db", config.Database.Path)
+				assertions.Equal([]string{"migrate", "down", "--steps", "2"}, flags.Args)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, flags, err := Load("products", tt.args, io.Discard, env(tt.env))
+			assertions.NoError(err)
+
+			tt.assert(config, flags)
+		})
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	assertions := require.New(t)
+
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		wantErr string
+	}{
+		{
+			name:    "Unknown field in file",
+			args:    []string{"--config", "testdata/unknown_field.yaml"},
+			wantErr: "field port not found",
+		},
+		{
+			name:    "Missing file",
+			args:    []string{"--config", "testdata/missing.yaml"},
+			wantErr: "opening config file",
+		},
+		{
+			name:    "Invalid environment value",
+			env:     map[string]string{"HTTP_READ_TIMEOUT": "soon"},
+			wantErr: "HTTP_READ_TIMEOUT",
+		},
+		{
+			name:    "Invalid rates",
+			args:    []string{"--catalog.discounts.skus", "000001"},
+			wantErr: `"000001" must be key=rate`,
+		},
+		{
+			name:    "JWT without issuer and audience",
+			env:     map[string]string{"AUTH_JWT_JWKS_FILE": "jwks.json", "AUTH_JWT_ROLES": "writers=owner"},
+			wantErr: "auth.jwt.issuer is required to accept JWTs\nauth.jwt.audience is required to accept JWTs\nauth.jwt.roles: \"writers\": invalid role \"owner\"",
+		},
+		{
+			name:    "JWT attribute unknown to pricing",
+			env:     map[string]string{"AUTH_JWT_ATTRIBUTES": "tier=segment,region=zone"},
+			wantErr: "auth.jwt.attributes: \"region\" must be mapped to one of channel, segment, country",
+		},
+		{
+			name:    "Invalid error format and rate limits",
+			args:    []string{"--config", "testdata/rate_limit.yaml", "--rate-limit.store=redis", "--http.error-format=xml"},
+			wantErr: "http.error_format must be json or problem\nrate_limit.store must be memory or sqlite\nrate_limit.routes: route \"/api/v1/categories\" must be written as METHOD /path\nrate_limit.routes: /api/v1/categories burst can't be negative",
+		},
+		{
+			name:    "Every invalid setting is reported",
+			args:    []string{"--catalog.default-limit=0", "--tracing.sample-ratio=2", "--catalog.discounts.categories=boots=1.5"},
+			wantErr: "catalog.default_limit must be positive\ncatalog.discounts: category discount for \"boots\" must be greater than 0 and at most 1, got 1.5\ntracing.sample_ratio must be between 0 and 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Load("products", tt.args, io.Discard, env(tt.env))
+			assertions.ErrorContains(err, tt.wantErr)
+		})
+	}
+
+	_, _, err := Load("products", []string{"--help"}, io.Discard, env(nil))
+	assertions.True(errors.Is(err, flag.ErrHelp))
+}
+
+func TestConfig_Print(t *testing.T) {
+	assertions := require.New(t)
+
+	config := Default()
+	config.Catalog.Discounts.Skus = Rates{"000001": 0.5}
+
+	var output bytes.Buffer
+	assertions.NoError(config.Print(&output))
+	assertions.Contains(output.String(), "read_timeout: 10s")
+
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	assertions.NoError(os.WriteFile(file, output.Bytes(), 0o600))
+
+	loaded, _, err := Load("products", []string{"--config", file}, io.Discard, env(nil))
+	assertions.NoError(err)
+	assertions.Equal(config, loaded)
+}
@@ -0,0 +1,333 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"go-products.com/m/internal/shared/auth"
+)
+
+// Flags command line options which are not part of the configuration
+type Flags struct {
+	// File YAML configuration file
+	File string
+	// PrintConfig prints the effective configuration instead of running
+	PrintConfig bool
+	// Args arguments left after the flags, the command to run followed by its own arguments
+	Args []string
+}
+
+// envOverrides environment variables not following the flag naming, every other flag is read from the environment
+// variable named after it, http.read-timeout from HTTP_READ_TIMEOUT
+var envOverrides = map[string]string{
+	"config":               "CONFIG_FILE",
+	"tracing.service-name": "OTEL_SERVICE_NAME",
+}
+
+// Load reads the configuration from args, without the program name, the environment and the configuration file, the
+// result is validated. Parsing stops at the first positional argument, it and everything after it is returned in
+// Flags.Args. flag.ErrHelp is returned when help is requested
+func Load(name string, args []string, output io.Writer, lookupEnv func(string) (string, bool)) (Config, Flags, error) {
+	// the first pass only finds the configuration file, flags are parsed again on top of the file and the environment
+	// so they take precedence
+	defaults := Default()
+	var flags Flags
+	if err := newFlagSet(name, output, &defaults, &flags).Parse(args); err != nil {
+		return Config{}, Flags{}, err
+	}
+
+	if flags.File == "" {
+		flags.File, _ = lookupEnv(envName("config"))
+	}
+
+	config := Default()
+	if flags.File != "" {
+		if err := readFile(flags.File, &config); err != nil {
+			return Config{}, Flags{}, err
+		}
+	}
+
+	flagSet := newFlagSet(name, output, &config, &flags)
+
+	var errs []error
+	flagSet.VisitAll(func(f *flag.Flag) {
+		value, ok := lookupEnv(envName(f.Name))
+		if !ok || f.Name == "config" {
+			return
+		}
+
+		if err := f.Value.Set(value); err != nil {
+			errs = append(errs, fmt.Errorf("invalid value %q for %s: %w", value, envName(f.Name), err))
+		}
+	})
+	if err := errors.Join(errs...); err != nil {
+		return Config{}, Flags{}, err
+	}
+
+	if err := flagSet.Parse(args); err != nil {
+		return Config{}, Flags{}, err
+	}
+
+	if len(flagSet.Args()) > 0 {
+		flags.Args = flagSet.Args()
+	}
+
+	if err := config.Validate(); err != nil {
+		return Config{}, Flags{}, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	return config, flags, nil
+}
+
+func newFlagSet(name string, output io.Writer, config *Config, flags *Flags) *flag.FlagSet {
+	flagSet := flag.NewFlagSet(name, flag.ContinueOnError)
+	flagSet.SetOutput(output)
+
+	flagSet.StringVar(&flags.File, "config", flags.File, "YAML configuration file")
+	flagSet.BoolVar(&flags.PrintConfig, "print-config", flags.PrintConfig, "print the effective configuration and exit")
+
+	flagSet.StringVar(&config.HTTP.Address, "http.address", config.HTTP.Address, "address the server listens on")
+	flagSet.DurationVar(&config.HTTP.ReadHeaderTimeout, "http.read-header-timeout", config.HTTP.ReadHeaderTimeout, "time allowed to read request headers")
+	flagSet.DurationVar(&config.HTTP.ReadTimeout, "http.read-timeout", config.HTTP.ReadTimeout, "time allowed to read a whole request")
+	flagSet.DurationVar(&config.HTTP.WriteTimeout, "http.write-timeout", config.HTTP.WriteTimeout, "time allowed to write a response")
+	flagSet.DurationVar(&config.HTTP.IdleTimeout, "http.idle-timeout", config.HTTP.IdleTimeout, "time keep-alive connections are kept idle")
+	flagSet.DurationVar(&config.HTTP.ShutdownTimeout, "http.shutdown-timeout", config.HTTP.ShutdownTimeout, "time in-flight requests are waited for on shutdown")
+	flagSet.DurationVar(&config.HTTP.RequestTimeout, "http.request-timeout", config.HTTP.RequestTimeout, "time after which the work of a request is cancelled, 0 disables it")
+	flagSet.StringVar(&config.HTTP.ErrorFormat, "http.error-format", config.HTTP.ErrorFormat, "error responses format, json or problem")
+
+	flagSet.StringVar(&config.Database.Path, "database.path", config.Database.Path, "SQLite database file")
+	flagSet.IntVar(&config.Database.MaxOpenConnections, "database.max-open-connections", config.Database.MaxOpenConnections, "maximum open database connections, 0 is unlimited")
+
+	flagSet.StringVar(&config.Seed.Categories, "seed.categories", config.Seed.Categories, "categories seeded at startup, empty skips them")
+	flagSet.StringVar(&config.Seed.Products, "seed.products", config.Seed.Products, "products seeded at startup, empty skips them")
+
+	flagSet.IntVar(&config.Catalog.DefaultLimit, "catalog.default-limit", config.Catalog.DefaultLimit, "maximum number of products listed")
+	flagSet.IntVar(&config.Catalog.CacheSize, "catalog.cache-size", config.Catalog.CacheSize, "cached products queries, 0 disables the cache")
+	flagSet.DurationVar(&config.Catalog.CacheTTL, "catalog.cache-ttl", config.Catalog.CacheTTL, "how long products queries are cached")
+	flagSet.Var(&config.Catalog.Discounts.Categories, "catalog.discounts.categories", "discount rates by category as category=rate,...")
+	flagSet.Var(&config.Catalog.Discounts.Skus, "catalog.discounts.skus", "discount rates by sku as sku=rate,...")
+
+	flagSet.StringVar(&config.Log.Format, "log.format", config.Log.Format, "log format, json or text")
+	flagSet.StringVar(&config.Log.Level, "log.level", config.Log.Level, "log level, debug, info, warn or error")
+
+	flagSet.StringVar(&config.Tracing.Exporter, "tracing.exporter", config.Tracing.Exporter, "span exporter, none or otlp")
+	flagSet.StringVar(&config.Tracing.ServiceName, "tracing.service-name", config.Tracing.ServiceName, "service name reported in spans")
+	flagSet.Float64Var(&config.Tracing.SampleRatio, "tracing.sample-ratio", config.Tracing.SampleRatio, "fraction of new traces recorded")
+
+	flagSet.StringVar(&config.Auth.JWT.JWKSFile, "auth.jwt.jwks-file", config.Auth.JWT.JWKSFile, "JWKS file verifying JWTs, JWTs are rejected when no key set is given")
+	flagSet.StringVar(&config.Auth.JWT.JWKSURL, "auth.jwt.jwks-url", config.Auth.JWT.JWKSURL, "JWKS endpoint verifying JWTs")
+	flagSet.StringVar(&config.Auth.JWT.Issuer, "auth.jwt.issuer", config.Auth.JWT.Issuer, "required iss claim")
+	flagSet.StringVar(&config.Auth.JWT.Audience, "auth.jwt.audience", config.Auth.JWT.Audience, "required aud claim")
+	flagSet.StringVar(&config.Auth.JWT.RolesClaim, "auth.jwt.roles-claim", config.Auth.JWT.RolesClaim, "claim holding the roles of the caller")
+	flagSet.Var(&config.Auth.JWT.Roles, "auth.jwt.roles", "roles granted by claim value as value=role,...")
+	flagSet.Var(&config.Auth.JWT.Attributes, "auth.jwt.attributes", "attributes of the caller by claim as claim=attribute,...")
+	flagSet.DurationVar(&config.Auth.JWT.RefreshInterval, "auth.jwt.refresh-interval", config.Auth.JWT.RefreshInterval, "how often the key set is reloaded")
+	flagSet.DurationVar(&config.Auth.JWT.Leeway, "auth.jwt.leeway", config.Auth.JWT.Leeway, "tolerated clock skew with the issuer")
+
+	flagSet.StringVar(&config.RateLimit.Store, "rate-limit.store", config.RateLimit.Store, "where rate limits are kept, memory or sqlite")
+	flagSet.Float64Var(&config.RateLimit.Rate, "rate-limit.rate", config.RateLimit.Rate, "requests per second allowed to a client on a route, 0 disables limiting")
+	flagSet.IntVar(&config.RateLimit.Burst, "rate-limit.burst", config.RateLimit.Burst, "requests a client can make at once on a route")
+	flagSet.Float64Var(&config.RateLimit.Authentication.Rate, "rate-limit.authentication.rate", config.RateLimit.Authentication.Rate, "requests with credentials per second allowed to an address, 0 disables limiting")
+	flagSet.IntVar(&config.RateLimit.Authentication.Burst, "rate-limit.authentication.burst", config.RateLimit.Authentication.Burst, "requests with credentials an address can make at once")
+	flagSet.StringVar(&config.RateLimit.ClientIPHeader, "rate-limit.client-ip-header", config.RateLimit.ClientIPHeader, "header with the client address set by a trusted proxy")
+
+	return flagSet
+}
+
+func envName(flagName string) string {
+	if name, ok := envOverrides[flagName]; ok {
+		return name
+	}
+
+	return strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(flagName))
+}
+
+func readFile(path string, config *Config) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening config file: %w", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// Rates discount rates keyed by category or sku, as a flag they are written as key=rate,key=rate
+type Rates map[string]float64
+
+func (r *Rates) String() string {
+	if r == nil {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(*r))
+	for key, rate := range *r {
+		pairs = append(pairs, key+"="+strconv.FormatFloat(rate, 'g', -1, 64))
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+// Set replaces every rate, an empty value removes them all
+func (r *Rates) Set(value string) error {
+	pairs, err := parsePairs(value, "key=rate")
+	if err != nil {
+		return err
+	}
+
+	rates := make(Rates, len(pairs))
+	for key, rate := range pairs {
+		parsedRate, err := strconv.ParseFloat(rate, 64)
+		if err != nil {
+			return fmt.Errorf("%q must be key=rate: %w", key+"="+rate, err)
+		}
+
+		rates[key] = parsedRate
+	}
+
+	*r = rates
+
+	return nil
+}
+
+// UnmarshalYAML rates in the file replace the defaults instead of being merged with them
+func (r *Rates) UnmarshalYAML(node *yaml.Node) error {
+	rates := make(map[string]float64)
+	if err := node.Decode(&rates); err != nil {
+		return err
+	}
+
+	*r = rates
+
+	return nil
+}
+
+// RoleMapping roles granted by claim value, as a flag it is written as value=role,value=role
+type RoleMapping map[string]string
+
+func (m *RoleMapping) String() string {
+	if m == nil {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(*m))
+	for value, role := range *m {
+		pairs = append(pairs, value+"="+role)
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+// Set replaces the whole mapping, an empty value removes it
+func (m *RoleMapping) Set(value string) error {
+	pairs, err := parsePairs(value, "value=role")
+	if err != nil {
+		return err
+	}
+
+	*m = pairs
+
+	return nil
+}
+
+// UnmarshalYAML the mapping in the file replaces the default instead of being merged with it
+func (m *RoleMapping) UnmarshalYAML(node *yaml.Node) error {
+	mapping := make(map[string]string)
+	if err := node.Decode(&mapping); err != nil {
+		return err
+	}
+
+	*m = mapping
+
+	return nil
+}
+
+// Roles the mapping with parsed roles, failing on unknown roles
+func (m RoleMapping) Roles() (map[string]auth.Role, error) {
+	roles := make(map[string]auth.Role, len(m))
+	for value, role := range m {
+		parsedRole, err := auth.ParseRole(role)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", value, err)
+		}
+
+		roles[value] = parsedRole
+	}
+
+	return roles, nil
+}
+
+// AttributeMapping attributes of the caller by claim name, as a flag it is written as claim=attribute,claim=attribute
+type AttributeMapping map[string]string
+
+func (m *AttributeMapping) String() string {
+	if m == nil {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(*m))
+	for claim, attribute := range *m {
+		pairs = append(pairs, claim+"="+attribute)
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+// Set replaces the whole mapping, an empty value removes it
+func (m *AttributeMapping) Set(value string) error {
+	pairs, err := parsePairs(value, "claim=attribute")
+	if err != nil {
+		return err
+	}
+
+	*m = pairs
+
+	return nil
+}
+
+// UnmarshalYAML the mapping in the file replaces the default instead of being merged with it
+func (m *AttributeMapping) UnmarshalYAML(node *yaml.Node) error {
+	mapping := make(map[string]string)
+	if err := node.Decode(&mapping); err != nil {
+		return err
+	}
+
+	*m = mapping
+
+	return nil
+}
+
+func parsePairs(value, format string) (map[string]string, error) {
+	pairs := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		key, pairValue, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("%q must be %s", pair, format)
+		}
+
+		pairs[strings.TrimSpace(key)] = strings.TrimSpace(pairValue)
+	}
+
+	return pairs, nil
+}
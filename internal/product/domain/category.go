@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"go-products.com/m/internal/product/domain/errors"
+)
+
+// Category groups products in a tree, a category without parent is a root of the catalog (e.g. footwear > boots)
+type Category struct {
+	Slug       string
+	Name       string
+	ParentSlug *string
+}
+
+func NewCategory(slug, name string, parentSlug *string) (*Category, error) {
+	category := &Category{
+		Slug:       slug,
+		Name:       name,
+		ParentSlug: parentSlug,
+	}
+
+	if err := category.validate(); err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (c *Category) IsRoot() bool {
+	return c.ParentSlug == nil
+}
+
+// validate reports every invalid field at once, the slug format is only checked when there is one
+func (c *Category) validate() error {
+	var validation errors.Validation
+
+	slugErr := errors.NewNonEmptyString("slug", c.Slug)
+	if slugErr == nil {
+		slugErr = errors.ValidateSlug(c.Slug)
+	}
+	validation.Check("slug", slugErr)
+	validation.Check("name", errors.NewNonEmptyString("name", c.Name))
+
+	if c.ParentSlug != nil && *c.ParentSlug == c.Slug {
+		validation.Check("parent", errors.CategoryOwnParent)
+	}
+
+	return validation.Err()
+}
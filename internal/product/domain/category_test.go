@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCategory(t *testing.T) {
+	assertions := require.New(t)
+
+	type args struct {
+		slug       string
+		name       string
+		parentSlug *string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *Category
+		wantErr bool
+	}{
+		{
+			name: "Create root category successfully",
+			args: args{
+				slug: "footwear",
+				name: "Footwear",
+			},
+			want: &Category{
+				Slug: "footwear",
+				Name: "Footwear",
+			},
+		},
+		{
+			name: "Create child category successfully",
+			args: args{
+				slug:       "ankle-boots",
+				name:       "Ankle boots",
+				parentSlug: ptr("boots"),
+			},
+			want: &Category{
+				Slug:       "ankle-boots",
+				Name:       "Ankle boots",
+				ParentSlug: ptr("boots"),
+			},
+		},
+		{
+			name: "Create category with invalid slug returns error",
+			args: args{
+				slug: "Ankle Boots",
+				name: "Ankle boots",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Create category with empty name returns error",
+			args: args{
+				slug: "boots",
+				name: "",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Create category being its own parent returns error",
+			args: args{
+				slug:       "boots",
+				name:       "Boots",
+				parentSlug: ptr("boots"),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewCategory(tt.args.slug, tt.args.name, tt.args.parentSlug)
+			assertions.Equal(err != nil, tt.wantErr)
+			assertions.Equal(tt.want, got)
+		})
+	}
+}
@@ -1,6 +1,111 @@
 package domain
 
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+const (
+	DiscountRuleCategory = "category"
+	DiscountRuleSku      = "sku"
+)
+
 type Discount struct {
 	FinalPrice int
 	Percentage *float64
+	// Rule name of the rule whose discount was applied, empty when there is no discount
+	Rule string
+	// Breakdown every discount matching the product in the order of the policy rules, only the applied one lowers the
+	// price
+	Breakdown []DiscountOutcome
+}
+
+// FormattedPercentage the discount as a whole percentage such as 30%, false when there is no discount
+func (d Discount) FormattedPercentage() (string, bool) {
+	if d.Percentage == nil {
+		return "", false
+	}
+
+	return formatPercentage(*d.Percentage), true
+}
+
+// DiscountOutcome a discount matching a product and whether it was applied or dropped because a bigger one won
+type DiscountOutcome struct {
+	DiscountCandidate
+	// Amount taken from the price when this discount is applied
+	Amount  int
+	Applied bool
+}
+
+// DiscountCandidate a discount of the policy matching a product through Key, the sku or category depending on Rule.
+// Target is set when the discount is only granted in some pricing contexts
+type DiscountCandidate struct {
+	Rule       string
+	Key        string
+	Percentage float64
+	Target     PricingContext
+}
+
+// FormattedPercentage the rate of the discount as a whole percentage such as 30%
+func (c DiscountCandidate) FormattedPercentage() string {
+	return formatPercentage(c.Percentage)
+}
+
+func formatPercentage(percentage float64) string {
+	return strconv.Itoa(int(math.Round(percentage*100))) + "%"
+}
+
+// DiscountPolicy discount rates, as a fraction of the price, granted by category and by sku
+type DiscountPolicy struct {
+	Categories map[string]float64
+	Skus       map[string]float64
+	// Targeted rules only granted in the pricing contexts they target
+	Targeted []DiscountRule
+}
+
+// DiscountPolicySource gives the policy prices are computed with, it may change between calls when rules are stored
+type DiscountPolicySource interface {
+	GetDiscountPolicy(ctx context.Context) (DiscountPolicy, error)
+}
+
+// DiscountPolicySourceFunc allows using ordinary functions as DiscountPolicySource
+type DiscountPolicySourceFunc func(ctx context.Context) (DiscountPolicy, error)
+
+func (f DiscountPolicySourceFunc) GetDiscountPolicy(ctx context.Context) (DiscountPolicy, error) {
+	return f(ctx)
+}
+
+// DefaultDiscountPolicy a new policy on every call, callers may change it freely
+func DefaultDiscountPolicy() DiscountPolicy {
+	return DiscountPolicy{
+		Categories: map[string]float64{"boots": 0.3},
+		Skus:       map[string]float64{"000003": 0.15},
+	}
+}
+
+// GetDiscountPolicy a fixed policy is its own source
+func (p DiscountPolicy) GetDiscountPolicy(context.Context) (DiscountPolicy, error) {
+	return p, nil
+}
+
+// Validate rates must be greater than 0 and at most 1
+func (p DiscountPolicy) Validate() error {
+	for kind, rates := range map[string]map[string]float64{DiscountRuleCategory: p.Categories, DiscountRuleSku: p.Skus} {
+		for key, rate := range rates {
+			if rate <= 0 || rate > 1 {
+				return fmt.Errorf("%s discount for %q must be greater than 0 and at most 1, got %v", kind, key, rate)
+			}
+		}
+	}
+
+	for _, rule := range p.Targeted {
+		if rule.Percentage <= 0 || rule.Percentage > 1 {
+			return fmt.Errorf("%s discount for %q targeting %s must be greater than 0 and at most 1, got %v", rule.Kind,
+				rule.Key, rule.Target, rule.Percentage)
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"go-products.com/m/internal/product/domain/errors"
+)
+
+// DiscountRule a discount stored as data, it takes Percentage off the price of the products whose category or sku,
+// depending on Kind, is Key. Rules with a Target are only granted in the pricing contexts it matches
+type DiscountRule struct {
+	ID         int
+	Kind       string
+	Key        string
+	Percentage float64
+	Target     PricingContext
+}
+
+func NewDiscountRule(kind, key string, percentage float64, target PricingContext) (*DiscountRule, error) {
+	rule := &DiscountRule{Kind: kind, Key: key, Percentage: percentage, Target: target}
+	if err := rule.validate(); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// Change replaces the discount granted by the rule, it is left untouched when the new one is invalid
+func (r *DiscountRule) Change(kind, key string, percentage float64, target PricingContext) error {
+	changed := DiscountRule{ID: r.ID, Kind: kind, Key: key, Percentage: percentage, Target: target}
+	if err := changed.validate(); err != nil {
+		return err
+	}
+
+	*r = changed
+
+	return nil
+}
+
+// validate reports every invalid field at once
+func (r *DiscountRule) validate() error {
+	var validation errors.Validation
+	if r.Kind != DiscountRuleCategory && r.Kind != DiscountRuleSku {
+		validation.Check("kind", errors.InvalidDiscountKind)
+	}
+
+	validation.Check("key", errors.NewNonEmptyString("key", r.Key))
+	if r.Percentage <= 0 || r.Percentage > 1 {
+		validation.Check("percentage", errors.InvalidDiscountPercentage)
+	}
+
+	r.Target.check(&validation)
+
+	return validation.Err()
+}
+
+// ValidateDiscountRules checks a whole rule set, a policy grants a single discount per kind, key and target so rules
+// sharing them are rejected. The fields of the rule at index i are reported as rules[i].field
+func ValidateDiscountRules(rules []DiscountRule) error {
+	var validation errors.Validation
+	seen := make(map[string]bool, len(rules))
+	for i, rule := range rules {
+		prefix := fmt.Sprintf("rules[%d]", i)
+		validation.CheckNested(prefix, rule.validate())
+
+		if seen[rule.identity()] {
+			validation.Check(prefix, errors.DuplicatedDiscountRule)
+		}
+
+		seen[rule.identity()] = true
+	}
+
+	return validation.Err()
+}
+
+// identity what a rule grants a discount to, a policy holds a single rule for each one
+func (r DiscountRule) identity() string {
+	return r.Kind + "/" + r.Key + "/" + r.Target.String()
+}
+
+// NewDiscountPolicy the policy granting the discounts of rules
+func NewDiscountPolicy(rules []DiscountRule) DiscountPolicy {
+	policy := DiscountPolicy{Categories: make(map[string]float64), Skus: make(map[string]float64)}
+	for _, rule := range rules {
+		if !rule.Target.IsZero() {
+			policy.Targeted = append(policy.Targeted, rule)
+
+			continue
+		}
+
+		switch rule.Kind {
+		case DiscountRuleCategory:
+			policy.Categories[rule.Key] = rule.Percentage
+		case DiscountRuleSku:
+			policy.Skus[rule.Key] = rule.Percentage
+		}
+	}
+
+	return policy
+}
+
+// Rules the rules granting the discounts of the policy, sorted by kind, key and target
+func (p DiscountPolicy) Rules() []DiscountRule {
+	rules := make([]DiscountRule, 0, len(p.Categories)+len(p.Skus)+len(p.Targeted))
+	for key, percentage := range p.Categories {
+		rules = append(rules, DiscountRule{Kind: DiscountRuleCategory, Key: key, Percentage: percentage})
+	}
+
+	for key, percentage := range p.Skus {
+		rules = append(rules, DiscountRule{Kind: DiscountRuleSku, Key: key, Percentage: percentage})
+	}
+
+	for _, rule := range p.Targeted {
+		rules = append(rules, DiscountRule{Kind: rule.Kind, Key: rule.Key, Percentage: rule.Percentage, Target: rule.Target})
+	}
+
+	slices.SortFunc(rules, func(a, b DiscountRule) int {
+		return strings.Compare(a.identity(), b.identity())
+	})
+
+	return rules
+}
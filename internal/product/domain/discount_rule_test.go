@@ -0,0 +1,189 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go-products.com/m/internal/product/domain/errors"
+)
+
+func TestNewDiscountRule(t *testing.T) {
+	assertions := require.New(t)
+
+	tests := []struct {
+		name          string
+		kind          string
+		key           string
+		percentage    float64
+		target        PricingContext
+		want          *DiscountRule
+		expectedCodes map[string]string
+	}{
+		{
+			name:       "Create category rule successfully",
+			kind:       DiscountRuleCategory,
+			key:        "boots",
+			percentage: 0.3,
+			want:       &DiscountRule{Kind: DiscountRuleCategory, Key: "boots", Percentage: 0.3},
+		},
+		{
+			name:       "Create rule taking the whole price successfully",
+			kind:       DiscountRuleSku,
+			key:        "000003",
+			percentage: 1,
+			want:       &DiscountRule{Kind: DiscountRuleSku, Key: "000003", Percentage: 1},
+		},
+		{
+			name:       "Create rule targeting a pricing context successfully",
+			kind:       DiscountRuleCategory,
+			key:        "boots",
+			percentage: 0.4,
+			target:     PricingContext{Channel: ChannelWholesale, Country: "ES"},
+			want:       &DiscountRule{Kind: DiscountRuleCategory, Key: "boots", Percentage: 0.4, Target: PricingContext{Channel: ChannelWholesale, Country: "ES"}},
+		},
+		{
+			name:       "Create rule with every field invalid reports all of them",
+			kind:       "brand",
+			percentage: 0,
+			target:     PricingContext{Channel: "store", Segment: "VIP", Country: "spain"},
+			expectedCodes: map[string]string{
+				"kind":       errors.CodeInvalidKind,
+				"key":        errors.CodeRequired,
+				"percentage": errors.CodeInvalidPercentage,
+				"channel":    errors.CodeInvalidChannel,
+				"segment":    errors.CodeInvalidSegment,
+				"country":    errors.CodeInvalidCountry,
+			},
+		},
+		{
+			name:          "Create rule taking more than the price returns error",
+			kind:          DiscountRuleCategory,
+			key:           "boots",
+			percentage:    1.5,
+			expectedCodes: map[string]string{"percentage": errors.CodeInvalidPercentage},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := NewDiscountRule(tt.kind, tt.key, tt.percentage, tt.target)
+			if tt.expectedCodes != nil {
+				assertions.Equal(tt.expectedCodes, validationCodes(t, err))
+				assertions.Nil(rule)
+
+				return
+			}
+
+			assertions.NoError(err)
+			assertions.Equal(tt.want, rule)
+		})
+	}
+}
+
+func TestDiscountRule_Change(t *testing.T) {
+	assertions := require.New(t)
+
+	rule := &DiscountRule{ID: 3, Kind: DiscountRuleCategory, Key: "boots", Percentage: 0.3}
+
+	assertions.Error(rule.Change(DiscountRuleSku, "", 0.2, PricingContext{}))
+	assertions.Equal(&DiscountRule{ID: 3, Kind: DiscountRuleCategory, Key: "boots", Percentage: 0.3}, rule)
+
+	assertions.NoError(rule.Change(DiscountRuleSku, "000003", 0.2, PricingContext{Channel: ChannelApp}))
+	assertions.Equal(&DiscountRule{ID: 3, Kind: DiscountRuleSku, Key: "000003", Percentage: 0.2, Target: PricingContext{Channel: ChannelApp}}, rule)
+}
+
+func TestValidateDiscountRules(t *testing.T) {
+	assertions := require.New(t)
+
+	assertions.NoError(ValidateDiscountRules(nil))
+	assertions.NoError(ValidateDiscountRules([]DiscountRule{
+		{Kind: DiscountRuleCategory, Key: "000003", Percentage: 0.3},
+		{Kind: DiscountRuleSku, Key: "000003", Percentage: 0.15},
+		{Kind: DiscountRuleSku, Key: "000003", Percentage: 0.2, Target: PricingContext{Channel: ChannelApp}},
+	}))
+
+	err := ValidateDiscountRules([]DiscountRule{
+		{Kind: DiscountRuleCategory, Key: "boots", Percentage: 0.3},
+		{Kind: DiscountRuleSku, Key: "000003", Percentage: 2},
+		{Kind: DiscountRuleCategory, Key: "boots", Percentage: 0.1},
+	})
+	assertions.Equal(map[string]string{"rules[1].percentage": errors.CodeInvalidPercentage, "rules[2]": errors.CodeDuplicated}, validationCodes(t, err))
+}
+
+func TestNewDiscountPolicy(t *testing.T) {
+	assertions := require.New(t)
+
+	rules := []DiscountRule{
+		{Kind: DiscountRuleSku, Key: "000003", Percentage: 0.15},
+		{Kind: DiscountRuleCategory, Key: "sandals", Percentage: 0.1},
+		{Kind: DiscountRuleCategory, Key: "boots", Percentage: 0.4, Target: PricingContext{Channel: ChannelWholesale}},
+		{Kind: DiscountRuleCategory, Key: "boots", Percentage: 0.3},
+	}
+
+	policy := NewDiscountPolicy(rules)
+	assertions.Equal(DiscountPolicy{
+		Categories: map[string]float64{"boots": 0.3, "sandals": 0.1},
+		Skus:       map[string]float64{"000003": 0.15},
+		Targeted:   []DiscountRule{rules[2]},
+	}, policy)
+
+	assertions.Equal([]DiscountRule{rules[3], rules[2], rules[1], rules[0]}, policy.Rules())
+	assertions.Empty(NewDiscountPolicy(nil).Rules())
+}
+
+func TestSimulateDiscounts(t *testing.T) {
+	assertions := require.New(t)
+
+	products := []Product{
+		{Sku: "000001", Category: "boots", Price: 89000, Currency: EUR},
+		{Sku: "000003", Category: "boots", Price: 71000, Currency: EUR},
+		{Sku: "000004", Category: "sandals", Price: 79500, Currency: EUR},
+		{Sku: "000005", Category: "sneakers", Price: 59000, Currency: EUR},
+	}
+	current := DiscountPolicy{Categories: map[string]float64{"boots": 0.3}, Skus: map[string]float64{"000003": 0.15}}
+	proposed := DiscountPolicy{Categories: map[string]float64{"boots": 0.2, "sneakers": 0.5}}
+
+	simulation, err := SimulateDiscounts(products, current, proposed, PricingContext{})
+	assertions.NoError(err)
+
+	assertions.Equal(4, simulation.Products)
+	assertions.Equal(2, simulation.Increased)
+	assertions.Equal(1, simulation.Decreased)
+	assertions.Equal(1, simulation.Unchanged)
+	assertions.Equal(62299+49700+79500+59000, simulation.CurrentRevenue)
+	assertions.Equal(71200+56800+79500+29500, simulation.ProposedRevenue)
+	assertions.Equal(EUR, simulation.Currency)
+
+	differences := make(map[string]int)
+	skus := make([]string, 0, len(simulation.Changes))
+	for _, change := range simulation.Changes {
+		skus = append(skus, change.Product.Sku)
+		differences[change.Product.Sku] = change.Difference()
+	}
+
+	assertions.Equal([]string{"000005", "000001", "000003"}, skus)
+	assertions.Equal(map[string]int{"000005": -29500, "000001": 8901, "000003": 7100}, differences)
+
+	empty, err := SimulateDiscounts(nil, current, proposed, PricingContext{})
+	assertions.NoError(err)
+	assertions.Empty(empty.Currency)
+
+	mixed := append(products, Product{Sku: "000007", Category: "boots", Price: 99000, Currency: "USD"})
+	_, err = SimulateDiscounts(mixed, current, proposed, PricingContext{})
+	assertions.ErrorIs(err, errors.MixedCurrencies)
+}
+
+// validationCodes the code of every invalid field of err, which must be an errors.ErrValidation
+func validationCodes(t *testing.T, err error) map[string]string {
+	t.Helper()
+
+	var validationErr errors.ErrValidation
+	require.ErrorAs(t, err, &validationErr)
+
+	codes := make(map[string]string)
+	for _, field := range validationErr.Fields() {
+		codes[field.Field()] = field.Code()
+	}
+
+	return codes
+}
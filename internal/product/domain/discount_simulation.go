@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"cmp"
+	"slices"
+
+	"go-products.com/m/internal/product/domain/errors"
+)
+
+// DiscountSimulation how the final prices of products change when a proposed policy replaces the current one.
+// Revenues add up the final price of one unit of every product, there is no cost data so the revenue difference is
+// the impact on margin
+type DiscountSimulation struct {
+	Products        int
+	Increased       int
+	Decreased       int
+	Unchanged       int
+	CurrentRevenue  int
+	ProposedRevenue int
+	// Currency of the revenues, empty when there are no products
+	Currency string
+	// Changes products whose final price changes, biggest absolute differences first
+	Changes []SimulatedPriceChange
+}
+
+// SimulatedPriceChange the discount of a product under the current and the proposed policy
+type SimulatedPriceChange struct {
+	Product  Product
+	Current  Discount
+	Proposed Discount
+}
+
+func (c SimulatedPriceChange) Difference() int {
+	return c.Proposed.FinalPrice - c.Current.FinalPrice
+}
+
+// SimulateDiscounts applies both policies to every product in the pricing context, on equal differences products keep
+// their order. Revenues can only be added up when every product is priced in the same currency
+func SimulateDiscounts(products []Product, current, proposed DiscountPolicy, pricing PricingContext) (DiscountSimulation, error) {
+	simulation := DiscountSimulation{Products: len(products), Changes: make([]SimulatedPriceChange, 0)}
+	for _, product := range products {
+		if simulation.Currency != "" && product.Currency != simulation.Currency {
+			return DiscountSimulation{}, errors.MixedCurrencies
+		}
+
+		simulation.Currency = product.Currency
+		change := SimulatedPriceChange{Product: product, Current: product.GetDiscount(current, pricing), Proposed: product.GetDiscount(proposed, pricing)}
+		simulation.CurrentRevenue += change.Current.FinalPrice
+		simulation.ProposedRevenue += change.Proposed.FinalPrice
+
+		switch {
+		case change.Difference() > 0:
+			simulation.Increased++
+		case change.Difference() < 0:
+			simulation.Decreased++
+		default:
+			simulation.Unchanged++
+
+			continue
+		}
+
+		simulation.Changes = append(simulation.Changes, change)
+	}
+
+	slices.SortStableFunc(simulation.Changes, func(a, b SimulatedPriceChange) int {
+		return cmp.Compare(abs(b.Difference()), abs(a.Difference()))
+	})
+
+	return simulation, nil
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+
+	return value
+}
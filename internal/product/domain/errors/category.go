@@ -0,0 +1,24 @@
+package errors
+
+import "fmt"
+
+var (
+	CategoryAlreadyExists = newExpected("category already exists")
+	CategoryOwnParent     = newExpected("category cannot be its own parent")
+)
+
+type ErrUnknownCategory struct {
+	slug string
+}
+
+func (e ErrUnknownCategory) Error() string {
+	return fmt.Sprintf("category %s does not exist", e.slug)
+}
+
+func (e ErrUnknownCategory) Expected() bool {
+	return true
+}
+
+func NewUnknownCategory(slug string) error {
+	return ErrUnknownCategory{slug: slug}
+}
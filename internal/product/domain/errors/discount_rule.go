@@ -0,0 +1,10 @@
+package errors
+
+var (
+	DiscountRuleNotFound      = newExpected("discount rule not found")
+	DiscountRuleAlreadyExists = newExpected("a discount rule for this kind, key and target already exists")
+	DuplicatedDiscountRule    = newExpected("there is another rule for this kind, key and target")
+	InvalidDiscountKind       = newExpected("kind must be one of category or sku")
+	InvalidDiscountPercentage = newExpected("percentage must be greater than 0 and at most 1")
+	MixedCurrencies           = newExpected("products are priced in more than one currency, their prices can't be added up")
+)
@@ -10,6 +10,10 @@ func (e ErrEmptyString) Error() string {
 	return fmt.Sprintf("%s cannot be empty", e.label)
 }
 
+func (e ErrEmptyString) Expected() bool {
+	return true
+}
+
 func NewNonEmptyString(label, value string) error {
 	if value == "" {
 		return ErrEmptyString{label: label}
@@ -0,0 +1,20 @@
+package errors
+
+// expected an outcome the domain reports to the caller, such as a missing product or an invalid field, rather than a
+// failure of the operation. Tracing leaves these out of span errors
+type expected struct {
+	message string
+}
+
+// newExpected sentinels are compared by identity, two of them with the same message are still different errors
+func newExpected(message string) error {
+	return &expected{message: message}
+}
+
+func (e *expected) Error() string {
+	return e.message
+}
+
+func (e *expected) Expected() bool {
+	return true
+}
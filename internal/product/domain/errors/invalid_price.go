@@ -1,8 +1,6 @@
 package errors
 
-import "errors"
-
-var InvalidPrice = errors.New("price must be greater than 0")
+var InvalidPrice = newExpected("price must be greater than 0")
 
 func ValidatePrice(price int) error {
 	if price <= 0 {
@@ -0,0 +1,15 @@
+package errors
+
+import "regexp"
+
+var InvalidSlug = newExpected("slug must contain only lowercase letters, numbers and dashes")
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+func ValidateSlug(slug string) error {
+	if !slugPattern.MatchString(slug) {
+		return InvalidSlug
+	}
+
+	return nil
+}
@@ -0,0 +1,22 @@
+package errors
+
+import "fmt"
+
+var InvalidStatus = newExpected("status must be one of draft, active, discontinued or archived")
+
+type ErrInvalidStatusTransition struct {
+	from string
+	to   string
+}
+
+func (e ErrInvalidStatusTransition) Error() string {
+	return fmt.Sprintf("product cannot transition from %s to %s", e.from, e.to)
+}
+
+func (e ErrInvalidStatusTransition) Expected() bool {
+	return true
+}
+
+func NewInvalidStatusTransition(from, to string) error {
+	return ErrInvalidStatusTransition{from: from, to: to}
+}
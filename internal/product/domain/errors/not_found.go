@@ -0,0 +1,3 @@
+package errors
+
+var ProductNotFound = newExpected("product not found")
@@ -0,0 +1,7 @@
+package errors
+
+var (
+	InvalidChannel = newExpected("channel must be one of web, app or wholesale")
+	InvalidSegment = newExpected("segment must contain only lowercase letters, numbers and dashes")
+	InvalidCountry = newExpected("country must be a two letter ISO 3166-1 code")
+)
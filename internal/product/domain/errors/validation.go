@@ -0,0 +1,156 @@
+package errors
+
+import (
+	"errors"
+	"strings"
+)
+
+// Codes of invalid fields, clients can rely on them while messages are meant for humans and may change
+const (
+	CodeRequired          = "REQUIRED"
+	CodeInvalidPrice      = "INVALID_PRICE"
+	CodeInvalidSlug       = "INVALID_SLUG"
+	CodeInvalidStatus     = "INVALID_STATUS"
+	CodeUnknownCategory   = "UNKNOWN_CATEGORY"
+	CodeOwnParent         = "OWN_PARENT"
+	CodeInvalidKind       = "INVALID_KIND"
+	CodeInvalidPercentage = "INVALID_PERCENTAGE"
+	CodeDuplicated        = "DUPLICATED"
+	CodeInvalidChannel    = "INVALID_CHANNEL"
+	CodeInvalidSegment    = "INVALID_SEGMENT"
+	CodeInvalidCountry    = "INVALID_COUNTRY"
+	CodeInvalid           = "INVALID"
+)
+
+// ErrInvalidField a field of a request or entity rejected by validation, it wraps the error describing why
+type ErrInvalidField struct {
+	field string
+	err   error
+}
+
+func (e ErrInvalidField) Error() string {
+	return e.err.Error()
+}
+
+func (e ErrInvalidField) Expected() bool {
+	return true
+}
+
+func (e ErrInvalidField) Unwrap() error {
+	return e.err
+}
+
+func (e ErrInvalidField) Field() string {
+	return e.field
+}
+
+// Code identifies the error wrapped, CodeInvalid when it is not a known validation error
+func (e ErrInvalidField) Code() string {
+	var emptyStringErr ErrEmptyString
+	var unknownCategoryErr ErrUnknownCategory
+
+	switch {
+	case errors.As(e.err, &emptyStringErr):
+		return CodeRequired
+	case errors.As(e.err, &unknownCategoryErr):
+		return CodeUnknownCategory
+	case errors.Is(e.err, InvalidPrice):
+		return CodeInvalidPrice
+	case errors.Is(e.err, InvalidSlug):
+		return CodeInvalidSlug
+	case errors.Is(e.err, InvalidStatus):
+		return CodeInvalidStatus
+	case errors.Is(e.err, CategoryOwnParent):
+		return CodeOwnParent
+	case errors.Is(e.err, InvalidDiscountKind):
+		return CodeInvalidKind
+	case errors.Is(e.err, InvalidDiscountPercentage):
+		return CodeInvalidPercentage
+	case errors.Is(e.err, DuplicatedDiscountRule):
+		return CodeDuplicated
+	case errors.Is(e.err, InvalidChannel):
+		return CodeInvalidChannel
+	case errors.Is(e.err, InvalidSegment):
+		return CodeInvalidSegment
+	case errors.Is(e.err, InvalidCountry):
+		return CodeInvalidCountry
+	default:
+		return CodeInvalid
+	}
+}
+
+// ErrValidation every invalid field found, so clients can fix them all at once. errors.Is and errors.As see the
+// errors of each field
+type ErrValidation struct {
+	fields []ErrInvalidField
+}
+
+func (e ErrValidation) Error() string {
+	messages := make([]string, 0, len(e.fields))
+	for _, field := range e.fields {
+		messages = append(messages, field.Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+func (e ErrValidation) Expected() bool {
+	return true
+}
+
+func (e ErrValidation) Unwrap() []error {
+	errs := make([]error, 0, len(e.fields))
+	for _, field := range e.fields {
+		errs = append(errs, field)
+	}
+
+	return errs
+}
+
+func (e ErrValidation) Fields() []ErrInvalidField {
+	return e.fields
+}
+
+// NewInvalidField a validation error for a single field, nil when err is nil
+func NewInvalidField(field string, err error) error {
+	var validation Validation
+	validation.Check(field, err)
+
+	return validation.Err()
+}
+
+// Validation collects the invalid fields of an entity, the zero value is ready to use
+type Validation struct {
+	fields []ErrInvalidField
+}
+
+// Check records err as the reason field is invalid, nil errors are ignored
+func (v *Validation) Check(field string, err error) {
+	if err != nil {
+		v.fields = append(v.fields, ErrInvalidField{field: field, err: err})
+	}
+}
+
+// CheckNested records the invalid fields of err, an ErrValidation, under prefix (rules[0].percentage), any other
+// error is recorded as prefix itself
+func (v *Validation) CheckNested(prefix string, err error) {
+	var validationErr ErrValidation
+	if !errors.As(err, &validationErr) {
+		v.Check(prefix, err)
+
+		return
+	}
+
+	for _, field := range validationErr.fields {
+		v.fields = append(v.fields, ErrInvalidField{field: prefix + "." + field.field, err: field.err})
+	}
+}
+
+// Err an ErrValidation with every invalid field, nil when all of them are valid
+func (v *Validation) Err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+
+	return ErrValidation{fields: v.fields}
+}
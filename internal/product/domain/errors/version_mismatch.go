@@ -0,0 +1,3 @@
+package errors
+
+var VersionMismatch = newExpected("product was modified by another request, fetch it again before updating")
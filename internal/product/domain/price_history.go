@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// LowestPriceWindow is the lookback used to announce the lowest price next to a discount (EU Omnibus directive)
+const LowestPriceWindow = 30 * 24 * time.Hour
+
+type PriceChange struct {
+	Sku       string
+	Price     int
+	ChangedAt time.Time
+}
+
+// LowestPriceSince returns the lowest price in effect from since onward, the last change before since is still in effect at
+// that moment so it is taken into account as well. History must be ordered from oldest to newest
+func LowestPriceSince(history []PriceChange, since time.Time) *int {
+	var lowest *int
+
+	for i, change := range history {
+		supersededBeforeSince := i < len(history)-1 && !history[i+1].ChangedAt.After(since)
+		if supersededBeforeSince {
+			continue
+		}
+
+		if lowest == nil || change.Price < *lowest {
+			price := change.Price
+			lowest = &price
+		}
+	}
+
+	return lowest
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLowestPriceSince(t *testing.T) {
+	assertions := require.New(t)
+
+	since := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		history []PriceChange
+		want    *int
+	}{
+		{
+			name:    "No history returns nil",
+			history: []PriceChange{},
+			want:    nil,
+		},
+		{
+			name: "Price set before since is still in effect",
+			history: []PriceChange{
+				{Sku: "0001", Price: 100, ChangedAt: since.AddDate(0, -2, 0)},
+			},
+			want: ptr(100),
+		},
+		{
+			name: "Price superseded before since is ignored",
+			history: []PriceChange{
+				{Sku: "0001", Price: 50, ChangedAt: since.AddDate(0, -2, 0)},
+				{Sku: "0001", Price: 100, ChangedAt: since.AddDate(0, -1, 0)},
+				{Sku: "0001", Price: 120, ChangedAt: since.AddDate(0, 0, 10)},
+			},
+			want: ptr(100),
+		},
+		{
+			name: "Lowest price within the window wins",
+			history: []PriceChange{
+				{Sku: "0001", Price: 100, ChangedAt: since.AddDate(0, -1, 0)},
+				{Sku: "0001", Price: 80, ChangedAt: since.AddDate(0, 0, 5)},
+				{Sku: "0001", Price: 120, ChangedAt: since.AddDate(0, 0, 10)},
+			},
+			want: ptr(80),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertions.Equal(tt.want, LowestPriceSince(tt.history, since))
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"go-products.com/m/internal/product/domain/errors"
+)
+
+const (
+	ChannelWeb       = "web"
+	ChannelApp       = "app"
+	ChannelWholesale = "wholesale"
+)
+
+// Fields of a pricing context, as named in validation errors and in the attributes of a principal
+const (
+	PricingChannel = "channel"
+	PricingSegment = "segment"
+	PricingCountry = "country"
+)
+
+var channels = []string{ChannelWeb, ChannelApp, ChannelWholesale}
+
+var countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// PricingContext who prices are computed for. Empty fields are unknown, as a rule target they match any value
+type PricingContext struct {
+	Channel string
+	Segment string
+	Country string
+}
+
+// PricingFields every field of a pricing context
+func PricingFields() []string {
+	return []string{PricingChannel, PricingSegment, PricingCountry}
+}
+
+// NewPricingContext channel and segment are lowercased and country uppercased, so headers, claims and rule targets can
+// use any case
+func NewPricingContext(channel, segment, country string) (PricingContext, error) {
+	pricing := normalizedPricingContext(channel, segment, country)
+
+	var validation errors.Validation
+	pricing.check(&validation)
+	if err := validation.Err(); err != nil {
+		return PricingContext{}, err
+	}
+
+	return pricing, nil
+}
+
+// normalizedPricingContext the context NewPricingContext validates, for callers reporting its errors with others
+func normalizedPricingContext(channel, segment, country string) PricingContext {
+	return PricingContext{
+		Channel: strings.ToLower(strings.TrimSpace(channel)),
+		Segment: strings.ToLower(strings.TrimSpace(segment)),
+		Country: strings.ToUpper(strings.TrimSpace(country)),
+	}
+}
+
+// check records every invalid field, empty fields are valid
+func (p PricingContext) check(validation *errors.Validation) {
+	if p.Channel != "" && !slices.Contains(channels, p.Channel) {
+		validation.Check(PricingChannel, errors.InvalidChannel)
+	}
+
+	if p.Segment != "" && errors.ValidateSlug(p.Segment) != nil {
+		validation.Check(PricingSegment, errors.InvalidSegment)
+	}
+
+	if p.Country != "" && !countryPattern.MatchString(p.Country) {
+		validation.Check(PricingCountry, errors.InvalidCountry)
+	}
+}
+
+// Matches whether a rule targeting target applies, every field set in target must have the same value in p
+func (p PricingContext) Matches(target PricingContext) bool {
+	return (target.Channel == "" || target.Channel == p.Channel) &&
+		(target.Segment == "" || target.Segment == p.Segment) &&
+		(target.Country == "" || target.Country == p.Country)
+}
+
+func (p PricingContext) IsZero() bool {
+	return p == PricingContext{}
+}
+
+// String channel/segment/country, unknown fields are left empty
+func (p PricingContext) String() string {
+	return p.Channel + "/" + p.Segment + "/" + p.Country
+}
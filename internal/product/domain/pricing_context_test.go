@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go-products.com/m/internal/product/domain/errors"
+)
+
+func TestNewPricingContext(t *testing.T) {
+	assertions := require.New(t)
+
+	tests := []struct {
+		name          string
+		channel       string
+		segment       string
+		country       string
+		want          PricingContext
+		expectedCodes map[string]string
+	}{
+		{name: "Empty context is unknown", want: PricingContext{}},
+		{
+			name:    "Values are normalized",
+			channel: " Wholesale",
+			segment: "VIP-Partners",
+			country: "es",
+			want:    PricingContext{Channel: ChannelWholesale, Segment: "vip-partners", Country: "ES"},
+		},
+		{
+			name:          "Every invalid field is reported",
+			channel:       "store",
+			segment:       "top tier",
+			country:       "ESP",
+			expectedCodes: map[string]string{"channel": errors.CodeInvalidChannel, "segment": errors.CodeInvalidSegment, "country": errors.CodeInvalidCountry},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pricing, err := NewPricingContext(tt.channel, tt.segment, tt.country)
+			if tt.expectedCodes != nil {
+				assertions.Equal(tt.expectedCodes, validationCodes(t, err))
+
+				return
+			}
+
+			assertions.NoError(err)
+			assertions.Equal(tt.want, pricing)
+		})
+	}
+}
+
+func TestPricingContext_Matches(t *testing.T) {
+	assertions := require.New(t)
+
+	pricing := PricingContext{Channel: ChannelApp, Segment: "vip", Country: "ES"}
+
+	assertions.True(pricing.Matches(PricingContext{}))
+	assertions.True(pricing.Matches(PricingContext{Channel: ChannelApp, Country: "ES"}))
+	assertions.False(pricing.Matches(PricingContext{Channel: ChannelWeb}))
+	assertions.False(PricingContext{}.Matches(PricingContext{Segment: "vip"}))
+}
+
+func TestDiscountRuleDTO_Target(t *testing.T) {
+	assertions := require.New(t)
+
+	dto := DiscountRuleDTO{Kind: DiscountRuleCategory, Key: "boots", Percentage: 0.4, Channel: "Wholesale", Segment: " VIP", Country: "es"}
+	assertions.Equal(PricingContext{Channel: ChannelWholesale, Segment: "vip", Country: "ES"}, dto.Target())
+
+	headers, err := NewPricingContext(dto.Channel, dto.Segment, dto.Country)
+	assertions.NoError(err)
+	assertions.Equal(headers, dto.Target(), "rule targets are normalized as headers and claims")
+}
@@ -1,8 +1,6 @@
 package domain
 
 import (
-	"sort"
-
 	"go-products.com/m/internal/product/domain/errors"
 )
 
@@ -12,12 +10,25 @@ type Product struct {
 	Category string
 	Price    int
 	Currency string
+	Status   ProductStatus
+	// Version increases on every update and allows detecting concurrent modifications
+	Version int
 }
 
 const EUR = "EUR"
 
-// Discount represents the discount applied to a product, encoding discount function in a function type allows to add new discounts without modifying the Product struct
-type discountFn func() *float64
+// PricedProduct a product with the discount it gets in a pricing context
+type PricedProduct struct {
+	Product
+	Discount Discount
+}
+
+// discountRule a kind of discount, rates are looked up by the product attribute in key
+type discountRule struct {
+	name  string
+	key   string
+	rates map[string]float64
+}
 
 func NewProduct(sku, name, category string, price int) (*Product, error) {
 	product := &Product{
@@ -26,6 +37,8 @@ func NewProduct(sku, name, category string, price int) (*Product, error) {
 		Category: category,
 		Price:    price,
 		Currency: EUR,
+		Status:   ProductStatusActive,
+		Version:  1,
 	}
 
 	if err := product.validate(); err != nil {
@@ -35,87 +48,160 @@ func NewProduct(sku, name, category string, price int) (*Product, error) {
 	return product, nil
 }
 
-func (p *Product) GetDiscount() Discount {
-	discount := p.applyDiscounts([]discountFn{
-		p.bootsDiscount,
-		p.skuDiscount,
-	})
+// AnyVersion expected version of writes applying to whatever version the product has
+const AnyVersion = -1
 
-	if discount == nil {
-		return Discount{
-			FinalPrice: p.Price,
-			Percentage: nil,
-		}
+// CheckVersion fails when the product was modified after expectedVersion was read
+func (p *Product) CheckVersion(expectedVersion int) error {
+	if expectedVersion != AnyVersion && p.Version != expectedVersion {
+		return errors.VersionMismatch
 	}
 
-	finalPrice := int(float64(p.Price) * (1 - *discount))
-	return Discount{
-		FinalPrice: finalPrice,
-		Percentage: discount,
-	}
+	return nil
 }
 
-func (p *Product) applyDiscounts(discountFns []discountFn) *float64 {
-	results := make([]float64, 0)
-	for _, discountFn := range discountFns {
-		if discount := discountFn(); discount != nil {
-			results = append(results, *discount)
-		}
-	}
+func (p *Product) Update(name, category string, price int) error {
+	updated := *p
+	updated.Name = name
+	updated.Category = category
+	updated.Price = price
 
-	if len(results) == 0 {
-		return nil
+	if err := updated.validate(); err != nil {
+		return err
 	}
 
-	sort.Float64s(results)
-	discount := results[len(results)-1]
+	*p = updated
 
-	return &discount
+	return nil
 }
 
-func (p *Product) bootsDiscount() *float64 {
-	categoriesWithDiscount := map[string]float64{
-		"boots": 0.3,
-	}
+func (p *Product) ChangePrice(price int) error {
+	return p.Update(p.Name, p.Category, price)
+}
 
-	if discount, ok := categoriesWithDiscount[p.Category]; ok {
-		return &discount
+func (p *Product) IsArchived() bool {
+	return p.Status == ProductStatusArchived
+}
+
+func (p *Product) TransitionTo(status ProductStatus) error {
+	if !p.Status.CanTransitionTo(status) {
+		return errors.NewInvalidStatusTransition(string(p.Status), string(status))
 	}
 
+	p.Status = status
+
 	return nil
 }
 
-func (p *Product) skuDiscount() *float64 {
-	skusWithDiscount := map[string]float64{
-		"000003": 0.15,
+// Archive soft deletes the product, it is kept in the catalog so it can be restored later
+func (p *Product) Archive() error {
+	return p.TransitionTo(ProductStatusArchived)
+}
+
+// Restore brings back an archived product as draft so it has to be explicitly activated again
+func (p *Product) Restore() error {
+	if !p.IsArchived() {
+		return errors.NewInvalidStatusTransition(string(p.Status), string(ProductStatusDraft))
 	}
 
-	if discount, ok := skusWithDiscount[p.Sku]; ok {
-		return &discount
+	return p.TransitionTo(ProductStatusDraft)
+}
+
+// GetDiscount applies the biggest of the discounts the policy grants to the product in the pricing context, on ties the
+// first rule is kept. Every discount matching the product is kept in the breakdown so it can be explained why the price
+// changed
+func (p *Product) GetDiscount(policy DiscountPolicy, pricing PricingContext) Discount {
+	candidates := p.DiscountCandidates(policy, pricing)
+	applied := -1
+	for i, candidate := range candidates {
+		if applied == -1 || candidate.Percentage > candidates[applied].Percentage {
+			applied = i
+		}
 	}
 
-	return nil
+	breakdown := make([]DiscountOutcome, 0, len(candidates))
+	for i, candidate := range candidates {
+		breakdown = append(breakdown, DiscountOutcome{
+			DiscountCandidate: candidate,
+			Amount:            p.Price - p.discountedPrice(candidate.Percentage),
+			Applied:           i == applied,
+		})
+	}
+
+	if applied == -1 {
+		return Discount{
+			FinalPrice: p.Price,
+			Percentage: nil,
+			Breakdown:  breakdown,
+		}
+	}
+
+	best := candidates[applied]
+	return Discount{
+		FinalPrice: p.discountedPrice(best.Percentage),
+		Percentage: &best.Percentage,
+		Rule:       best.Rule,
+		Breakdown:  breakdown,
+	}
 }
 
-func (p *Product) validate() error {
-	err := errors.NewNonEmptyString("sku", p.Sku)
-	if err != nil {
-		return err
+// PriceProducts the discount of every product in the pricing context, in the same order
+func PriceProducts(products []Product, policy DiscountPolicy, pricing PricingContext) []PricedProduct {
+	priced := make([]PricedProduct, 0, len(products))
+	for _, product := range products {
+		priced = append(priced, PricedProduct{Product: product, Discount: product.GetDiscount(policy, pricing)})
 	}
 
-	err = errors.NewNonEmptyString("name", p.Name)
-	if err != nil {
-		return err
+	return priced
+}
+
+func (p *Product) discountedPrice(percentage float64) int {
+	return int(float64(p.Price) * (1 - percentage))
+}
+
+// DiscountCandidates every discount of the policy matching the product, only the biggest one is applied. Targeted rules
+// come after the ones granted everywhere and only match when they target the pricing context
+func (p *Product) DiscountCandidates(policy DiscountPolicy, pricing PricingContext) []DiscountCandidate {
+	rules := []discountRule{
+		{name: DiscountRuleCategory, key: p.Category, rates: policy.Categories},
+		{name: DiscountRuleSku, key: p.Sku, rates: policy.Skus},
 	}
 
-	err = errors.NewNonEmptyString("category", p.Category)
-	if err != nil {
-		return err
+	candidates := make([]DiscountCandidate, 0)
+	for _, rule := range rules {
+		if percentage, ok := rule.rates[rule.key]; ok {
+			candidates = append(candidates, DiscountCandidate{Rule: rule.name, Key: rule.key, Percentage: percentage})
+		}
 	}
 
-	if err = errors.ValidatePrice(p.Price); err != nil {
-		return err
+	for _, rule := range policy.Targeted {
+		if rule.Key == p.discountKey(rule.Kind) && pricing.Matches(rule.Target) {
+			candidates = append(candidates, DiscountCandidate{Rule: rule.Kind, Key: rule.Key, Percentage: rule.Percentage, Target: rule.Target})
+		}
 	}
 
-	return nil
+	return candidates
+}
+
+// discountKey what rules of kind match the product by, empty for unknown kinds
+func (p *Product) discountKey(kind string) string {
+	switch kind {
+	case DiscountRuleCategory:
+		return p.Category
+	case DiscountRuleSku:
+		return p.Sku
+	default:
+		return ""
+	}
+}
+
+// validate reports every invalid field at once
+func (p *Product) validate() error {
+	var validation errors.Validation
+	validation.Check("sku", errors.NewNonEmptyString("sku", p.Sku))
+	validation.Check("name", errors.NewNonEmptyString("name", p.Name))
+	validation.Check("category", errors.NewNonEmptyString("category", p.Category))
+	validation.Check("price", errors.ValidatePrice(p.Price))
+
+	return validation.Err()
 }
@@ -21,9 +21,15 @@ var _ ProductRepository = &ProductRepositoryMock{}
 //			CreateProductFunc: func(ctx context.Context, product CreateProductDTO) error {
 //				panic("mock out the CreateProduct method")
 //			},
+//			GetProductFunc: func(ctx context.Context, sku string) (*Product, error) {
+//				panic("mock out the GetProduct method")
+//			},
 //			GetProductsFunc: func(ctx context.Context, filters ProductsFilters) ([]Product, error) {
 //				panic("mock out the GetProducts method")
 //			},
+//			UpdateProductFunc: func(ctx context.Context, product *Product) error {
+//				panic("mock out the UpdateProduct method")
+//			},
 //		}
 //
 //		// use mockedProductRepository in code that requires ProductRepository
@@ -34,9 +40,15 @@ type ProductRepositoryMock struct {
 	// CreateProductFunc mocks the CreateProduct method.
 	CreateProductFunc func(ctx context.Context, product CreateProductDTO) error
 
+	// GetProductFunc mocks the GetProduct method.
+	GetProductFunc func(ctx context.Context, sku string) (*Product, error)
+
 	// GetProductsFunc mocks the GetProducts method.
 	GetProductsFunc func(ctx context.Context, filters ProductsFilters) ([]Product, error)
 
+	// UpdateProductFunc mocks the UpdateProduct method.
+	UpdateProductFunc func(ctx context.Context, product *Product) error
+
 	// calls tracks calls to the methods.
 	calls struct {
 		// CreateProduct holds details about calls to the CreateProduct method.
@@ -46,6 +58,13 @@ type ProductRepositoryMock struct {
 			// Product is the product argument value.
 			Product CreateProductDTO
 		}
+		// GetProduct holds details about calls to the GetProduct method.
+		GetProduct []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Sku is the sku argument value.
+			Sku string
+		}
 		// GetProducts holds details about calls to the GetProducts method.
 		GetProducts []struct {
 			// Ctx is the ctx argument value.
@@ -53,9 +72,18 @@ type ProductRepositoryMock struct {
 			// Filters is the filters argument value.
 			Filters ProductsFilters
 		}
+		// UpdateProduct holds details about calls to the UpdateProduct method.
+		UpdateProduct []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Product is the product argument value.
+			Product *Product
+		}
 	}
 	lockCreateProduct sync.RWMutex
+	lockGetProduct    sync.RWMutex
 	lockGetProducts   sync.RWMutex
+	lockUpdateProduct sync.RWMutex
 }
 
 // CreateProduct calls CreateProductFunc.
@@ -94,6 +122,42 @@ func (mock *ProductRepositoryMock) CreateProductCalls() []struct {
 	return calls
 }
 
+// GetProduct calls GetProductFunc.
+func (mock *ProductRepositoryMock) GetProduct(ctx context.Context, sku string) (*Product, error) {
+	if mock.GetProductFunc == nil {
+		panic("ProductRepositoryMock.GetProductFunc: method is nil but ProductRepository.GetProduct was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Sku string
+	}{
+		Ctx: ctx,
+		Sku: sku,
+	}
+	mock.lockGetProduct.Lock()
+	mock.calls.GetProduct = append(mock.calls.GetProduct, callInfo)
+	mock.lockGetProduct.Unlock()
+	return mock.GetProductFunc(ctx, sku)
+}
+
+// GetProductCalls gets all the calls that were made to GetProduct.
+// Check the length with:
+//
+//	len(mockedProductRepository.GetProductCalls())
+func (mock *ProductRepositoryMock) GetProductCalls() []struct {
+	Ctx context.Context
+	Sku string
+} {
+	var calls []struct {
+		Ctx context.Context
+		Sku string
+	}
+	mock.lockGetProduct.RLock()
+	calls = mock.calls.GetProduct
+	mock.lockGetProduct.RUnlock()
+	return calls
+}
+
 // GetProducts calls GetProductsFunc.
 func (mock *ProductRepositoryMock) GetProducts(ctx context.Context, filters ProductsFilters) ([]Product, error) {
 	if mock.GetProductsFunc == nil {
@@ -129,3 +193,39 @@ func (mock *ProductRepositoryMock) GetProductsCalls() []struct {
 	mock.lockGetProducts.RUnlock()
 	return calls
 }
+
+// UpdateProduct calls UpdateProductFunc.
+func (mock *ProductRepositoryMock) UpdateProduct(ctx context.Context, product *Product) error {
+	if mock.UpdateProductFunc == nil {
+		panic("ProductRepositoryMock.UpdateProductFunc: method is nil but ProductRepository.UpdateProduct was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Product *Product
+	}{
+		Ctx:     ctx,
+		Product: product,
+	}
+	mock.lockUpdateProduct.Lock()
+	mock.calls.UpdateProduct = append(mock.calls.UpdateProduct, callInfo)
+	mock.lockUpdateProduct.Unlock()
+	return mock.UpdateProductFunc(ctx, product)
+}
+
+// UpdateProductCalls gets all the calls that were made to UpdateProduct.
+// Check the length with:
+//
+//	len(mockedProductRepository.UpdateProductCalls())
+func (mock *ProductRepositoryMock) UpdateProductCalls() []struct {
+	Ctx     context.Context
+	Product *Product
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Product *Product
+	}
+	mock.lockUpdateProduct.RLock()
+	calls = mock.calls.UpdateProduct
+	mock.lockUpdateProduct.RUnlock()
+	return calls
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"slices"
+
+	"go-products.com/m/internal/product/domain/errors"
+)
+
+type ProductStatus string
+
+const (
+	ProductStatusDraft        ProductStatus = "draft"
+	ProductStatusActive       ProductStatus = "active"
+	ProductStatusDiscontinued ProductStatus = "discontinued"
+	// ProductStatusArchived is the soft deleted state, archived products can only be restored back to draft
+	ProductStatusArchived ProductStatus = "archived"
+)
+
+var allowedStatusTransitions = map[ProductStatus][]ProductStatus{
+	ProductStatusDraft:        {ProductStatusActive, ProductStatusArchived},
+	ProductStatusActive:       {ProductStatusDiscontinued, ProductStatusArchived},
+	ProductStatusDiscontinued: {ProductStatusActive, ProductStatusArchived},
+	ProductStatusArchived:     {ProductStatusDraft},
+}
+
+func ParseProductStatus(status string) (ProductStatus, error) {
+	productStatus := ProductStatus(status)
+	if _, ok := allowedStatusTransitions[productStatus]; !ok {
+		return "", errors.InvalidStatus
+	}
+
+	return productStatus, nil
+}
+
+func (s ProductStatus) CanTransitionTo(status ProductStatus) bool {
+	return slices.Contains(allowedStatusTransitions[s], status)
+}
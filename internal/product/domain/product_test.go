@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"go-products.com/m/internal/product/domain/errors"
 )
 
 func TestNewProduct(t *testing.T) {
@@ -35,6 +37,8 @@ func TestNewProduct(t *testing.T) {
 				Category: "sandals",
 				Price:    100,
 				Currency: EUR,
+				Status:   ProductStatusActive,
+				Version:  1,
 			},
 			wantErr: false,
 		},
@@ -92,6 +96,28 @@ func TestNewProduct(t *testing.T) {
 	}
 }
 
+func TestNewProduct_ReportsEveryInvalidField(t *testing.T) {
+	assertions := require.New(t)
+
+	_, err := NewProduct("", "name", "", 0)
+
+	var validationErr errors.ErrValidation
+	assertions.ErrorAs(err, &validationErr)
+	assertions.EqualError(err, "sku cannot be empty; category cannot be empty; price must be greater than 0")
+
+	type field struct{ name, code string }
+	fields := make([]field, 0)
+	for _, invalid := range validationErr.Fields() {
+		fields = append(fields, field{name: invalid.Field(), code: invalid.Code()})
+	}
+	assertions.Equal([]field{
+		{name: "sku", code: errors.CodeRequired},
+		{name: "category", code: errors.CodeRequired},
+		{name: "price", code: errors.CodeInvalidPrice},
+	}, fields)
+	assertions.ErrorIs(err, errors.InvalidPrice)
+}
+
 func TestProduct_GetDiscount(t *testing.T) {
 	assertions := require.New(t)
 
@@ -119,6 +145,10 @@ func TestProduct_GetDiscount(t *testing.T) {
 			want: Discount{
 				FinalPrice: 70,
 				Percentage: ptr(0.3),
+				Rule:       DiscountRuleCategory,
+				Breakdown: []DiscountOutcome{
+					{DiscountCandidate: DiscountCandidate{Rule: DiscountRuleCategory, Key: "boots", Percentage: 0.3}, Amount: 30, Applied: true},
+				},
 			},
 		},
 		{
@@ -133,6 +163,10 @@ func TestProduct_GetDiscount(t *testing.T) {
 			want: Discount{
 				FinalPrice: 85,
 				Percentage: ptr(0.15),
+				Rule:       DiscountRuleSku,
+				Breakdown: []DiscountOutcome{
+					{DiscountCandidate: DiscountCandidate{Rule: DiscountRuleSku, Key: "000003", Percentage: 0.15}, Amount: 15, Applied: true},
+				},
 			},
 		},
 		{
@@ -147,6 +181,25 @@ func TestProduct_GetDiscount(t *testing.T) {
 			want: Discount{
 				FinalPrice: 70,
 				Percentage: ptr(0.3),
+				Rule:       DiscountRuleCategory,
+				Breakdown: []DiscountOutcome{
+					{DiscountCandidate: DiscountCandidate{Rule: DiscountRuleCategory, Key: "boots", Percentage: 0.3}, Amount: 30, Applied: true},
+					{DiscountCandidate: DiscountCandidate{Rule: DiscountRuleSku, Key: "000003", Percentage: 0.15}, Amount: 15, Applied: false},
+				},
+			},
+		},
+		{
+			name: "Get no discount when no rule matches",
+			fields: fields{
+				Sku:      "0004",
+				Name:     "Product 4",
+				Category: "sandals",
+				Price:    100,
+				Currency: EUR,
+			},
+			want: Discount{
+				FinalPrice: 100,
+				Breakdown:  []DiscountOutcome{},
 			},
 		},
 	}
@@ -160,11 +213,157 @@ func TestProduct_GetDiscount(t *testing.T) {
 				Currency: tt.fields.Currency,
 			}
 
-			assertions.Equal(tt.want, p.GetDiscount())
+			assertions.Equal(tt.want, p.GetDiscount(DefaultDiscountPolicy(), PricingContext{}))
 		})
 	}
 }
 
+func TestProduct_GetDiscount_PricingContext(t *testing.T) {
+	assertions := require.New(t)
+
+	wholesale := PricingContext{Channel: ChannelWholesale}
+	policy := NewDiscountPolicy([]DiscountRule{
+		{Kind: DiscountRuleCategory, Key: "boots", Percentage: 0.3},
+		{Kind: DiscountRuleCategory, Key: "boots", Percentage: 0.4, Target: wholesale},
+		{Kind: DiscountRuleSku, Key: "000003", Percentage: 0.1, Target: PricingContext{Segment: "vip", Country: "ES"}},
+	})
+	product := &Product{Sku: "000003", Category: "boots", Price: 100, Currency: EUR}
+
+	tests := []struct {
+		name    string
+		pricing PricingContext
+		want    Discount
+	}{
+		{
+			name: "Targeted rules are left out without a pricing context",
+			want: Discount{
+				FinalPrice: 70,
+				Percentage: ptr(0.3),
+				Rule:       DiscountRuleCategory,
+				Breakdown: []DiscountOutcome{
+					{DiscountCandidate: DiscountCandidate{Rule: DiscountRuleCategory, Key: "boots", Percentage: 0.3}, Amount: 30, Applied: true},
+				},
+			},
+		},
+		{
+			name:    "Targeted rule wins when it is bigger",
+			pricing: PricingContext{Channel: ChannelWholesale, Country: "ES"},
+			want: Discount{
+				FinalPrice: 60,
+				Percentage: ptr(0.4),
+				Rule:       DiscountRuleCategory,
+				Breakdown: []DiscountOutcome{
+					{DiscountCandidate: DiscountCandidate{Rule: DiscountRuleCategory, Key: "boots", Percentage: 0.3}, Amount: 30, Applied: false},
+					{DiscountCandidate: DiscountCandidate{Rule: DiscountRuleCategory, Key: "boots", Percentage: 0.4, Target: wholesale}, Amount: 40, Applied: true},
+				},
+			},
+		},
+		{
+			name:    "Rules targeting several fields need all of them",
+			pricing: PricingContext{Channel: ChannelWeb, Segment: "vip", Country: "ES"},
+			want: Discount{
+				FinalPrice: 70,
+				Percentage: ptr(0.3),
+				Rule:       DiscountRuleCategory,
+				Breakdown: []DiscountOutcome{
+					{DiscountCandidate: DiscountCandidate{Rule: DiscountRuleCategory, Key: "boots", Percentage: 0.3}, Amount: 30, Applied: true},
+					{DiscountCandidate: DiscountCandidate{Rule: DiscountRuleSku, Key: "000003", Percentage: 0.1, Target: PricingContext{Segment: "vip", Country: "ES"}}, Amount: 10, Applied: false},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertions.Equal(tt.want, product.GetDiscount(policy, tt.pricing))
+		})
+	}
+}
+
+func TestPriceProducts(t *testing.T) {
+	assertions := require.New(t)
+
+	policy := NewDiscountPolicy([]DiscountRule{{Kind: DiscountRuleCategory, Key: "boots", Percentage: 0.4, Target: PricingContext{Channel: ChannelApp}}})
+	products := []Product{
+		{Sku: "000001", Category: "boots", Price: 100, Currency: EUR},
+		{Sku: "000004", Category: "sandals", Price: 100, Currency: EUR},
+	}
+
+	priced := PriceProducts(products, policy, PricingContext{Channel: ChannelApp})
+	assertions.Len(priced, 2)
+	assertions.Equal(products[0], priced[0].Product)
+	assertions.Equal(60, priced[0].Discount.FinalPrice)
+	assertions.Equal(100, priced[1].Discount.FinalPrice)
+
+	assertions.Equal(100, PriceProducts(products, policy, PricingContext{Channel: ChannelWeb})[0].Discount.FinalPrice)
+}
+
+func TestDiscount_FormattedPercentage(t *testing.T) {
+	assertions := require.New(t)
+
+	percentage, ok := Discount{FinalPrice: 70, Percentage: ptr(0.3)}.FormattedPercentage()
+	assertions.True(ok)
+	assertions.Equal("30%", percentage)
+
+	_, ok = Discount{FinalPrice: 100}.FormattedPercentage()
+	assertions.False(ok)
+
+	assertions.Equal("15%", DiscountCandidate{Rule: DiscountRuleSku, Key: "000003", Percentage: 0.15}.FormattedPercentage())
+	// multiplied by 100 these are 28.999… and 56.999…, percentages are rounded, not truncated
+	assertions.Equal("29%", DiscountCandidate{Rule: DiscountRuleSku, Key: "000003", Percentage: 0.29}.FormattedPercentage())
+	assertions.Equal("57%", DiscountCandidate{Rule: DiscountRuleSku, Key: "000003", Percentage: 0.57}.FormattedPercentage())
+}
+
 func ptr[T any](v T) *T {
 	return &v
 }
+
+func TestProduct_TransitionTo(t *testing.T) {
+	assertions := require.New(t)
+
+	tests := []struct {
+		name    string
+		from    ProductStatus
+		to      ProductStatus
+		wantErr bool
+	}{
+		{name: "Activate draft product", from: ProductStatusDraft, to: ProductStatusActive},
+		{name: "Discontinue active product", from: ProductStatusActive, to: ProductStatusDiscontinued},
+		{name: "Reactivate discontinued product", from: ProductStatusDiscontinued, to: ProductStatusActive},
+		{name: "Archive active product", from: ProductStatusActive, to: ProductStatusArchived},
+		{name: "Discontinue draft product returns error", from: ProductStatusDraft, to: ProductStatusDiscontinued, wantErr: true},
+		{name: "Activate archived product returns error", from: ProductStatusArchived, to: ProductStatusActive, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Product{Sku: "0001", Status: tt.from}
+
+			err := p.TransitionTo(tt.to)
+
+			assertions.Equal(err != nil, tt.wantErr)
+			if !tt.wantErr {
+				assertions.Equal(tt.to, p.Status)
+			}
+		})
+	}
+}
+
+func TestProduct_Restore(t *testing.T) {
+	assertions := require.New(t)
+
+	archived := &Product{Sku: "0001", Status: ProductStatusArchived}
+	assertions.NoError(archived.Restore())
+	assertions.Equal(ProductStatusDraft, archived.Status)
+
+	active := &Product{Sku: "0002", Status: ProductStatusActive}
+	assertions.Error(active.Restore())
+	assertions.Equal(ProductStatusActive, active.Status)
+}
+
+func TestDiscountPolicy_Validate(t *testing.T) {
+	assertions := require.New(t)
+
+	assertions.NoError(DefaultDiscountPolicy().Validate())
+	assertions.NoError(DiscountPolicy{}.Validate())
+	assertions.Error(DiscountPolicy{Categories: map[string]float64{"boots": 0}}.Validate())
+	assertions.Error(DiscountPolicy{Skus: map[string]float64{"000001": 1.5}}.Validate())
+}
@@ -1,22 +1,90 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 //go:generate moq -out product_repository_mock.go . ProductRepository
 type ProductRepository interface {
 	GetProducts(ctx context.Context, filters ProductsFilters) ([]Product, error)
+	GetProduct(ctx context.Context, sku string) (*Product, error)
 	CreateProduct(ctx context.Context, product CreateProductDTO) error
+	// UpdateProduct persists the product only if it is still at product.Version, failing with errors.VersionMismatch
+	// otherwise, on success product.Version is increased
+	UpdateProduct(ctx context.Context, product *Product) error
 }
 
+//go:generate moq -out price_history_repository_mock.go . PriceHistoryRepository
+type PriceHistoryRepository interface {
+	GetPriceHistory(ctx context.Context, sku string) ([]PriceChange, error)
+	GetLowestPrices(ctx context.Context, skus []string, since time.Time) (map[string]int, error)
+}
+
+//go:generate moq -out category_repository_mock.go . CategoryRepository
+type CategoryRepository interface {
+	GetCategories(ctx context.Context) ([]Category, error)
+	CreateCategory(ctx context.Context, category CreateCategoryDTO) error
+}
+
+// DiscountRuleRepository rules are identified by the ID given when created, a kind and key pair can only have one rule per target
+type DiscountRuleRepository interface {
+	GetDiscountRules(ctx context.Context) ([]DiscountRule, error)
+	GetDiscountRule(ctx context.Context, id int) (*DiscountRule, error)
+	// CreateDiscountRule on success rule.ID is set
+	CreateDiscountRule(ctx context.Context, rule *DiscountRule) error
+	UpdateDiscountRule(ctx context.Context, rule *DiscountRule) error
+	DeleteDiscountRule(ctx context.Context, id int) error
+}
+
+// CatalogVersionRepository the catalog version changes on every write to products, categories, prices or discount rules
+type CatalogVersionRepository interface {
+	GetCatalogVersion(ctx context.Context) (int, error)
+}
+
+// ProductsFilters narrows the products listing, Category matches the given category and all of its descendants
+// and a nil Status includes products in every status
 type ProductsFilters struct {
 	Category      *string
 	PriceLessThan *int
+	Status        *ProductStatus
 	Limit         *int
 }
 
+// CreateProductDTO products are created as active when no status is given
 type CreateProductDTO struct {
 	Sku      string `json:"sku"`
 	Name     string `json:"name"`
 	Category string `json:"category"`
 	Price    int    `json:"price"`
+	Status   string `json:"status,omitempty"`
+}
+
+// UpdateProductDTO nil fields are left unchanged
+type UpdateProductDTO struct {
+	Name     *string `json:"name"`
+	Category *string `json:"category"`
+	Price    *int    `json:"price"`
+}
+
+type CreateCategoryDTO struct {
+	Slug   string  `json:"slug"`
+	Name   string  `json:"name"`
+	Parent *string `json:"parent"`
+}
+
+// DiscountRuleDTO percentage is a fraction of the price, 0.3 takes 30% off. Channel, segment and country restrict the
+// rule to the pricing contexts having them, empty ones match any
+type DiscountRuleDTO struct {
+	Kind       string  `json:"kind"`
+	Key        string  `json:"key"`
+	Percentage float64 `json:"percentage"`
+	Channel    string  `json:"channel"`
+	Segment    string  `json:"segment"`
+	Country    string  `json:"country"`
+}
+
+// Target normalized as NewPricingContext does with headers and claims, it is validated with the rest of the rule
+func (d DiscountRuleDTO) Target() PricingContext {
+	return normalizedPricingContext(d.Channel, d.Segment, d.Country)
 }
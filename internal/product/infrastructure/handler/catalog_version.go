@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/shared/api"
+)
+
+// CatalogVersion versions responses built from the catalog. The lowest price of the last 30 days also moves with time,
+// so the version changes at least every hour even if nothing was written. Prices depend on the pricing context, each
+// one resolved by Pricing gets its own version
+func CatalogVersion(catalogVersionRepository domain.CatalogVersionRepository) api.VersionFunc {
+	return func(ctx context.Context) (string, error) {
+		version, err := catalogVersionRepository.GetCatalogVersion(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		catalogVersion := strconv.Itoa(version) + "-" + strconv.FormatInt(time.Now().Truncate(time.Hour).Unix(), 10)
+		if pricing := pricingContextFrom(ctx); !pricing.IsZero() {
+			catalogVersion += "-" + pricing.String()
+		}
+
+		return catalogVersion, nil
+	}
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-products.com/m/internal/product/domain"
+	domainErrors "go-products.com/m/internal/product/domain/errors"
+	"go-products.com/m/internal/product/use_cases"
+	"go-products.com/m/internal/shared/api"
+)
+
+type changeProductStatusRequest struct {
+	Status string `json:"status"`
+}
+
+func HandleChangeProductStatus(productsRepository domain.ProductRepository) http.HandlerFunc {
+	changeProductStatusUseCase := use_cases.NewChangeProductStatusUseCase(productsRepository)
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		expectedVersion, ok := getExpectedVersion(writer, request)
+		if !ok {
+			return
+		}
+
+		body, err := api.DecodeBody[changeProductStatusRequest](request)
+		if err != nil {
+			api.InvalidRequest(writer, err.Error())
+
+			return
+		}
+
+		status, err := domain.ParseProductStatus(body.Status)
+		if err != nil {
+			invalidRequest(writer, domainErrors.NewInvalidField("status", err))
+
+			return
+		}
+
+		product, err := changeProductStatusUseCase.Execute(request.Context(), api.GetPathParam(request, "sku"), status, expectedVersion)
+		writeProductUpdate(writer, request, product, err)
+	}
+}
+
+func HandleDeleteProduct(productsRepository domain.ProductRepository) http.HandlerFunc {
+	deleteProductUseCase := use_cases.NewDeleteProductUseCase(productsRepository)
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		expectedVersion, ok := getExpectedVersion(writer, request)
+		if !ok {
+			return
+		}
+
+		product, err := deleteProductUseCase.Execute(request.Context(), api.GetPathParam(request, "sku"), expectedVersion)
+		writeProductUpdate(writer, request, product, err)
+	}
+}
+
+func HandleRestoreProduct(productsRepository domain.ProductRepository) http.HandlerFunc {
+	restoreProductUseCase := use_cases.NewRestoreProductUseCase(productsRepository)
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		expectedVersion, ok := getExpectedVersion(writer, request)
+		if !ok {
+			return
+		}
+
+		product, err := restoreProductUseCase.Execute(request.Context(), api.GetPathParam(request, "sku"), expectedVersion)
+		writeProductUpdate(writer, request, product, err)
+	}
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go-products.com/m/internal/product/infrastructure/persistance"
+	"go-products.com/m/internal/product/infrastructure/persistance/migrations"
+	sharedDatabaseUtils "go-products.com/m/internal/shared/database"
+)
+
+func TestIntegration_ProductLifecycle(t *testing.T) {
+	assertions := require.New(t)
+
+	database, err := sharedDatabaseUtils.GenerateDatabaseConnection(sharedDatabaseUtils.DatabaseConnection{
+		DatabaseName: "file:product_lifecycle?mode=memory&cache=shared",
+	}, migrations.CreateProductsDatabase)
+	assertions.NoError(err)
+
+	err = migrations.InitCategories(context.Background(), persistance.NewCategoriesSQLiteRepository(database), path.Join(".", "testdata", "categories.json"))
+	assertions.NoError(err)
+
+	productsRepository := persistance.NewProductsSQLiteRepository(database)
+	err = migrations.InitProducts(context.Background(), productsRepository, path.Join(".", "testdata", "products.json"))
+	assertions.NoError(err)
+
+	priceHistoryRepository := persistance.NewPriceHistorySQLiteRepository(database)
+
+	router := http.NewServeMux()
+	router.HandleFunc("GET /api/v1/products", HandleGetProducts(productsRepository, priceHistoryRepository, persistance.NewCategoriesSQLiteRepository(database), DefaultCatalogOptions()))
+	router.HandleFunc("GET /api/v1/admin/products", HandleAdminGetProducts(productsRepository, priceHistoryRepository, persistance.NewCategoriesSQLiteRepository(database), DefaultCatalogOptions()))
+	router.HandleFunc("DELETE /api/v1/products/{sku}", HandleDeleteProduct(productsRepository))
+	router.HandleFunc("POST /api/v1/products/{sku}/restore", HandleRestoreProduct(productsRepository))
+	router.HandleFunc("PUT /api/v1/products/{sku}/status", HandleChangeProductStatus(productsRepository))
+
+	listSkus := func(path string) []string {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, path, nil)
+		assertions.NoError(err)
+
+		router.ServeHTTP(recorder, request)
+		assertions.Equal(http.StatusOK, recorder.Code)
+
+		var body struct {
+			Content []struct {
+				Sku string `json:"sku"`
+			} `json:"content"`
+		}
+		assertions.NoError(json.Unmarshal(recorder.Body.Bytes(), &body))
+
+		skus := make([]string, 0)
+		for _, product := range body.Content {
+			skus = append(skus, product.Sku)
+		}
+
+		return skus
+	}
+
+	testCases := []struct {
+		name               string
+		method             string
+		path               string
+		body               string
+		ifMatch            string
+		expectedStatusCode int
+		expectedPublicSkus []string
+		expectedAdminSkus  []string
+	}{
+		{
+			name:               "Delete product without If-Match returns a 428",
+			method:             http.MethodDelete,
+			path:               "/api/v1/products/000001",
+			expectedStatusCode: http.StatusPreconditionRequired,
+			expectedPublicSkus: []string{"000001", "000002", "000003", "000004", "000005"},
+			expectedAdminSkus:  []string{},
+		},
+		{
+			name:               "Delete product with stale version returns a 412",
+			method:             http.MethodDelete,
+			path:               "/api/v1/products/000001",
+			ifMatch:            `"7"`,
+			expectedStatusCode: http.StatusPreconditionFailed,
+			expectedPublicSkus: []string{"000001", "000002", "000003", "000004", "000005"},
+			expectedAdminSkus:  []string{},
+		},
+		{
+			name:               "Delete product archives it and hides it from the public listing",
+			method:             http.MethodDelete,
+			path:               "/api/v1/products/000001",
+			ifMatch:            `"1"`,
+			expectedStatusCode: http.StatusNoContent,
+			expectedPublicSkus: []string{"000002", "000003", "000004", "000005"},
+			expectedAdminSkus:  []string{"000001"},
+		},
+		{
+			name:               "Activate archived product returns a 409",
+			method:             http.MethodPut,
+			path:               "/api/v1/products/000001/status",
+			body:               `{"status": "active"}`,
+			ifMatch:            `"2"`,
+			expectedStatusCode: http.StatusConflict,
+			expectedPublicSkus: []string{"000002", "000003", "000004", "000005"},
+			expectedAdminSkus:  []string{"000001"},
+		},
+		{
+			name:               "Restore archived product brings it back as draft",
+			method:             http.MethodPost,
+			path:               "/api/v1/products/000001/restore",
+			ifMatch:            `"2"`,
+			expectedStatusCode: http.StatusNoContent,
+			expectedPublicSkus: []string{"000002", "000003", "000004", "000005"},
+			expectedAdminSkus:  []string{},
+		},
+		{
+			name:               "Activate restored product shows it again in the public listing",
+			method:             http.MethodPut,
+			path:               "/api/v1/products/000001/status",
+			body:               `{"status": "active"}`,
+			ifMatch:            `"3"`,
+			expectedStatusCode: http.StatusNoContent,
+			expectedPublicSkus: []string{"000001", "000002", "000003", "000004", "000005"},
+			expectedAdminSkus:  []string{},
+		},
+		{
+			name:               "Delete unknown product returns a 404",
+			method:             http.MethodDelete,
+			path:               "/api/v1/products/999999",
+			ifMatch:            `"1"`,
+			expectedStatusCode: http.StatusNotFound,
+			expectedPublicSkus: []string{"000001", "000002", "000003", "000004", "000005"},
+			expectedAdminSkus:  []string{},
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			assertions.NoError(err)
+			if tt.ifMatch != "" {
+				request.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			router.ServeHTTP(recorder, request)
+
+			assertions.Equal(tt.expectedStatusCode, recorder.Code)
+			assertions.Equal(tt.expectedPublicSkus, listSkus("/api/v1/products"))
+			assertions.Equal(tt.expectedAdminSkus, listSkus("/api/v1/admin/products?status=archived"))
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/product/infrastructure/handler/response"
+	"go-products.com/m/internal/product/use_cases"
+	"go-products.com/m/internal/shared/api"
+)
+
+func HandleCreateCategory(categoriesRepository domain.CategoryRepository) http.HandlerFunc {
+	createCategoryUseCase := use_cases.NewCreateCategoryUseCase(categoriesRepository)
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		categoryDTO, err := api.DecodeBody[domain.CreateCategoryDTO](request)
+		if err != nil {
+			api.InvalidRequest(writer, err.Error())
+
+			return
+		}
+
+		category, err := createCategoryUseCase.Execute(request.Context(), categoryDTO)
+		if err != nil {
+			writeError(writer, request, err)
+
+			return
+		}
+
+		api.Created(writer, response.FromDomainCategory(*category))
+	}
+}
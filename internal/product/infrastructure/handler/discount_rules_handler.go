@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-products.com/m/internal/product/domain"
+	domainErrors "go-products.com/m/internal/product/domain/errors"
+	"go-products.com/m/internal/product/infrastructure/handler/response"
+	"go-products.com/m/internal/product/use_cases"
+	"go-products.com/m/internal/shared/api"
+)
+
+func HandleGetDiscountRules(discountRulesRepository domain.DiscountRuleRepository) http.HandlerFunc {
+	getDiscountRulesUseCase := use_cases.NewGetDiscountRulesUseCase(discountRulesRepository)
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		rules, err := getDiscountRulesUseCase.Execute(request.Context())
+		if err != nil {
+			writeError(writer, request, err)
+
+			return
+		}
+
+		api.Success(writer, response.FromDomainDiscountRules(rules))
+	}
+}
+
+func HandleGetDiscountRule(discountRulesRepository domain.DiscountRuleRepository) http.HandlerFunc {
+	getDiscountRuleUseCase := use_cases.NewGetDiscountRuleUseCase(discountRulesRepository)
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, ok := getDiscountRuleID(writer, request)
+		if !ok {
+			return
+		}
+
+		rule, err := getDiscountRuleUseCase.Execute(request.Context(), id)
+		if err != nil {
+			writeError(writer, request, err)
+
+			return
+		}
+
+		api.Success(writer, response.FromDomainDiscountRule(*rule))
+	}
+}
+
+func HandleCreateDiscountRule(discountRulesRepository domain.DiscountRuleRepository) http.HandlerFunc {
+	createDiscountRuleUseCase := use_cases.NewCreateDiscountRuleUseCase(discountRulesRepository)
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ruleDTO, err := api.DecodeBody[domain.DiscountRuleDTO](request)
+		if err != nil {
+			api.InvalidRequest(writer, err.Error())
+
+			return
+		}
+
+		rule, err := createDiscountRuleUseCase.Execute(request.Context(), ruleDTO)
+		if err != nil {
+			writeError(writer, request, err)
+
+			return
+		}
+
+		api.Created(writer, response.FromDomainDiscountRule(*rule))
+	}
+}
+
+func HandleUpdateDiscountRule(discountRulesRepository domain.DiscountRuleRepository) http.HandlerFunc {
+	updateDiscountRuleUseCase := use_cases.NewUpdateDiscountRuleUseCase(discountRulesRepository)
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, ok := getDiscountRuleID(writer, request)
+		if !ok {
+			return
+		}
+
+		ruleDTO, err := api.DecodeBody[domain.DiscountRuleDTO](request)
+		if err != nil {
+			api.InvalidRequest(writer, err.Error())
+
+			return
+		}
+
+		rule, err := updateDiscountRuleUseCase.Execute(request.Context(), id, ruleDTO)
+		if err != nil {
+			writeError(writer, request, err)
+
+			return
+		}
+
+		api.Success(writer, response.FromDomainDiscountRule(*rule))
+	}
+}
+
+func HandleDeleteDiscountRule(discountRulesRepository domain.DiscountRuleRepository) http.HandlerFunc {
+	deleteDiscountRuleUseCase := use_cases.NewDeleteDiscountRuleUseCase(discountRulesRepository)
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, ok := getDiscountRuleID(writer, request)
+		if !ok {
+			return
+		}
+
+		if err := deleteDiscountRuleUseCase.Execute(request.Context(), id); err != nil {
+			writeError(writer, request, err)
+
+			return
+		}
+
+		api.NoContent(writer)
+	}
+}
+
+// getDiscountRuleID ids are numbers, any other value cannot identify a rule so it is answered as not found
+func getDiscountRuleID(writer http.ResponseWriter, request *http.Request) (int, bool) {
+	id, err := strconv.Atoi(api.GetPathParam(request, "id"))
+	if err != nil {
+		writeError(writer, request, domainErrors.DiscountRuleNotFound)
+
+		return 0, false
+	}
+
+	return id, true
+}
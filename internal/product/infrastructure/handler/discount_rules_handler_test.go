@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"context"
+	"embed"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/product/infrastructure/persistance"
+	"go-products.com/m/internal/product/infrastructure/persistance/migrations"
+	sharedDatabaseUtils "go-products.com/m/internal/shared/database"
+)
+
+//go:embed testdata/discounts/*.json
+var discountsContent embed.FS
+
+func TestIntegration_HandleDiscountRules(t *testing.T) {
+	assertions := require.New(t)
+
+	database, err := sharedDatabaseUtils.GenerateDatabaseConnection(sharedDatabaseUtils.DatabaseConnection{
+		DatabaseName: "file:discount_rules?mode=memory&cache=shared",
+	}, migrations.CreateProductsDatabase)
+	assertions.NoError(err)
+
+	err = migrations.InitCategories(context.Background(), persistance.NewCategoriesSQLiteRepository(database), path.Join(".", "testdata", "categories.json"))
+	assertions.NoError(err)
+
+	productsRepository := persistance.NewProductsSQLiteRepository(database)
+	err = migrations.InitProducts(context.Background(), productsRepository, path.Join(".", "testdata", "products.json"))
+	assertions.NoError(err)
+
+	discountRulesRepository := persistance.NewDiscountRulesSQLiteRepository(database)
+	err = migrations.InitDiscountRules(context.Background(), discountRulesRepository, domain.DefaultDiscountPolicy())
+	assertions.NoError(err)
+
+	options := DefaultCatalogOptions()
+	options.DiscountRules = discountRulesRepository
+
+	router := http.NewServeMux()
+	router.HandleFunc("GET /api/v1/discounts", HandleGetDiscountRules(discountRulesRepository))
+	router.HandleFunc("POST /api/v1/discounts", HandleCreateDiscountRule(discountRulesRepository))
+	router.HandleFunc("POST /api/v1/discount-simulations", HandleSimulateDiscountRules(productsRepository, discountRulesRepository))
+	router.HandleFunc("GET /api/v1/discounts/{id}", HandleGetDiscountRule(discountRulesRepository))
+	router.HandleFunc("PUT /api/v1/discounts/{id}", HandleUpdateDiscountRule(discountRulesRepository))
+	router.HandleFunc("DELETE /api/v1/discounts/{id}", HandleDeleteDiscountRule(discountRulesRepository))
+	router.Handle("GET /api/v1/products/{sku}", Pricing(false)(HandleGetProduct(productsRepository, persistance.NewPriceHistorySQLiteRepository(database), persistance.NewCategoriesSQLiteRepository(database), options)))
+
+	// cases run in order, each one sees the rules left by the previous ones
+	testCases := []struct {
+		name               string
+		method             string
+		path               string
+		body               string
+		headers            map[string]string
+		expectedStatusCode int
+		expectedResponse   string
+		expectedContains   string
+	}{
+		{
+			name:               "List the seeded rules returns a 200",
+			method:             http.MethodGet,
+			path:               "/api/v1/discounts",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   "testdata/discounts/successful_list_response.json",
+		},
+		{
+			name:               "Create rule successfully returns a 201",
+			method:             http.MethodPost,
+			path:               "/api/v1/discounts",
+			body:               `{"kind": "category", "key": "sandals", "percentage": 0.1}`,
+			expectedStatusCode: http.StatusCreated,
+			expectedResponse:   "testdata/discounts/successful_create_response.json",
+		},
+		{
+			name:               "Create rule for a kind and key already used returns a 409",
+			method:             http.MethodPost,
+			path:               "/api/v1/discounts",
+			body:               `{"kind": "category", "key": "sandals", "percentage": 0.2}`,
+			expectedStatusCode: http.StatusConflict,
+			expectedResponse:   "testdata/discounts/error_conflict_response.json",
+		},
+		{
+			name:               "Create invalid rule returns a 400 with every invalid field",
+			method:             http.MethodPost,
+			path:               "/api/v1/discounts",
+			body:               `{"kind": "brand", "key": "", "percentage": 1.5}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   "testdata/discounts/error_invalid_rule_response.json",
+		},
+		{
+			name:               "New rule applies to prices at once",
+			method:             http.MethodGet,
+			path:               "/api/v1/products/000004",
+			expectedStatusCode: http.StatusOK,
+			expectedContains:   `"final":71550`,
+		},
+		{
+			name:               "Update rule successfully returns a 200",
+			method:             http.MethodPut,
+			path:               "/api/v1/discounts/3",
+			body:               `{"kind": "category", "key": "sandals", "percentage": 0.2}`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   "testdata/discounts/successful_update_response.json",
+		},
+		{
+			name:               "Get rule successfully returns a 200",
+			method:             http.MethodGet,
+			path:               "/api/v1/discounts/3",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   "testdata/discounts/successful_update_response.json",
+		},
+		{
+			name:               "Update unknown rule returns a 404",
+			method:             http.MethodPut,
+			path:               "/api/v1/discounts/99",
+			body:               `{"kind": "category", "key": "sneakers", "percentage": 0.2}`,
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   "testdata/discounts/error_not_found_response.json",
+		},
+		{
+			name:               "Get rule with an id that is not a number returns a 404",
+			method:             http.MethodGet,
+			path:               "/api/v1/discounts/sandals",
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   "testdata/discounts/error_not_found_response.json",
+		},
+		{
+			name:               "Delete rule successfully returns a 204",
+			method:             http.MethodDelete,
+			path:               "/api/v1/discounts/3",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:               "Delete rule already deleted returns a 404",
+			method:             http.MethodDelete,
+			path:               "/api/v1/discounts/3",
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   "testdata/discounts/error_not_found_response.json",
+		},
+		{
+			name:               "Deleted rule no longer applies to prices",
+			method:             http.MethodGet,
+			path:               "/api/v1/products/000004",
+			expectedStatusCode: http.StatusOK,
+			expectedContains:   `"final":79500`,
+		},
+		{
+			name:               "Simulate rules returns how final prices change",
+			method:             http.MethodPost,
+			path:               "/api/v1/discount-simulations",
+			body:               `{"rules": [{"kind": "category", "key": "boots", "percentage": 0.2}, {"kind": "category", "key": "sneakers", "percentage": 0.5}]}`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   "testdata/discounts/successful_simulation_response.json",
+		},
+		{
+			name:               "Simulated rules are not stored",
+			method:             http.MethodGet,
+			path:               "/api/v1/discounts",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   "testdata/discounts/successful_list_response.json",
+		},
+		{
+			name:               "Simulate rules sharing a kind and key returns a 400",
+			method:             http.MethodPost,
+			path:               "/api/v1/discount-simulations",
+			body:               `{"rules": [{"kind": "sku", "key": "000001", "percentage": 0.1}, {"kind": "sku", "key": "000001", "percentage": 0}]}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   "testdata/discounts/error_invalid_simulation_response.json",
+		},
+		{
+			name:               "Create rule targeting a channel returns a 201",
+			method:             http.MethodPost,
+			path:               "/api/v1/discounts",
+			body:               `{"kind": "category", "key": "sandals", "percentage": 0.25, "channel": "Wholesale"}`,
+			expectedStatusCode: http.StatusCreated,
+			expectedContains:   `"id":4,"kind":"category","key":"sandals","percentage":0.25,"channel":"wholesale"`,
+		},
+		{
+			name:               "Create rule for the same kind and key without target returns a 201",
+			method:             http.MethodPost,
+			path:               "/api/v1/discounts",
+			body:               `{"kind": "category", "key": "sandals", "percentage": 0.1}`,
+			expectedStatusCode: http.StatusCreated,
+			expectedContains:   `"id":5`,
+		},
+		{
+			name:               "Targeted rule applies to prices for its channel",
+			method:             http.MethodGet,
+			path:               "/api/v1/products/000004",
+			headers:            map[string]string{ChannelHeader: "wholesale"},
+			expectedStatusCode: http.StatusOK,
+			expectedContains:   `"final":59625`,
+		},
+		{
+			name:               "Targeted rule does not apply to prices for other channels",
+			method:             http.MethodGet,
+			path:               "/api/v1/products/000004",
+			headers:            map[string]string{ChannelHeader: "web"},
+			expectedStatusCode: http.StatusOK,
+			expectedContains:   `"final":71550`,
+		},
+		{
+			name:               "Get product for an unknown channel returns a 400",
+			method:             http.MethodGet,
+			path:               "/api/v1/products/000004",
+			headers:            map[string]string{ChannelHeader: "store"},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedContains:   "INVALID_CHANNEL",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			assertions.NoError(err)
+			for header, value := range tt.headers {
+				request.Header.Set(header, value)
+			}
+
+			router.ServeHTTP(recorder, request)
+
+			assertions.Equal(tt.expectedStatusCode, recorder.Code)
+			if tt.expectedContains != "" {
+				assertions.Contains(recorder.Body.String(), tt.expectedContains)
+			}
+
+			if tt.expectedResponse != "" {
+				expectedResponse, err := discountsContent.ReadFile(tt.expectedResponse)
+				assertions.NoError(err)
+				assertions.JSONEq(string(expectedResponse), recorder.Body.String())
+			}
+		})
+	}
+}
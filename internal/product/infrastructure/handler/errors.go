@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"go-products.com/m/internal/product/domain"
+	domainErrors "go-products.com/m/internal/product/domain/errors"
+	"go-products.com/m/internal/shared/api"
+	"go-products.com/m/internal/shared/logging"
+)
+
+func isValidationError(err error) bool {
+	var emptyStringErr domainErrors.ErrEmptyString
+	var unknownCategoryErr domainErrors.ErrUnknownCategory
+
+	return errors.As(err, &emptyStringErr) ||
+		errors.As(err, &unknownCategoryErr) ||
+		errors.Is(err, domainErrors.InvalidSlug) ||
+		errors.Is(err, domainErrors.CategoryOwnParent) ||
+		errors.Is(err, domainErrors.InvalidStatus) ||
+		errors.Is(err, domainErrors.InvalidPrice) ||
+		errors.Is(err, domainErrors.InvalidDiscountKind) ||
+		errors.Is(err, domainErrors.InvalidDiscountPercentage) ||
+		errors.Is(err, domainErrors.DuplicatedDiscountRule) ||
+		errors.Is(err, domainErrors.InvalidChannel) ||
+		errors.Is(err, domainErrors.InvalidSegment) ||
+		errors.Is(err, domainErrors.InvalidCountry)
+}
+
+// invalidRequest answers a validation error, the invalid fields are listed when the domain reported them
+func invalidRequest(writer http.ResponseWriter, err error) {
+	api.InvalidFields(writer, err.Error(), fieldErrors(err))
+}
+
+// fieldErrors the invalid fields of a domain validation error or of unknown fields asked for in the query, nil for
+// any other error
+func fieldErrors(err error) []api.FieldError {
+	var unknownFieldsErr api.ErrUnknownFields
+	if errors.As(err, &unknownFieldsErr) {
+		return unknownFieldsErr.FieldErrors()
+	}
+
+	var validationErr domainErrors.ErrValidation
+	if !errors.As(err, &validationErr) {
+		return nil
+	}
+
+	fields := make([]api.FieldError, 0, len(validationErr.Fields()))
+	for _, field := range validationErr.Fields() {
+		fields = append(fields, api.FieldError{Field: field.Field(), Code: field.Code(), Message: field.Error()})
+	}
+
+	return fields
+}
+
+// writeProductUpdate answers a product write, on success the new version is returned as ETag
+func writeProductUpdate(writer http.ResponseWriter, request *http.Request, product *domain.Product, err error) {
+	if err != nil {
+		writeError(writer, request, err)
+
+		return
+	}
+
+	setProductETag(writer, product)
+	api.NoContent(writer)
+}
+
+// writeError classifies err, only outcomes expected by the domain are explained to the client. Anything else is
+// answered with a generic message and its whole cause is logged with the request scoped logger
+func writeError(writer http.ResponseWriter, request *http.Request, err error) {
+	var invalidTransitionErr domainErrors.ErrInvalidStatusTransition
+	logger := logging.FromContext(request.Context()).With(slog.String("path", request.URL.Path), slog.Any("error", err))
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		logger.Info("request cancelled by the client")
+		api.ClientClosedRequest(writer, "request cancelled")
+	case errors.Is(err, context.DeadlineExceeded):
+		logger.Warn("request timed out")
+		api.GatewayTimeout(writer, "request timed out")
+	case errors.Is(err, domainErrors.ProductNotFound), errors.Is(err, domainErrors.DiscountRuleNotFound):
+		api.NotFound(writer, err.Error())
+	case errors.Is(err, domainErrors.VersionMismatch):
+		api.PreconditionFailed(writer, err.Error())
+	case errors.Is(err, domainErrors.CategoryAlreadyExists), errors.Is(err, domainErrors.DiscountRuleAlreadyExists),
+		errors.Is(err, domainErrors.MixedCurrencies), errors.As(err, &invalidTransitionErr):
+		api.Conflict(writer, err.Error())
+	case isValidationError(err):
+		invalidRequest(writer, err)
+	default:
+		logger.Error("request failed")
+		api.InternalServerError(writer, "internal server error")
+	}
+}
+
+// getExpectedVersion product writes must carry the ETag they were based on, or * to apply to any version, otherwise an
+// error response is written
+func getExpectedVersion(writer http.ResponseWriter, request *http.Request) (int, bool) {
+	ifMatch, err := api.GetIfMatch(request)
+	if errors.Is(err, api.ErrMissingIfMatch) {
+		api.PreconditionRequired(writer, err.Error())
+
+		return 0, false
+	}
+
+	if err != nil {
+		api.PreconditionFailed(writer, err.Error())
+
+		return 0, false
+	}
+
+	if ifMatch == api.AnyETag {
+		return domain.AnyVersion, true
+	}
+
+	// versions start at 1, lower ones are never current
+	version, err := strconv.Atoi(ifMatch)
+	if err != nil || version < 1 {
+		api.PreconditionFailed(writer, domainErrors.VersionMismatch.Error())
+
+		return 0, false
+	}
+
+	return version, true
+}
+
+func setProductETag(writer http.ResponseWriter, product *domain.Product) {
+	api.SetETag(writer, strconv.Itoa(product.Version))
+}
@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/product/infrastructure/handler/response"
+	"go-products.com/m/internal/product/use_cases"
+	"go-products.com/m/internal/shared/api"
+)
+
+func HandleGetCategories(categoriesRepository domain.CategoryRepository) http.HandlerFunc {
+	getCategoriesUseCase := use_cases.NewGetCategoriesUseCase(categoriesRepository)
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		categories, err := getCategoriesUseCase.Execute(request.Context())
+		if err != nil {
+			writeError(writer, request, err)
+
+			return
+		}
+
+		api.Success(writer, response.FromDomainCategories(categories))
+	}
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"context"
+	"embed"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go-products.com/m/internal/product/domain"
+	domainErrors "go-products.com/m/internal/product/domain/errors"
+)
+
+//go:embed testdata/categories/*.json
+var categoriesContent embed.FS
+
+func TestHandleGetCategories(t *testing.T) {
+	assertions := require.New(t)
+
+	repository := &domain.CategoryRepositoryMock{
+		GetCategoriesFunc: func(ctx context.Context) ([]domain.Category, error) {
+			return []domain.Category{
+				{Slug: "boots", Name: "Boots", ParentSlug: ptr("footwear")},
+				{Slug: "footwear", Name: "Footwear"},
+				{Slug: "sandals", Name: "Sandals", ParentSlug: ptr("footwear")},
+			}, nil
+		},
+	}
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/api/v1/categories", nil)
+	assertions.NoError(err)
+
+	HandleGetCategories(repository)(recorder, request)
+
+	expectedResponse, err := categoriesContent.ReadFile("testdata/categories/successful_tree_response.json")
+	assertions.NoError(err)
+
+	assertions.Equal(http.StatusOK, recorder.Code)
+	assertions.JSONEq(string(expectedResponse), recorder.Body.String())
+}
+
+func TestHandleCreateCategory(t *testing.T) {
+	assertions := require.New(t)
+
+	tests := []struct {
+		name                 string
+		body                 string
+		categoriesRepository domain.CategoryRepository
+		expectedStatusCode   int
+		expectedResponse     string
+	}{
+		{
+			name: "Create category successfully returns a 201",
+			body: `{"slug": "boots", "name": "Boots", "parent": "footwear"}`,
+			categoriesRepository: &domain.CategoryRepositoryMock{
+				CreateCategoryFunc: func(ctx context.Context, category domain.CreateCategoryDTO) error {
+					return nil
+				},
+			},
+			expectedStatusCode: http.StatusCreated,
+			expectedResponse:   "testdata/categories/successful_create_response.json",
+		},
+		{
+			name: "Create category with unknown parent returns a 400",
+			body: `{"slug": "boots", "name": "Boots", "parent": "footwear"}`,
+			categoriesRepository: &domain.CategoryRepositoryMock{
+				CreateCategoryFunc: func(ctx context.Context, category domain.CreateCategoryDTO) error {
+					return domainErrors.NewInvalidField("parent", domainErrors.NewUnknownCategory("footwear"))
+				},
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   "testdata/categories/error_unknown_parent_response.json",
+		},
+		{
+			name: "Create category already existing returns a 409",
+			body: `{"slug": "boots", "name": "Boots", "parent": "footwear"}`,
+			categoriesRepository: &domain.CategoryRepositoryMock{
+				CreateCategoryFunc: func(ctx context.Context, category domain.CreateCategoryDTO) error {
+					return domainErrors.CategoryAlreadyExists
+				},
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedResponse:   "testdata/categories/error_conflict_response.json",
+		},
+		{
+			name:                 "Create category with invalid slug returns a 400",
+			body:                 `{"slug": "Boots!", "name": "Boots"}`,
+			categoriesRepository: &domain.CategoryRepositoryMock{},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponse:     "testdata/categories/error_invalid_slug_response.json",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, "/api/v1/categories", strings.NewReader(tt.body))
+			assertions.NoError(err)
+
+			HandleCreateCategory(tt.categoriesRepository)(recorder, request)
+
+			expectedResponse, err := categoriesContent.ReadFile(tt.expectedResponse)
+			assertions.NoError(err)
+
+			assertions.Equal(tt.expectedStatusCode, recorder.Code)
+			assertions.JSONEq(string(expectedResponse), recorder.Body.String())
+		})
+	}
+}
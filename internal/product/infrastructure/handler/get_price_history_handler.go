@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/product/infrastructure/handler/response"
+	"go-products.com/m/internal/product/use_cases"
+	"go-products.com/m/internal/shared/api"
+)
+
+func HandleGetPriceHistory(priceHistoryRepository domain.PriceHistoryRepository) http.HandlerFunc {
+	getPriceHistoryUseCase := use_cases.NewGetPriceHistoryUseCase(priceHistoryRepository)
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		priceHistory, err := getPriceHistoryUseCase.Execute(request.Context(), api.GetPathParam(request, "sku"))
+		if err != nil {
+			writeError(writer, request, err)
+
+			return
+		}
+
+		api.Success(writer, response.FromPriceHistory(priceHistory))
+	}
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-products.com/m/internal/product/domain"
+	domainErrors "go-products.com/m/internal/product/domain/errors"
+	"go-products.com/m/internal/product/infrastructure/handler/response"
+	"go-products.com/m/internal/product/use_cases"
+	"go-products.com/m/internal/shared/api"
+)
+
+// HandleGetProduct public detail, archived products are not found. The product can be trimmed with fields and
+// expanded with include
+func HandleGetProduct(productsRepository domain.ProductRepository, priceHistoryRepository domain.PriceHistoryRepository, categoriesRepository domain.CategoryRepository, options CatalogOptions) http.HandlerFunc {
+	return handleGetProduct(productsRepository, priceHistoryRepository, categoriesRepository, options, false)
+}
+
+// HandleAdminGetProduct back office detail, archived products are returned so they can be restored
+func HandleAdminGetProduct(productsRepository domain.ProductRepository, priceHistoryRepository domain.PriceHistoryRepository, categoriesRepository domain.CategoryRepository, options CatalogOptions) http.HandlerFunc {
+	return handleGetProduct(productsRepository, priceHistoryRepository, categoriesRepository, options, true)
+}
+
+func handleGetProduct(productsRepository domain.ProductRepository, priceHistoryRepository domain.PriceHistoryRepository, categoriesRepository domain.CategoryRepository, options CatalogOptions, includeArchived bool) http.HandlerFunc {
+	getProductUseCase := use_cases.NewGetProductUseCase(productsRepository, options.discountPolicies())
+	getLowestPricesUseCase := use_cases.NewGetLowestPricesUseCase(priceHistoryRepository)
+	getCategoriesUseCase := use_cases.NewGetCategoriesUseCase(categoriesRepository)
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ctx := request.Context()
+		shape, err := getProductShape(request)
+		if err != nil {
+			invalidRequest(writer, err)
+
+			return
+		}
+
+		product, err := getProductUseCase.Execute(ctx, api.GetPathParam(request, "sku"), pricingContextFrom(ctx))
+		if err == nil && product.IsArchived() && !includeArchived {
+			err = domainErrors.ProductNotFound
+		}
+
+		if err != nil {
+			writeError(writer, request, err)
+
+			return
+		}
+
+		lowestPrices, err := getLowestPricesUseCase.Execute(ctx, []string{product.Sku})
+		if err != nil {
+			writeError(writer, request, err)
+
+			return
+		}
+
+		products := []domain.PricedProduct{*product}
+		options.Metrics.recordDiscounts(products)
+		productsResponse := shape.expandDiscounts(response.FromDomainProducts(products, lowestPrices), products)
+		productsResponse, err = shape.expandCategories(ctx, getCategoriesUseCase, productsResponse)
+		if err != nil {
+			writeError(writer, request, err)
+
+			return
+		}
+
+		setProductETag(writer, &product.Product)
+		api.Success(writer, shape.fields.Apply(productsResponse[0]))
+	}
+}
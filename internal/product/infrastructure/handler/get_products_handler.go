@@ -6,32 +6,91 @@ import (
 	"strconv"
 
 	"go-products.com/m/internal/product/domain"
+	domainErrors "go-products.com/m/internal/product/domain/errors"
 	"go-products.com/m/internal/product/infrastructure/handler/response"
 	"go-products.com/m/internal/product/use_cases"
 	"go-products.com/m/internal/shared/api"
+	"go-products.com/m/internal/shared/tracing"
 )
 
-func HandleGetProducts(productsRepository domain.ProductRepository) http.HandlerFunc {
-	getProductsUseCase := use_cases.NewGetProductsUseCase(productsRepository)
+// HandleGetProducts public listing, only active products are shown. Products can be trimmed with fields and expanded
+// with include
+func HandleGetProducts(productsRepository domain.ProductRepository, priceHistoryRepository domain.PriceHistoryRepository, categoriesRepository domain.CategoryRepository, options CatalogOptions) http.HandlerFunc {
+	return handleGetProducts(productsRepository, priceHistoryRepository, categoriesRepository, options, getActiveProductsFilters)
+}
+
+// HandleAdminGetProducts back office listing, products in every status are shown unless the status filter is given
+func HandleAdminGetProducts(productsRepository domain.ProductRepository, priceHistoryRepository domain.PriceHistoryRepository, categoriesRepository domain.CategoryRepository, options CatalogOptions) http.HandlerFunc {
+	return handleGetProducts(productsRepository, priceHistoryRepository, categoriesRepository, options, getAdminProductsFilters)
+}
+
+func handleGetProducts(
+	productsRepository domain.ProductRepository,
+	priceHistoryRepository domain.PriceHistoryRepository,
+	categoriesRepository domain.CategoryRepository,
+	options CatalogOptions,
+	getFilters func(request *http.Request) (domain.ProductsFilters, error),
+) http.HandlerFunc {
+	getProductsUseCase := use_cases.NewGetProductsUseCase(productsRepository, options.discountPolicies())
+	getLowestPricesUseCase := use_cases.NewGetLowestPricesUseCase(priceHistoryRepository)
+	getCategoriesUseCase := use_cases.NewGetCategoriesUseCase(categoriesRepository)
 
 	return func(writer http.ResponseWriter, request *http.Request) {
-		filters, err := getProductsFilters(request)
+		ctx := request.Context()
+		_, span := tracing.Start(ctx, "handler.parseFilters")
+		filters, err := getFilters(request)
+		span.End()
 		if err != nil {
-			api.InvalidRequest(writer, err.Error())
+			invalidRequest(writer, err)
 
 			return
 		}
 
-		ctx := request.Context()
-		products, err := getProductsUseCase.Execute(ctx, filters)
+		shape, err := getProductShape(request)
+		if err != nil {
+			invalidRequest(writer, err)
+
+			return
+		}
+
+		limit := options.limit()
+		filters.Limit = &limit
+
+		products, err := getProductsUseCase.Execute(ctx, filters, pricingContextFrom(ctx))
+		if err != nil {
+			writeError(writer, request, err)
+
+			return
+		}
+
+		lowestPrices, err := getLowestPricesUseCase.Execute(ctx, productSkus(products))
+		if err != nil {
+			writeError(writer, request, err)
+
+			return
+		}
+
+		options.Metrics.recordDiscounts(products)
+		productsResponse := shape.expandDiscounts(response.FromDomainProducts(products, lowestPrices), products)
+
+		productsResponse, err = shape.expandCategories(ctx, getCategoriesUseCase, productsResponse)
 		if err != nil {
-			api.InternalServerError(writer, err.Error())
+			writeError(writer, request, err)
 
 			return
 		}
 
-		api.Success(writer, response.FromDomainProducts(products))
+		api.Success(writer, shape.fields.Apply(productsResponse))
+	}
+}
+
+func productSkus(products []domain.PricedProduct) []string {
+	skus := make([]string, 0, len(products))
+	for _, product := range products {
+		skus = append(skus, product.Sku)
 	}
+
+	return skus
 }
 
 func getProductsFilters(request *http.Request) (domain.ProductsFilters, error) {
@@ -40,7 +99,7 @@ func getProductsFilters(request *http.Request) (domain.ProductsFilters, error) {
 
 	priceFilter, err := strconv.Atoi(priceLessThan)
 	if priceLessThan != "" && err != nil {
-		return domain.ProductsFilters{}, errors.New("price_less_than must be a number")
+		return domain.ProductsFilters{}, domainErrors.NewInvalidField("price_less_than", errors.New("price_less_than must be a number"))
 	}
 
 	categoryFilter := &category
@@ -48,12 +107,9 @@ func getProductsFilters(request *http.Request) (domain.ProductsFilters, error) {
 		categoryFilter = nil
 	}
 
-	limit := 5
-
 	productFilters := domain.ProductsFilters{
 		Category:      categoryFilter,
 		PriceLessThan: &priceFilter,
-		Limit:         &limit,
 	}
 
 	if priceLessThan == "" {
@@ -62,3 +118,36 @@ func getProductsFilters(request *http.Request) (domain.ProductsFilters, error) {
 
 	return productFilters, nil
 }
+
+func getActiveProductsFilters(request *http.Request) (domain.ProductsFilters, error) {
+	productFilters, err := getProductsFilters(request)
+	if err != nil {
+		return domain.ProductsFilters{}, err
+	}
+
+	activeStatus := domain.ProductStatusActive
+	productFilters.Status = &activeStatus
+
+	return productFilters, nil
+}
+
+func getAdminProductsFilters(request *http.Request) (domain.ProductsFilters, error) {
+	productFilters, err := getProductsFilters(request)
+	if err != nil {
+		return domain.ProductsFilters{}, err
+	}
+
+	status := api.GetQueryParam(request, "status")
+	if status == "" {
+		return productFilters, nil
+	}
+
+	statusFilter, err := domain.ParseProductStatus(status)
+	if err != nil {
+		return domain.ProductsFilters{}, domainErrors.NewInvalidField("status", err)
+	}
+
+	productFilters.Status = &statusFilter
+
+	return productFilters, nil
+}
@@ -1,20 +1,26 @@
 package handler
 
 import (
+	"bytes"
 	"context"
 	"embed"
 	_ "embed"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"path"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"go-products.com/m/internal/product/domain"
 	"go-products.com/m/internal/product/infrastructure/persistance"
 	"go-products.com/m/internal/product/infrastructure/persistance/migrations"
+	"go-products.com/m/internal/shared/api"
 	sharedDatabaseUtils "go-products.com/m/internal/shared/database"
+	"go-products.com/m/internal/shared/logging"
 )
 
 //go:embed testdata/*.json
@@ -27,11 +33,12 @@ func TestHandleGetProducts(t *testing.T) {
 	assertions := require.New(t)
 
 	tests := []struct {
-		name               string
-		productsRepository domain.ProductRepository
-		expectedStatusCode int
-		expectedResponse   string
-		priceLessThan      *string
+		name                   string
+		productsRepository     domain.ProductRepository
+		priceHistoryRepository domain.PriceHistoryRepository
+		expectedStatusCode     int
+		expectedResponse       string
+		priceLessThan          *string
 	}{
 		{
 			name: "Get products successfully returns a 200",
@@ -44,6 +51,7 @@ func TestHandleGetProducts(t *testing.T) {
 							Category: "sandals",
 							Price:    100,
 							Currency: domain.EUR,
+							Status:   domain.ProductStatusActive,
 						},
 						{
 							Sku:      "0002",
@@ -51,6 +59,7 @@ func TestHandleGetProducts(t *testing.T) {
 							Category: "boots",
 							Price:    100,
 							Currency: domain.EUR,
+							Status:   domain.ProductStatusActive,
 						},
 						{
 							Sku:      "000003",
@@ -58,10 +67,16 @@ func TestHandleGetProducts(t *testing.T) {
 							Category: "sandals",
 							Price:    100,
 							Currency: domain.EUR,
+							Status:   domain.ProductStatusActive,
 						},
 					}, nil
 				},
 			},
+			priceHistoryRepository: &domain.PriceHistoryRepositoryMock{
+				GetLowestPricesFunc: func(ctx context.Context, skus []string, since time.Time) (map[string]int, error) {
+					return map[string]int{"0002": 90}, nil
+				},
+			},
 			expectedStatusCode: http.StatusOK,
 			expectedResponse:   "testdata/successful_response.json",
 		},
@@ -72,15 +87,17 @@ func TestHandleGetProducts(t *testing.T) {
 					return nil, persistance.ErrGetProducts
 				},
 			},
-			expectedStatusCode: http.StatusInternalServerError,
-			expectedResponse:   "testdata/error_response.json",
+			priceHistoryRepository: &domain.PriceHistoryRepositoryMock{},
+			expectedStatusCode:     http.StatusInternalServerError,
+			expectedResponse:       "testdata/error_response.json",
 		},
 		{
-			name:               "Get products with bad price returns a 400",
-			productsRepository: &domain.ProductRepositoryMock{},
-			expectedStatusCode: http.StatusBadRequest,
-			expectedResponse:   "testdata/error_invalid_response.json",
-			priceLessThan:      ptr("not_a_number"),
+			name:                   "Get products with bad price returns a 400",
+			productsRepository:     &domain.ProductRepositoryMock{},
+			priceHistoryRepository: &domain.PriceHistoryRepositoryMock{},
+			expectedStatusCode:     http.StatusBadRequest,
+			expectedResponse:       "testdata/error_invalid_response.json",
+			priceLessThan:          ptr("not_a_number"),
 		},
 	}
 	for _, tt := range tests {
@@ -94,7 +111,7 @@ func TestHandleGetProducts(t *testing.T) {
 			request, err := http.NewRequest(http.MethodGet, path, nil)
 			assertions.NoError(err)
 
-			handler := HandleGetProducts(tt.productsRepository)
+			handler := HandleGetProducts(tt.productsRepository, tt.priceHistoryRepository, &domain.CategoryRepositoryMock{}, DefaultCatalogOptions())
 
 			handler(recorder, request)
 
@@ -160,6 +177,17 @@ func TestIntegration_HandleGetProducts(t *testing.T) {
 			category:      ptr("boots"),
 			priceLessThan: ptr("75000"),
 		},
+		{
+			name: "Get products with parent category filter includes descendant categories",
+			assertions: func(writer *httptest.ResponseRecorder) {
+				productsContent, err := productsContentIntegration.ReadFile("testdata/integration_test/successful_all_response.json")
+				assertions.NoError(err)
+
+				assertions.JSONEq(string(productsContent), writer.Body.String())
+				assertions.Equal(http.StatusOK, writer.Code)
+			},
+			category: ptr("footwear"),
+		},
 	}
 
 	database, err := sharedDatabaseUtils.GenerateDatabaseConnection(sharedDatabaseUtils.DatabaseConnection{
@@ -167,6 +195,9 @@ func TestIntegration_HandleGetProducts(t *testing.T) {
 	}, migrations.CreateProductsDatabase)
 	assertions.NoError(err)
 
+	err = migrations.InitCategories(context.Background(), persistance.NewCategoriesSQLiteRepository(database), path.Join(".", "testdata", "categories.json"))
+	assertions.NoError(err)
+
 	repository := persistance.NewProductsSQLiteRepository(database)
 	err = migrations.InitProducts(context.Background(), repository, path.Join(".", "testdata", "products.json"))
 	assertions.NoError(err)
@@ -190,7 +221,7 @@ func TestIntegration_HandleGetProducts(t *testing.T) {
 			request, err := http.NewRequest(http.MethodGet, path, nil)
 			assertions.NoError(err)
 
-			handler := HandleGetProducts(repository)
+			handler := HandleGetProducts(repository, persistance.NewPriceHistorySQLiteRepository(database), persistance.NewCategoriesSQLiteRepository(database), DefaultCatalogOptions())
 
 			handler(recorder, request)
 
@@ -199,6 +230,185 @@ func TestIntegration_HandleGetProducts(t *testing.T) {
 	}
 }
 
+func TestIntegration_HandleGetProducts_Errors(t *testing.T) {
+	assertions := require.New(t)
+
+	database, err := sharedDatabaseUtils.GenerateDatabaseConnection(sharedDatabaseUtils.DatabaseConnection{
+		DatabaseName: filepath.Join(t.TempDir(), "products.db"),
+	}, migrations.CreateProductsDatabase)
+	assertions.NoError(err)
+	t.Cleanup(func() { _ = database.Close() })
+
+	err = migrations.InitCategories(context.Background(), persistance.NewCategoriesSQLiteRepository(database), path.Join(".", "testdata", "categories.json"))
+	assertions.NoError(err)
+
+	repository := persistance.NewProductsSQLiteRepository(database)
+	err = migrations.InitProducts(context.Background(), repository, path.Join(".", "testdata", "products.json"))
+	assertions.NoError(err)
+
+	handler := HandleGetProducts(repository, persistance.NewPriceHistorySQLiteRepository(database), persistance.NewCategoriesSQLiteRepository(database), DefaultCatalogOptions())
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name               string
+		ctx                context.Context
+		corrupt            bool
+		expectedStatusCode int
+		expectedResponse   string
+		expectedLog        string
+	}{
+		{
+			name:               "Cancelled requests return a 499",
+			ctx:                cancelled,
+			expectedStatusCode: api.StatusClientClosedRequest,
+			expectedResponse:   `{"message": "request cancelled", "app_code": "REQUEST_CANCELLED"}`,
+			expectedLog:        `level=INFO msg="request cancelled by the client" path=/api/v1/products error="error getting products: context canceled"`,
+		},
+		{
+			name:               "Timed out requests return a 504",
+			ctx:                expired,
+			expectedStatusCode: http.StatusGatewayTimeout,
+			expectedResponse:   `{"message": "request timed out", "app_code": "TIMEOUT"}`,
+			expectedLog:        `level=WARN msg="request timed out" path=/api/v1/products error="error getting products: context deadline exceeded"`,
+		},
+		{
+			name:               "Corrupted products return a 500 without details",
+			ctx:                context.Background(),
+			corrupt:            true,
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse:   `{"message": "internal server error", "app_code": "INTERNAL_SERVER_ERROR"}`,
+			expectedLog:        `level=ERROR msg="request failed" path=/api/v1/products error="error parsing product: product 000001: name cannot be empty"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.corrupt {
+				_, err := database.Exec("UPDATE products SET name = '' WHERE sku = '000001';")
+				assertions.NoError(err)
+			}
+
+			var logs bytes.Buffer
+			ctx := logging.WithLogger(tt.ctx, slog.New(slog.NewTextHandler(&logs, nil)))
+			request, err := http.NewRequestWithContext(ctx, http.MethodGet, "/api/v1/products", nil)
+			assertions.NoError(err)
+
+			recorder := httptest.NewRecorder()
+			handler(recorder, request)
+
+			assertions.Equal(tt.expectedStatusCode, recorder.Code)
+			assertions.JSONEq(tt.expectedResponse, recorder.Body.String())
+			assertions.Contains(logs.String(), tt.expectedLog)
+		})
+	}
+}
+
+func TestIntegration_HandleGetProducts_Shaping(t *testing.T) {
+	assertions := require.New(t)
+
+	database, err := sharedDatabaseUtils.GenerateDatabaseConnection(sharedDatabaseUtils.DatabaseConnection{
+		DatabaseName: filepath.Join(t.TempDir(), "products.db"),
+	}, migrations.CreateProductsDatabase)
+	assertions.NoError(err)
+	t.Cleanup(func() { _ = database.Close() })
+
+	categoriesRepository := persistance.NewCategoriesSQLiteRepository(database)
+	err = migrations.InitCategories(context.Background(), categoriesRepository, path.Join(".", "testdata", "categories.json"))
+	assertions.NoError(err)
+
+	repository := persistance.NewProductsSQLiteRepository(database)
+	err = migrations.InitProducts(context.Background(), repository, path.Join(".", "testdata", "products.json"))
+	assertions.NoError(err)
+
+	router := api.NewRouter(api.Negotiate(api.DefaultEncoders...))
+	router.HandleFunc(http.MethodGet, "/api/v1/products", HandleGetProducts(repository, persistance.NewPriceHistorySQLiteRepository(database), categoriesRepository, DefaultCatalogOptions()))
+	router.HandleFunc(http.MethodGet, "/api/v1/products/{sku}", HandleGetProduct(repository, persistance.NewPriceHistorySQLiteRepository(database), categoriesRepository, DefaultCatalogOptions()))
+
+	tests := []struct {
+		name               string
+		path               string
+		accept             string
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name:               "Fields trim the products",
+			path:               "/api/v1/products?category=sandals&fields=sku,price.final",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"content": [{"sku": "000004", "price": {"final": 79500}}]}`,
+		},
+		{
+			name:               "Include expands the category",
+			path:               "/api/v1/products?category=sandals&include=category_details&fields=sku,category_details",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"content": [{"sku": "000004", "category_details": {"slug": "sandals", "name": "Sandals", "parent": "footwear", "path": ["footwear", "sandals"]}}]}`,
+		},
+		{
+			name:               "Expansions are not rendered unless included",
+			path:               "/api/v1/products/000004?fields=sku,category_details",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"content": {"sku": "000004"}}`,
+		},
+		{
+			name:               "Include explains the discounts",
+			path:               "/api/v1/products/000003?include=discount_breakdown&fields=sku,price.final,price.discount_breakdown",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `{"content": {"sku": "000003", "price": {"final": 49700, "discount_breakdown": [
+				{"rule": "category", "key": "boots", "percentage": "30%", "amount": 21300, "outcome": "applied"},
+				{"rule": "sku", "key": "000003", "percentage": "15%", "amount": 10650, "outcome": "dropped"}
+			]}}}`,
+		},
+		{
+			name:               "Products matching no discount have no breakdown",
+			path:               "/api/v1/products?category=sandals&include=discount_breakdown,category_details&fields=sku,price.discount_breakdown,category_details.name",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"content": [{"sku": "000004", "price": {}, "category_details": {"name": "Sandals"}}]}`,
+		},
+		{
+			name:               "Trimmed products are rendered as CSV",
+			path:               "/api/v1/products?category=sandals&fields=sku,price.original,price.final",
+			accept:             "text/csv",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   "sku,price.original,price.final\n000004,79500,79500\n",
+		},
+		{
+			name:               "Unknown fields return a 400",
+			path:               "/api/v1/products?fields=sku,price.cost,stock",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: `{"message": "fields: unknown field \"price.cost\"; fields: unknown field \"stock\"", "app_code": "INVALID_REQUEST", "errors": [
+				{"field": "fields", "code": "UNKNOWN_FIELD", "message": "fields: unknown field \"price.cost\""},
+				{"field": "fields", "code": "UNKNOWN_FIELD", "message": "fields: unknown field \"stock\""}
+			]}`,
+		},
+		{
+			name:               "Unknown expansions return a 400",
+			path:               "/api/v1/products/000004?include=reviews",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   `{"message": "include: unknown field \"reviews\"", "app_code": "INVALID_REQUEST", "errors": [{"field": "include", "code": "UNKNOWN_FIELD", "message": "include: unknown field \"reviews\""}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			request.Header.Set("Accept", tt.accept)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			assertions.Equal(tt.expectedStatusCode, recorder.Code)
+			if tt.accept == "text/csv" {
+				assertions.Equal(tt.expectedResponse, recorder.Body.String())
+
+				return
+			}
+
+			assertions.JSONEq(tt.expectedResponse, recorder.Body.String())
+		})
+	}
+}
+
 func ptr[T any](v T) *T {
 	return &v
 }
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/product/infrastructure/persistance"
+	"go-products.com/m/internal/shared/api"
+	"go-products.com/m/internal/shared/metrics"
+	"go-products.com/m/internal/shared/tracing"
+)
+
+func TestHandleGetProducts_Tracing(t *testing.T) {
+	assertions := require.New(t)
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewTracerProvider(exporter, tracing.Options{ServiceName: "test", SampleRatio: 1})
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	queryMetrics := persistance.NewQueryMetrics(metrics.NewRegistry())
+	productsRepository := persistance.NewInstrumentedProductsRepository(&domain.ProductRepositoryMock{
+		GetProductsFunc: func(ctx context.Context, filters domain.ProductsFilters) ([]domain.Product, error) {
+			return []domain.Product{{Sku: "000001", Name: "Boot", Category: "boots", Price: 100, Status: domain.ProductStatusActive}}, nil
+		},
+	}, queryMetrics)
+	priceHistoryRepository := persistance.NewInstrumentedPriceHistoryRepository(&domain.PriceHistoryRepositoryMock{
+		GetLowestPricesFunc: func(ctx context.Context, skus []string, since time.Time) (map[string]int, error) {
+			return map[string]int{}, nil
+		},
+	}, queryMetrics)
+
+	router := api.NewRouter(api.Tracing())
+	router.HandleFunc(http.MethodGet, "/api/v1/products", HandleGetProducts(productsRepository, priceHistoryRepository, &domain.CategoryRepositoryMock{}, DefaultCatalogOptions()))
+
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/products", nil)
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	assertions.Equal(http.StatusOK, recorder.Code)
+	assertions.NoError(provider.ForceFlush(context.Background()))
+
+	spans := make(map[string]tracetest.SpanStub)
+	for _, span := range exporter.GetSpans() {
+		assertions.Equal("4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID().String())
+		spans[span.Name] = span
+	}
+
+	server := spans["GET /api/v1/products"]
+	assertions.Equal("00f067aa0ba902b7", server.Parent.SpanID().String())
+
+	useCase := spans["GetProductsUseCase.Execute"]
+	assertions.Equal(server.SpanContext.SpanID(), useCase.Parent.SpanID())
+	assertions.Equal(useCase.SpanContext.SpanID(), spans["products.get_products"].Parent.SpanID())
+	assertions.Equal(server.SpanContext.SpanID(), spans["handler.parseFilters"].Parent.SpanID())
+	assertions.Equal(useCase.SpanContext.SpanID(), spans["discounts.apply"].Parent.SpanID())
+	assertions.Equal(spans["GetLowestPricesUseCase.Execute"].SpanContext.SpanID(), spans["price_history.get_lowest_prices"].Parent.SpanID())
+}
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/shared/metrics"
+)
+
+// CatalogMetrics what the catalog handlers serve, a registry must only have one
+type CatalogMetrics struct {
+	discountsApplied *metrics.CounterVec
+}
+
+func NewCatalogMetrics(registry *metrics.Registry) *CatalogMetrics {
+	return &CatalogMetrics{
+		discountsApplied: registry.NewCounter("products_discounts_applied_total", "Discounts applied to served products by rule.", "rule"),
+	}
+}
+
+// recordDiscounts nothing is recorded without metrics
+func (m *CatalogMetrics) recordDiscounts(products []domain.PricedProduct) {
+	if m == nil {
+		return
+	}
+
+	for _, product := range products {
+		if rule := product.Discount.Rule; rule != "" {
+			m.discountsApplied.Inc(rule)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"sync"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/product/use_cases"
+)
+
+// CatalogOptions settings of the handlers serving products
+type CatalogOptions struct {
+	// DefaultLimit maximum number of products in a listing, defaultLimit when not positive
+	DefaultLimit int
+	// Discounts policy applied when DiscountRules is nil
+	Discounts domain.DiscountPolicy
+	// DiscountRules when set the policy is built from the stored rules, so changes apply at once
+	DiscountRules domain.DiscountRuleRepository
+	// Metrics where served discounts are counted, they are not counted when nil
+	Metrics *CatalogMetrics
+	// CatalogVersion when set with DiscountRules the policy is only built again once the catalog version changes,
+	// otherwise on every request
+	CatalogVersion domain.CatalogVersionRepository
+}
+
+const defaultLimit = 5
+
+// DefaultCatalogOptions new options on every call, their policy may be changed freely
+func DefaultCatalogOptions() CatalogOptions {
+	return CatalogOptions{
+		DefaultLimit: defaultLimit,
+		Discounts:    domain.DefaultDiscountPolicy(),
+	}
+}
+
+// limit products listed at most
+func (o CatalogOptions) limit() int {
+	if o.DefaultLimit <= 0 {
+		return defaultLimit
+	}
+
+	return o.DefaultLimit
+}
+
+// discountPolicies where prices take the policy from
+func (o CatalogOptions) discountPolicies() domain.DiscountPolicySource {
+	if o.DiscountRules == nil {
+		return o.Discounts
+	}
+
+	getDiscountPolicy := use_cases.NewGetDiscountPolicyUseCase(o.DiscountRules)
+	if o.CatalogVersion == nil {
+		return domain.DiscountPolicySourceFunc(getDiscountPolicy.Execute)
+	}
+
+	return &versionedDiscountPolicy{getDiscountPolicy: getDiscountPolicy, catalogVersion: o.CatalogVersion}
+}
+
+// versionedDiscountPolicy keeps the policy built for a catalog version, every write to discount rules changes it
+type versionedDiscountPolicy struct {
+	getDiscountPolicy use_cases.GetDiscountPolicyUseCase
+	catalogVersion    domain.CatalogVersionRepository
+
+	mutex   sync.Mutex
+	version int
+	policy  *domain.DiscountPolicy
+}
+
+// GetDiscountPolicy the version is read before the rules, so a policy is never kept for a version newer than its rules
+func (p *versionedDiscountPolicy) GetDiscountPolicy(ctx context.Context) (domain.DiscountPolicy, error) {
+	version, err := p.catalogVersion.GetCatalogVersion(ctx)
+	if err != nil {
+		return domain.DiscountPolicy{}, err
+	}
+
+	p.mutex.Lock()
+	if p.policy != nil && p.version == version {
+		policy := *p.policy
+		p.mutex.Unlock()
+
+		return policy, nil
+	}
+	p.mutex.Unlock()
+
+	policy, err := p.getDiscountPolicy.Execute(ctx)
+	if err != nil {
+		return domain.DiscountPolicy{}, err
+	}
+
+	p.mutex.Lock()
+	p.version, p.policy = version, &policy
+	p.mutex.Unlock()
+
+	return policy, nil
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/shared/metrics"
+)
+
+// countingDiscountRules counts the times the rules are read
+type countingDiscountRules struct {
+	domain.DiscountRuleRepository
+	rules []domain.DiscountRule
+	reads int
+}
+
+func (r *countingDiscountRules) GetDiscountRules(context.Context) ([]domain.DiscountRule, error) {
+	r.reads++
+
+	return r.rules, nil
+}
+
+// movingCatalogVersion a catalog version tests can change
+type movingCatalogVersion struct {
+	version int
+}
+
+func (v *movingCatalogVersion) GetCatalogVersion(context.Context) (int, error) {
+	return v.version, nil
+}
+
+func TestCatalogOptions_DiscountPolicies(t *testing.T) {
+	assertions := require.New(t)
+
+	rules := &countingDiscountRules{rules: []domain.DiscountRule{{ID: 1, Kind: domain.DiscountRuleCategory, Key: "boots", Percentage: 0.3}}}
+	version := &movingCatalogVersion{version: 1}
+	source := CatalogOptions{DiscountRules: rules, CatalogVersion: version}.discountPolicies()
+
+	for i := 0; i < 3; i++ {
+		policy, err := source.GetDiscountPolicy(context.Background())
+		assertions.NoError(err)
+		assertions.Equal(0.3, policy.Categories["boots"])
+	}
+	assertions.Equal(1, rules.reads)
+
+	rules.rules = []domain.DiscountRule{{ID: 1, Kind: domain.DiscountRuleCategory, Key: "boots", Percentage: 0.2}}
+	version.version++
+
+	policy, err := source.GetDiscountPolicy(context.Background())
+	assertions.NoError(err)
+	assertions.Equal(0.2, policy.Categories["boots"])
+	assertions.Equal(2, rules.reads)
+
+	uncached := CatalogOptions{DiscountRules: rules}.discountPolicies()
+	for i := 0; i < 2; i++ {
+		_, err := uncached.GetDiscountPolicy(context.Background())
+		assertions.NoError(err)
+	}
+	assertions.Equal(4, rules.reads)
+}
+
+func TestCatalogOptions_Limit(t *testing.T) {
+	assertions := require.New(t)
+
+	tests := []struct {
+		name         string
+		defaultLimit int
+		want         int
+	}{
+		{name: "Positive limits are kept", defaultLimit: 3, want: 3},
+		{name: "Zero means the default", defaultLimit: 0, want: defaultLimit},
+		{name: "Negative limits mean the default", defaultLimit: -1, want: defaultLimit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertions.Equal(tt.want, CatalogOptions{DefaultLimit: tt.defaultLimit}.limit())
+		})
+	}
+}
+
+func TestDefaultCatalogOptions(t *testing.T) {
+	assertions := require.New(t)
+
+	changed := DefaultCatalogOptions()
+	changed.Discounts.Categories["boots"] = 0.9
+
+	assertions.Equal(0.3, DefaultCatalogOptions().Discounts.Categories["boots"])
+}
+
+func TestCatalogMetrics(t *testing.T) {
+	assertions := require.New(t)
+
+	registry := metrics.NewRegistry()
+	catalogMetrics := NewCatalogMetrics(registry)
+	products := []domain.PricedProduct{
+		{Product: domain.Product{Sku: "000001"}, Discount: domain.Discount{Rule: "category"}},
+		{Product: domain.Product{Sku: "000003"}, Discount: domain.Discount{Rule: "sku"}},
+		{Product: domain.Product{Sku: "000004"}, Discount: domain.Discount{Rule: "category"}},
+		{Product: domain.Product{Sku: "000005"}},
+	}
+
+	catalogMetrics.recordDiscounts(products)
+	(*CatalogMetrics)(nil).recordDiscounts(products)
+
+	assertions.Equal(2.0, catalogMetrics.discountsApplied.Value("category"))
+	assertions.Equal(1.0, catalogMetrics.discountsApplied.Value("sku"))
+	assertions.Equal(0.0, catalogMetrics.discountsApplied.Value(""))
+
+	var rendered strings.Builder
+	assertions.NoError(registry.Render(&rendered))
+	assertions.Contains(rendered.String(), `products_discounts_applied_total{rule="category"} 2`)
+}
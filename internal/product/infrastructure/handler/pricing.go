@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/shared/api"
+	"go-products.com/m/internal/shared/auth"
+	"go-products.com/m/internal/shared/logging"
+)
+
+// Headers sent by front ends and partners to tell who prices are for
+const (
+	ChannelHeader = "X-Channel"
+	SegmentHeader = "X-Customer-Segment"
+	CountryHeader = "X-Country"
+)
+
+type pricingContextKey struct{}
+
+// Pricing resolves the pricing context of the request, invalid headers are answered with 400. It must run after
+// api.Authenticate and before caches, the catalog version depends on it.
+//
+// When principalAttributes is set the attributes of authenticated principals are verified, so they win over headers,
+// which any client can send. Responses then vary by Authorization even for anonymous requests, otherwise a shared
+// cache would serve the prices of an anonymous caller to a principal whose attributes set other ones
+func Pricing(principalAttributes bool) api.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			var attributes map[string]string
+			if principal, ok := auth.PrincipalFromContext(request.Context()); ok && principalAttributes {
+				attributes = principal.Attributes
+			}
+
+			pricing, err := domain.NewPricingContext(
+				pricingHeader(request, attributes, ChannelHeader, domain.PricingChannel),
+				pricingHeader(request, attributes, SegmentHeader, domain.PricingSegment),
+				pricingHeader(request, attributes, CountryHeader, domain.PricingCountry),
+			)
+			if err != nil {
+				invalidRequest(writer, err)
+
+				return
+			}
+
+			pricing = withPrincipalPricing(request.Context(), pricing, attributes)
+
+			writer.Header().Add("Vary", ChannelHeader+", "+SegmentHeader+", "+CountryHeader)
+			if principalAttributes {
+				writer.Header().Add("Vary", "Authorization")
+			}
+
+			next.ServeHTTP(writer, request.WithContext(context.WithValue(request.Context(), pricingContextKey{}, pricing)))
+		})
+	}
+}
+
+// pricingContextFrom the zero context, matching rules that target nobody, when Pricing did not run
+func pricingContextFrom(ctx context.Context) domain.PricingContext {
+	pricing, _ := ctx.Value(pricingContextKey{}).(domain.PricingContext)
+
+	return pricing
+}
+
+// pricingHeader the header of a field the principal does not set, headers overridden are not even validated
+func pricingHeader(request *http.Request, attributes map[string]string, header, field string) string {
+	if _, ok := attributes[field]; ok {
+		return ""
+	}
+
+	return request.Header.Get(header)
+}
+
+// withPrincipalPricing replaces the fields the principal sets. Invalid attributes come from a misconfigured issuer the
+// client can't fix, they are logged and the fields they set are left unknown
+func withPrincipalPricing(ctx context.Context, pricing domain.PricingContext, attributes map[string]string) domain.PricingContext {
+	fromPrincipal, err := domain.NewPricingContext(
+		attributes[domain.PricingChannel],
+		attributes[domain.PricingSegment],
+		attributes[domain.PricingCountry],
+	)
+	if err != nil {
+		logging.FromContext(ctx).Warn("ignoring invalid pricing attributes of the principal", slog.Any("error", err))
+		fromPrincipal = domain.PricingContext{}
+	}
+
+	if _, ok := attributes[domain.PricingChannel]; ok {
+		pricing.Channel = fromPrincipal.Channel
+	}
+
+	if _, ok := attributes[domain.PricingSegment]; ok {
+		pricing.Segment = fromPrincipal.Segment
+	}
+
+	if _, ok := attributes[domain.PricingCountry]; ok {
+		pricing.Country = fromPrincipal.Country
+	}
+
+	return pricing
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/shared/api"
+	"go-products.com/m/internal/shared/auth"
+)
+
+// fixedCatalogVersion a catalog that is never written
+type fixedCatalogVersion int
+
+func (v fixedCatalogVersion) GetCatalogVersion(context.Context) (int, error) {
+	return int(v), nil
+}
+
+func TestPricing(t *testing.T) {
+	assertions := require.New(t)
+
+	wholesale := &auth.Principal{Subject: "shop", Role: auth.RoleViewer, Attributes: map[string]string{"channel": "wholesale"}}
+
+	tests := []struct {
+		name                string
+		principalAttributes bool
+		headers             map[string]string
+		principal           *auth.Principal
+		expectedStatusCode  int
+		expectedPricing     domain.PricingContext
+		expectedVary        []string
+	}{
+		{
+			name:               "Request without pricing headers is priced for nobody in particular",
+			expectedStatusCode: http.StatusOK,
+			expectedVary:       []string{"X-Channel, X-Customer-Segment, X-Country"},
+		},
+		{
+			name:               "Pricing headers are normalized",
+			headers:            map[string]string{ChannelHeader: " App ", SegmentHeader: "VIP", CountryHeader: "es"},
+			expectedStatusCode: http.StatusOK,
+			expectedPricing:    domain.PricingContext{Channel: domain.ChannelApp, Segment: "vip", Country: "ES"},
+			expectedVary:       []string{"X-Channel, X-Customer-Segment, X-Country"},
+		},
+		{
+			name:                "Principal attributes win over headers",
+			principalAttributes: true,
+			headers:             map[string]string{ChannelHeader: "store", SegmentHeader: "vip", CountryHeader: "FR"},
+			principal:           &auth.Principal{Subject: "shop", Role: auth.RoleViewer, Attributes: map[string]string{"channel": "Web", "segment": "employee"}},
+			expectedStatusCode:  http.StatusOK,
+			expectedPricing:     domain.PricingContext{Channel: domain.ChannelWeb, Segment: "employee", Country: "FR"},
+			expectedVary:        []string{"X-Channel, X-Customer-Segment, X-Country", "Authorization"},
+		},
+		{
+			name:                "Anonymous requests vary by Authorization when principals carry pricing attributes",
+			principalAttributes: true,
+			headers:             map[string]string{ChannelHeader: "wholesale"},
+			expectedStatusCode:  http.StatusOK,
+			expectedPricing:     domain.PricingContext{Channel: domain.ChannelWholesale},
+			expectedVary:        []string{"X-Channel, X-Customer-Segment, X-Country", "Authorization"},
+		},
+		{
+			name:                "Invalid principal attributes are ignored",
+			principalAttributes: true,
+			headers:             map[string]string{ChannelHeader: "web", CountryHeader: "ES"},
+			principal:           &auth.Principal{Subject: "shop", Role: auth.RoleViewer, Attributes: map[string]string{"channel": "store"}},
+			expectedStatusCode:  http.StatusOK,
+			expectedPricing:     domain.PricingContext{Country: "ES"},
+			expectedVary:        []string{"X-Channel, X-Customer-Segment, X-Country", "Authorization"},
+		},
+		{
+			name:               "Principal attributes are not read unless enabled",
+			headers:            map[string]string{ChannelHeader: "app"},
+			principal:          wholesale,
+			expectedStatusCode: http.StatusOK,
+			expectedPricing:    domain.PricingContext{Channel: domain.ChannelApp},
+			expectedVary:       []string{"X-Channel, X-Customer-Segment, X-Country"},
+		},
+		{
+			name:               "Invalid pricing headers return a 400",
+			headers:            map[string]string{ChannelHeader: "store", CountryHeader: "Spain"},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pricing domain.PricingContext
+			next := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				pricing = pricingContextFrom(request.Context())
+			})
+
+			request := httptest.NewRequest(http.MethodGet, "/api/v1/products", nil)
+			for header, value := range tt.headers {
+				request.Header.Set(header, value)
+			}
+
+			if tt.principal != nil {
+				request = request.WithContext(auth.WithPrincipal(request.Context(), *tt.principal))
+			}
+
+			recorder := httptest.NewRecorder()
+			Pricing(tt.principalAttributes)(next).ServeHTTP(recorder, request)
+
+			assertions.Equal(tt.expectedStatusCode, recorder.Code)
+			assertions.Equal(tt.expectedPricing, pricing)
+			if tt.expectedVary != nil {
+				assertions.Equal(tt.expectedVary, recorder.Header().Values("Vary"))
+			}
+		})
+	}
+}
+
+// A shared cache may only reuse a stored response for requests matching every header in Vary, prices of anonymous
+// callers must not be served to principals whose attributes set other ones
+func TestPricing_SharedCache(t *testing.T) {
+	assertions := require.New(t)
+
+	priced := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(pricingContextFrom(request.Context()).String()))
+	})
+	handler := Pricing(true)(api.Cache(api.CachePolicy{MaxAge: time.Minute}, CatalogVersion(fixedCatalogVersion(7)))(priced))
+
+	anonymous := httptest.NewRecorder()
+	handler.ServeHTTP(anonymous, httptest.NewRequest(http.MethodGet, "/api/v1/products", nil))
+
+	assertions.Equal(http.StatusOK, anonymous.Code)
+	assertions.Equal("public, max-age=60", anonymous.Header().Get("Cache-Control"))
+	assertions.Contains(anonymous.Header().Values("Vary"), "Authorization")
+
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/products", nil)
+	request.Header.Set("Authorization", "Bearer token")
+	request = request.WithContext(auth.WithPrincipal(request.Context(), auth.Principal{Subject: "shop", Attributes: map[string]string{"channel": "wholesale"}}))
+	principal := httptest.NewRecorder()
+	handler.ServeHTTP(principal, request)
+
+	assertions.Equal("wholesale//", principal.Body.String())
+	assertions.NotEqual(anonymous.Header().Get("ETag"), principal.Header().Get("ETag"))
+}
+
+func TestCatalogVersion_PricingContext(t *testing.T) {
+	assertions := require.New(t)
+
+	version := CatalogVersion(fixedCatalogVersion(7))
+	withoutContext, err := version(context.Background())
+	assertions.NoError(err)
+
+	wholesale, err := version(context.WithValue(context.Background(), pricingContextKey{}, domain.PricingContext{Channel: domain.ChannelWholesale}))
+	assertions.NoError(err)
+
+	app, err := version(context.WithValue(context.Background(), pricingContextKey{}, domain.PricingContext{Channel: domain.ChannelApp}))
+	assertions.NoError(err)
+
+	assertions.NotEqual(withoutContext, wholesale)
+	assertions.NotEqual(wholesale, app)
+	assertions.True(strings.HasSuffix(wholesale, "-wholesale//"), wholesale)
+}
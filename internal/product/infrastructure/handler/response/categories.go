@@ -0,0 +1,51 @@
+package response
+
+import (
+	"go-products.com/m/internal/product/domain"
+)
+
+type CategoryResponse struct {
+	Slug     string             `json:"slug" xml:"slug"`
+	Name     string             `json:"name" xml:"name"`
+	Parent   *string            `json:"parent" xml:"parent"`
+	Children []CategoryResponse `json:"children" xml:"children>category"`
+}
+
+// FromDomainCategories builds the categories tree, roots are returned at the top level and every category carries its children
+func FromDomainCategories(categories []domain.Category) []CategoryResponse {
+	childrenByParent := make(map[string][]domain.Category)
+	roots := make([]domain.Category, 0)
+
+	for _, category := range categories {
+		if category.IsRoot() {
+			roots = append(roots, category)
+			continue
+		}
+
+		childrenByParent[*category.ParentSlug] = append(childrenByParent[*category.ParentSlug], category)
+	}
+
+	return buildCategoriesTree(roots, childrenByParent)
+}
+
+func FromDomainCategory(category domain.Category) CategoryResponse {
+	return CategoryResponse{
+		Slug:     category.Slug,
+		Name:     category.Name,
+		Parent:   category.ParentSlug,
+		Children: make([]CategoryResponse, 0),
+	}
+}
+
+func buildCategoriesTree(categories []domain.Category, childrenByParent map[string][]domain.Category) []CategoryResponse {
+	categoriesResponse := make([]CategoryResponse, 0)
+
+	for _, category := range categories {
+		categoryResponse := FromDomainCategory(category)
+		categoryResponse.Children = buildCategoriesTree(childrenByParent[category.Slug], childrenByParent)
+
+		categoriesResponse = append(categoriesResponse, categoryResponse)
+	}
+
+	return categoriesResponse
+}
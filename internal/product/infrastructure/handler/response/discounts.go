@@ -0,0 +1,118 @@
+package response
+
+import (
+	"go-products.com/m/internal/product/domain"
+)
+
+// biggestChangesLimit products listed in a simulation, the rest are only counted
+const biggestChangesLimit = 10
+
+// DiscountRuleResponse Percentage is a fraction of the price, as it is sent when the rule is created. Channel, Segment
+// and Country are only set for rules targeting them
+type DiscountRuleResponse struct {
+	ID         int     `json:"id" xml:"id"`
+	Kind       string  `json:"kind" xml:"kind"`
+	Key        string  `json:"key" xml:"key"`
+	Percentage float64 `json:"percentage" xml:"percentage"`
+	Channel    string  `json:"channel,omitempty" xml:"channel,omitempty"`
+	Segment    string  `json:"segment,omitempty" xml:"segment,omitempty"`
+	Country    string  `json:"country,omitempty" xml:"country,omitempty"`
+}
+
+// DiscountSimulationResponse how final prices change if the proposed rules replace the stored ones
+type DiscountSimulationResponse struct {
+	Products       int                    `json:"products" xml:"products"`
+	Increased      int                    `json:"increased" xml:"increased"`
+	Decreased      int                    `json:"decreased" xml:"decreased"`
+	Unchanged      int                    `json:"unchanged" xml:"unchanged"`
+	MarginImpact   MarginImpact           `json:"margin_impact" xml:"margin_impact"`
+	BiggestChanges []SimulatedPriceChange `json:"biggest_changes" xml:"biggest_changes>change"`
+}
+
+// MarginImpact sum of the final prices of one unit of every product today and under the proposed rules, Currency is
+// left out when there are no products
+type MarginImpact struct {
+	Current    int    `json:"current" xml:"current"`
+	Proposed   int    `json:"proposed" xml:"proposed"`
+	Difference int    `json:"difference" xml:"difference"`
+	Currency   string `json:"currency,omitempty" xml:"currency,omitempty"`
+}
+
+type SimulatedPriceChange struct {
+	Sku                        string  `json:"sku" xml:"sku"`
+	Name                       string  `json:"name" xml:"name"`
+	Category                   string  `json:"category" xml:"category"`
+	Original                   int     `json:"original" xml:"original"`
+	CurrentFinal               int     `json:"current_final" xml:"current_final"`
+	ProposedFinal              int     `json:"proposed_final" xml:"proposed_final"`
+	Difference                 int     `json:"difference" xml:"difference"`
+	CurrentDiscountPercentage  *string `json:"current_discount_percentage" xml:"current_discount_percentage"`
+	ProposedDiscountPercentage *string `json:"proposed_discount_percentage" xml:"proposed_discount_percentage"`
+}
+
+func FromDomainDiscountRules(rules []domain.DiscountRule) []DiscountRuleResponse {
+	rulesResponse := make([]DiscountRuleResponse, 0, len(rules))
+	for _, rule := range rules {
+		rulesResponse = append(rulesResponse, FromDomainDiscountRule(rule))
+	}
+
+	return rulesResponse
+}
+
+func FromDomainDiscountRule(rule domain.DiscountRule) DiscountRuleResponse {
+	return DiscountRuleResponse{
+		ID:         rule.ID,
+		Kind:       rule.Kind,
+		Key:        rule.Key,
+		Percentage: rule.Percentage,
+		Channel:    rule.Target.Channel,
+		Segment:    rule.Target.Segment,
+		Country:    rule.Target.Country,
+	}
+}
+
+// FromDomainDiscountSimulation lists the biggest changes only
+func FromDomainDiscountSimulation(simulation domain.DiscountSimulation) DiscountSimulationResponse {
+	changes := simulation.Changes
+	if len(changes) > biggestChangesLimit {
+		changes = changes[:biggestChangesLimit]
+	}
+
+	biggestChanges := make([]SimulatedPriceChange, 0, len(changes))
+	for _, change := range changes {
+		biggestChanges = append(biggestChanges, SimulatedPriceChange{
+			Sku:                        change.Product.Sku,
+			Name:                       change.Product.Name,
+			Category:                   change.Product.Category,
+			Original:                   change.Product.Price,
+			CurrentFinal:               change.Current.FinalPrice,
+			ProposedFinal:              change.Proposed.FinalPrice,
+			Difference:                 change.Difference(),
+			CurrentDiscountPercentage:  discountPercentage(change.Current),
+			ProposedDiscountPercentage: discountPercentage(change.Proposed),
+		})
+	}
+
+	return DiscountSimulationResponse{
+		Products:  simulation.Products,
+		Increased: simulation.Increased,
+		Decreased: simulation.Decreased,
+		Unchanged: simulation.Unchanged,
+		MarginImpact: MarginImpact{
+			Current:    simulation.CurrentRevenue,
+			Proposed:   simulation.ProposedRevenue,
+			Difference: simulation.ProposedRevenue - simulation.CurrentRevenue,
+			Currency:   simulation.Currency,
+		},
+		BiggestChanges: biggestChanges,
+	}
+}
+
+func discountPercentage(discount domain.Discount) *string {
+	percentage, ok := discount.FormattedPercentage()
+	if !ok {
+		return nil
+	}
+
+	return &percentage
+}
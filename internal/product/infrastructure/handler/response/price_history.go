@@ -0,0 +1,37 @@
+package response
+
+import (
+	"time"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/product/use_cases"
+)
+
+type PriceHistoryResponse struct {
+	Sku            string                `json:"sku" xml:"sku"`
+	LowestPrice30d *int                  `json:"lowest_price_30d" xml:"lowest_price_30d"`
+	Currency       string                `json:"currency" xml:"currency"`
+	History        []PriceChangeResponse `json:"history" xml:"history>change"`
+}
+
+type PriceChangeResponse struct {
+	Price     int       `json:"price" xml:"price"`
+	ChangedAt time.Time `json:"changed_at" xml:"changed_at"`
+}
+
+func FromPriceHistory(priceHistory use_cases.PriceHistory) PriceHistoryResponse {
+	history := make([]PriceChangeResponse, 0)
+	for _, change := range priceHistory.Changes {
+		history = append(history, PriceChangeResponse{
+			Price:     change.Price,
+			ChangedAt: change.ChangedAt,
+		})
+	}
+
+	return PriceHistoryResponse{
+		Sku:            priceHistory.Sku,
+		LowestPrice30d: priceHistory.LowestPriceInWindow,
+		Currency:       domain.EUR,
+		History:        history,
+	}
+}
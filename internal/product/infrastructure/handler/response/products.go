@@ -1,53 +1,151 @@
 package response
 
-import (
-	"strconv"
-
-	"go-products.com/m/internal/product/domain"
-)
+import "go-products.com/m/internal/product/domain"
 
 type ProductResponse struct {
-	Sku      string   `json:"sku"`
-	Name     string   `json:"name"`
-	Category string   `json:"category"`
-	Price    Discount `json:"price"`
+	Sku      string   `json:"sku" xml:"sku"`
+	Name     string   `json:"name" xml:"name"`
+	Category string   `json:"category" xml:"category"`
+	Status   string   `json:"status" xml:"status"`
+	Price    Discount `json:"price" xml:"price"`
+	// CategoryDetails only set when expanded with include=category_details
+	CategoryDetails *CategoryDetails `json:"category_details,omitempty" xml:"category_details,omitempty"`
+}
+
+// CategoryDetails the category of a product, Path lists the slugs from the root of the catalog down to it
+type CategoryDetails struct {
+	Slug   string   `json:"slug" xml:"slug"`
+	Name   string   `json:"name" xml:"name"`
+	Parent *string  `json:"parent" xml:"parent"`
+	Path   []string `json:"path" xml:"path>slug"`
 }
 
 type Discount struct {
-	Original           int     `json:"original"`
-	Final              int     `json:"final"`
-	DiscountPercentage *string `json:"discount_percentage"`
-	Currency           string  `json:"currency"`
+	Original           int     `json:"original" xml:"original"`
+	Final              int     `json:"final" xml:"final"`
+	DiscountPercentage *string `json:"discount_percentage" xml:"discount_percentage"`
+	LowestPrice30d     *int    `json:"lowest_price_30d" xml:"lowest_price_30d"`
+	Currency           string  `json:"currency" xml:"currency"`
+	// DiscountBreakdown only set when expanded with include=discount_breakdown, products matching no discount have none
+	DiscountBreakdown []DiscountBreakdown `json:"discount_breakdown,omitempty" xml:"discount_breakdown>discount"`
 }
 
-func FromDomainProducts(products []domain.Product) []ProductResponse {
+// DiscountBreakdown a discount matching the product, by its category or its sku, and whether the biggest discount
+// rule applied it or dropped it. Channel, Segment and Country are set for discounts targeting them
+type DiscountBreakdown struct {
+	Rule       string `json:"rule" xml:"rule"`
+	Key        string `json:"key" xml:"key"`
+	Percentage string `json:"percentage" xml:"percentage"`
+	Amount     int    `json:"amount" xml:"amount"`
+	Outcome    string `json:"outcome" xml:"outcome"`
+	Channel    string `json:"channel,omitempty" xml:"channel,omitempty"`
+	Segment    string `json:"segment,omitempty" xml:"segment,omitempty"`
+	Country    string `json:"country,omitempty" xml:"country,omitempty"`
+}
+
+const (
+	DiscountOutcomeApplied = "applied"
+	DiscountOutcomeDropped = "dropped"
+)
+
+// FromDomainProducts lowestPrices is keyed by sku, products without price history get a null lowest price. Prices are
+// the ones of the pricing context the products were priced for
+func FromDomainProducts(products []domain.PricedProduct, lowestPrices map[string]int) []ProductResponse {
 	productsResponse := make([]ProductResponse, 0)
 
 	for _, product := range products {
-		productsResponse = append(productsResponse, fromDomainProduct(product))
+		var lowestPrice *int
+		if price, ok := lowestPrices[product.Sku]; ok {
+			lowestPrice = &price
+		}
+
+		productsResponse = append(productsResponse, fromDomainProduct(product, lowestPrice))
 	}
 
 	return productsResponse
 }
 
-func fromDomainProduct(product domain.Product) ProductResponse {
-	discount := product.GetDiscount()
-	var discountPercentage *string = nil
-
-	if discount.Percentage != nil {
-		discountPercentageValue := strconv.Itoa(int(*discount.Percentage*100)) + "%"
-		discountPercentage = &discountPercentageValue
-	}
-
+func fromDomainProduct(product domain.PricedProduct, lowestPrice *int) ProductResponse {
 	return ProductResponse{
 		Sku:      product.Sku,
 		Name:     product.Name,
 		Category: product.Category,
+		Status:   string(product.Status),
 		Price: Discount{
 			Original:           product.Price,
-			Final:              discount.FinalPrice,
-			DiscountPercentage: discountPercentage,
+			Final:              product.Discount.FinalPrice,
+			DiscountPercentage: discountPercentage(product.Discount),
+			LowestPrice30d:     lowestPrice,
 			Currency:           product.Currency,
 		},
 	}
 }
+
+// WithDiscountBreakdown sets the breakdown of the discounts of every product, products hold the responses of
+// pricedProducts in the same order
+func WithDiscountBreakdown(products []ProductResponse, pricedProducts []domain.PricedProduct) []ProductResponse {
+	for i := range products {
+		for _, outcome := range pricedProducts[i].Discount.Breakdown {
+			status := DiscountOutcomeDropped
+			if outcome.Applied {
+				status = DiscountOutcomeApplied
+			}
+
+			products[i].Price.DiscountBreakdown = append(products[i].Price.DiscountBreakdown, DiscountBreakdown{
+				Rule:       outcome.Rule,
+				Key:        outcome.Key,
+				Percentage: outcome.FormattedPercentage(),
+				Amount:     outcome.Amount,
+				Outcome:    status,
+				Channel:    outcome.Target.Channel,
+				Segment:    outcome.Target.Segment,
+				Country:    outcome.Target.Country,
+			})
+		}
+	}
+
+	return products
+}
+
+// WithCategoryDetails sets the details of the category of every product, products whose category is not among
+// categories are left without them
+func WithCategoryDetails(products []ProductResponse, categories []domain.Category) []ProductResponse {
+	bySlug := make(map[string]domain.Category, len(categories))
+	for _, category := range categories {
+		bySlug[category.Slug] = category
+	}
+
+	for i := range products {
+		category, ok := bySlug[products[i].Category]
+		if !ok {
+			continue
+		}
+
+		products[i].CategoryDetails = &CategoryDetails{
+			Slug:   category.Slug,
+			Name:   category.Name,
+			Parent: category.ParentSlug,
+			Path:   categoryPath(category, bySlug),
+		}
+	}
+
+	return products
+}
+
+// categoryPath stops at parents that are missing or already visited so broken trees can't loop forever
+func categoryPath(category domain.Category, bySlug map[string]domain.Category) []string {
+	path := []string{category.Slug}
+	visited := map[string]bool{category.Slug: true}
+	for category.ParentSlug != nil && !visited[*category.ParentSlug] {
+		parent, ok := bySlug[*category.ParentSlug]
+		if !ok {
+			break
+		}
+
+		path = append([]string{parent.Slug}, path...)
+		visited[parent.Slug] = true
+		category = parent
+	}
+
+	return path
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/product/infrastructure/handler/response"
+	"go-products.com/m/internal/product/use_cases"
+	"go-products.com/m/internal/shared/api"
+)
+
+const (
+	// includeCategoryDetails expands the category of every product with its name and path in the catalog
+	includeCategoryDetails = "category_details"
+	// includeDiscountBreakdown explains the price of every product with the discounts matching it
+	includeDiscountBreakdown = "discount_breakdown"
+)
+
+// productSchema fields of a product clients can choose with the fields query param
+var productSchema = reflect.TypeFor[response.ProductResponse]()
+
+// productShape what clients ask the products to look like: the fields kept and the optional expansions
+type productShape struct {
+	fields   api.Fieldset
+	includes map[string]bool
+}
+
+// getProductShape reads the fields and include query params, an error lists every unknown name of the first of them
+// holding any
+func getProductShape(request *http.Request) (productShape, error) {
+	fields, err := api.ParseFieldset("fields", api.GetQueryParam(request, "fields"), productSchema)
+	if err != nil {
+		return productShape{}, err
+	}
+
+	includes, err := api.ParseIncludes("include", api.GetQueryParam(request, "include"), includeCategoryDetails, includeDiscountBreakdown)
+	if err != nil {
+		return productShape{}, err
+	}
+
+	return productShape{fields: fields, includes: includes}, nil
+}
+
+// expandDiscounts sets the discount breakdown when asked for, products hold the responses of pricedProducts
+func (s productShape) expandDiscounts(products []response.ProductResponse, pricedProducts []domain.PricedProduct) []response.ProductResponse {
+	if !s.includes[includeDiscountBreakdown] {
+		return products
+	}
+
+	return response.WithDiscountBreakdown(products, pricedProducts)
+}
+
+// expandCategories loads the category details when asked for
+func (s productShape) expandCategories(ctx context.Context, getCategoriesUseCase use_cases.GetCategoriesUseCase, products []response.ProductResponse) ([]response.ProductResponse, error) {
+	if !s.includes[includeCategoryDetails] {
+		return products, nil
+	}
+
+	categories, err := getCategoriesUseCase.Execute(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.WithCategoryDetails(products, categories), nil
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/product/infrastructure/handler/response"
+	"go-products.com/m/internal/product/use_cases"
+	"go-products.com/m/internal/shared/api"
+)
+
+type simulateDiscountRulesRequest struct {
+	Rules []domain.DiscountRuleDTO `json:"rules"`
+}
+
+// HandleSimulateDiscountRules dry run of a proposed rule set for the pricing context of the request, the stored rules
+// are left untouched
+func HandleSimulateDiscountRules(productsRepository domain.ProductRepository, discountRulesRepository domain.DiscountRuleRepository) http.HandlerFunc {
+	simulateDiscountRulesUseCase := use_cases.NewSimulateDiscountRulesUseCase(productsRepository, discountRulesRepository)
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		body, err := api.DecodeBody[simulateDiscountRulesRequest](request)
+		if err != nil {
+			api.InvalidRequest(writer, err.Error())
+
+			return
+		}
+
+		simulation, err := simulateDiscountRulesUseCase.Execute(request.Context(), body.Rules, pricingContextFrom(request.Context()))
+		if err != nil {
+			writeError(writer, request, err)
+
+			return
+		}
+
+		api.Success(writer, response.FromDomainDiscountSimulation(simulation))
+	}
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"go-products.com/m/internal/product/domain"
+	domainErrors "go-products.com/m/internal/product/domain/errors"
+	"go-products.com/m/internal/product/use_cases"
+	"go-products.com/m/internal/shared/api"
+)
+
+var ErrIncompleteProduct = errors.New("name, category and price are required, use PATCH for partial updates")
+
+// HandleUpdateProduct PUT replaces every editable field of the product
+func HandleUpdateProduct(productsRepository domain.ProductRepository) http.HandlerFunc {
+	return handleUpdateProduct(productsRepository, true)
+}
+
+// HandlePatchProduct PATCH only changes the fields present in the body
+func HandlePatchProduct(productsRepository domain.ProductRepository) http.HandlerFunc {
+	return handleUpdateProduct(productsRepository, false)
+}
+
+func handleUpdateProduct(productsRepository domain.ProductRepository, requireAllFields bool) http.HandlerFunc {
+	updateProductUseCase := use_cases.NewUpdateProductUseCase(productsRepository)
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		expectedVersion, ok := getExpectedVersion(writer, request)
+		if !ok {
+			return
+		}
+
+		changes, err := api.DecodeBody[domain.UpdateProductDTO](request)
+		if err != nil {
+			api.InvalidRequest(writer, err.Error())
+
+			return
+		}
+
+		if requireAllFields {
+			if err := missingFields(changes); err != nil {
+				api.InvalidFields(writer, ErrIncompleteProduct.Error(), fieldErrors(err))
+
+				return
+			}
+		}
+
+		product, err := updateProductUseCase.Execute(request.Context(), api.GetPathParam(request, "sku"), changes, expectedVersion)
+		writeProductUpdate(writer, request, product, err)
+	}
+}
+
+// missingFields every field a PUT body lacks
+func missingFields(changes domain.UpdateProductDTO) error {
+	var validation domainErrors.Validation
+	if changes.Name == nil {
+		validation.Check("name", domainErrors.NewNonEmptyString("name", ""))
+	}
+
+	if changes.Category == nil {
+		validation.Check("category", domainErrors.NewNonEmptyString("category", ""))
+	}
+
+	if changes.Price == nil {
+		validation.Check("price", domainErrors.NewNonEmptyString("price", ""))
+	}
+
+	return validation.Err()
+}
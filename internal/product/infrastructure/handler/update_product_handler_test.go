@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go-products.com/m/internal/product/domain"
+	domainErrors "go-products.com/m/internal/product/domain/errors"
+)
+
+func TestHandleUpdateProduct(t *testing.T) {
+	assertions := require.New(t)
+
+	newRepository := func() *domain.ProductRepositoryMock {
+		return &domain.ProductRepositoryMock{
+			GetProductFunc: func(ctx context.Context, sku string) (*domain.Product, error) {
+				if sku != "0001" {
+					return nil, domainErrors.ProductNotFound
+				}
+
+				return &domain.Product{Sku: "0001", Name: "Product 1", Category: "boots", Price: 100, Currency: domain.EUR, Status: domain.ProductStatusActive, Version: 3}, nil
+			},
+			UpdateProductFunc: func(ctx context.Context, product *domain.Product) error {
+				product.Version++
+
+				return nil
+			},
+		}
+	}
+
+	tests := []struct {
+		name               string
+		method             string
+		sku                string
+		body               string
+		ifMatch            string
+		expectedStatusCode int
+		expectedETag       string
+		expectedUpdates    int
+	}{
+		{
+			name:               "Patch product with current version returns a 204 and the new ETag",
+			method:             http.MethodPatch,
+			sku:                "0001",
+			body:               `{"name": "Renamed product"}`,
+			ifMatch:            `"3"`,
+			expectedStatusCode: http.StatusNoContent,
+			expectedETag:       `"4"`,
+			expectedUpdates:    1,
+		},
+		{
+			name:               "Patch product with any version returns a 204 and the new ETag",
+			method:             http.MethodPatch,
+			sku:                "0001",
+			body:               `{"name": "Renamed product"}`,
+			ifMatch:            `*`,
+			expectedStatusCode: http.StatusNoContent,
+			expectedETag:       `"4"`,
+			expectedUpdates:    1,
+		},
+		{
+			name:               "Patch product with a version that never exists returns a 412",
+			method:             http.MethodPatch,
+			sku:                "0001",
+			body:               `{"name": "Renamed product"}`,
+			ifMatch:            `"-1"`,
+			expectedStatusCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:               "Put product with missing fields returns a 400",
+			method:             http.MethodPut,
+			sku:                "0001",
+			body:               `{"name": "Renamed product"}`,
+			ifMatch:            `"3"`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "Put product with stale version returns a 412",
+			method:             http.MethodPut,
+			sku:                "0001",
+			body:               `{"name": "Renamed product", "category": "boots", "price": 90}`,
+			ifMatch:            `"2"`,
+			expectedStatusCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:               "Patch product with weak ETag returns a 412",
+			method:             http.MethodPatch,
+			sku:                "0001",
+			body:               `{"price": 90}`,
+			ifMatch:            `W/"3"`,
+			expectedStatusCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:               "Patch product without If-Match returns a 428",
+			method:             http.MethodPatch,
+			sku:                "0001",
+			body:               `{"price": 90}`,
+			expectedStatusCode: http.StatusPreconditionRequired,
+		},
+		{
+			name:               "Patch unknown product returns a 404",
+			method:             http.MethodPatch,
+			sku:                "0002",
+			body:               `{"price": 90}`,
+			ifMatch:            `"1"`,
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "Patch unknown product with any version returns a 404",
+			method:             http.MethodPatch,
+			sku:                "0002",
+			body:               `{"price": 90}`,
+			ifMatch:            `*`,
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := newRepository()
+			router := http.NewServeMux()
+			router.HandleFunc("PUT /api/v1/products/{sku}", HandleUpdateProduct(repository))
+			router.HandleFunc("PATCH /api/v1/products/{sku}", HandlePatchProduct(repository))
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(tt.method, "/api/v1/products/"+tt.sku, strings.NewReader(tt.body))
+			assertions.NoError(err)
+			if tt.ifMatch != "" {
+				request.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			router.ServeHTTP(recorder, request)
+
+			assertions.Equal(tt.expectedStatusCode, recorder.Code)
+			assertions.Equal(tt.expectedETag, recorder.Header().Get("ETag"))
+			assertions.Len(repository.UpdateProductCalls(), tt.expectedUpdates)
+		})
+	}
+}
+
+func TestHandleUpdateProduct_ValidationErrors(t *testing.T) {
+	assertions := require.New(t)
+
+	repository := &domain.ProductRepositoryMock{
+		GetProductFunc: func(ctx context.Context, sku string) (*domain.Product, error) {
+			return &domain.Product{Sku: sku, Name: "Product 1", Category: "boots", Price: 100, Currency: domain.EUR, Status: domain.ProductStatusActive, Version: 3}, nil
+		},
+	}
+
+	tests := []struct {
+		name             string
+		handler          http.HandlerFunc
+		body             string
+		expectedResponse string
+	}{
+		{
+			name:    "Every invalid field is reported",
+			handler: HandlePatchProduct(repository),
+			body:    `{"name": "", "price": 0}`,
+			expectedResponse: `{
+				"message": "name cannot be empty; price must be greater than 0",
+				"app_code": "INVALID_REQUEST",
+				"errors": [
+					{"field": "name", "code": "REQUIRED", "message": "name cannot be empty"},
+					{"field": "price", "code": "INVALID_PRICE", "message": "price must be greater than 0"}
+				]
+			}`,
+		},
+		{
+			name:    "Every missing field of a replacement is reported",
+			handler: HandleUpdateProduct(repository),
+			body:    `{"category": "boots"}`,
+			expectedResponse: `{
+				"message": "name, category and price are required, use PATCH for partial updates",
+				"app_code": "INVALID_REQUEST",
+				"errors": [
+					{"field": "name", "code": "REQUIRED", "message": "name cannot be empty"},
+					{"field": "price", "code": "REQUIRED", "message": "price cannot be empty"}
+				]
+			}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := http.NewServeMux()
+			router.HandleFunc("/api/v1/products/{sku}", tt.handler)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPatch, "/api/v1/products/0001", strings.NewReader(tt.body))
+			assertions.NoError(err)
+			request.Header.Set("If-Match", `"3"`)
+
+			router.ServeHTTP(recorder, request)
+
+			assertions.Equal(http.StatusBadRequest, recorder.Code)
+			assertions.JSONEq(tt.expectedResponse, recorder.Body.String())
+			assertions.Empty(repository.UpdateProductCalls())
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/product/use_cases"
+	"go-products.com/m/internal/shared/api"
+)
+
+type updateProductPriceRequest struct {
+	Price int `json:"price"`
+}
+
+func HandleUpdateProductPrice(productsRepository domain.ProductRepository) http.HandlerFunc {
+	updateProductPriceUseCase := use_cases.NewUpdateProductPriceUseCase(productsRepository)
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		expectedVersion, ok := getExpectedVersion(writer, request)
+		if !ok {
+			return
+		}
+
+		body, err := api.DecodeBody[updateProductPriceRequest](request)
+		if err != nil {
+			api.InvalidRequest(writer, err.Error())
+
+			return
+		}
+
+		product, err := updateProductPriceUseCase.Execute(request.Context(), api.GetPathParam(request, "sku"), body.Price, expectedVersion)
+		writeProductUpdate(writer, request, product, err)
+	}
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go-products.com/m/internal/product/infrastructure/persistance"
+	"go-products.com/m/internal/product/infrastructure/persistance/migrations"
+	sharedDatabaseUtils "go-products.com/m/internal/shared/database"
+)
+
+func TestIntegration_HandleUpdateProductPrice(t *testing.T) {
+	assertions := require.New(t)
+
+	database, err := sharedDatabaseUtils.GenerateDatabaseConnection(sharedDatabaseUtils.DatabaseConnection{
+		DatabaseName: "file:price_history?mode=memory&cache=shared",
+	}, migrations.CreateProductsDatabase)
+	assertions.NoError(err)
+
+	err = migrations.InitCategories(context.Background(), persistance.NewCategoriesSQLiteRepository(database), path.Join(".", "testdata", "categories.json"))
+	assertions.NoError(err)
+
+	productsRepository := persistance.NewProductsSQLiteRepository(database)
+	err = migrations.InitProducts(context.Background(), productsRepository, path.Join(".", "testdata", "products.json"))
+	assertions.NoError(err)
+
+	priceHistoryRepository := persistance.NewPriceHistorySQLiteRepository(database)
+
+	router := http.NewServeMux()
+	router.HandleFunc("/api/v1/products/{sku}/price", HandleUpdateProductPrice(productsRepository))
+	router.HandleFunc("/api/v1/products/{sku}/price-history", HandleGetPriceHistory(priceHistoryRepository))
+
+	testCases := []struct {
+		name               string
+		sku                string
+		body               string
+		ifMatch            string
+		expectedStatusCode int
+		expectedETag       string
+	}{
+		{
+			name:               "Update price successfully returns a 204",
+			sku:                "000004",
+			body:               `{"price": 69500}`,
+			ifMatch:            `"1"`,
+			expectedStatusCode: http.StatusNoContent,
+			expectedETag:       `"2"`,
+		},
+		{
+			name:               "Update price with stale version returns a 412",
+			sku:                "000004",
+			body:               `{"price": 59500}`,
+			ifMatch:            `"1"`,
+			expectedStatusCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:               "Update price with invalid price returns a 400",
+			sku:                "000004",
+			body:               `{"price": 0}`,
+			ifMatch:            `"2"`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "Update price of unknown product returns a 404",
+			sku:                "999999",
+			body:               `{"price": 100}`,
+			ifMatch:            `"1"`,
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPut, "/api/v1/products/"+tt.sku+"/price", strings.NewReader(tt.body))
+			assertions.NoError(err)
+			request.Header.Set("If-Match", tt.ifMatch)
+
+			router.ServeHTTP(recorder, request)
+
+			assertions.Equal(tt.expectedStatusCode, recorder.Code)
+			assertions.Equal(tt.expectedETag, recorder.Header().Get("ETag"))
+		})
+	}
+
+	t.Run("Price history records every change and the lowest price", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, "/api/v1/products/000004/price-history", nil)
+		assertions.NoError(err)
+
+		router.ServeHTTP(recorder, request)
+
+		var body struct {
+			Content struct {
+				LowestPrice30d int `json:"lowest_price_30d"`
+				History        []struct {
+					Price int `json:"price"`
+				} `json:"history"`
+			} `json:"content"`
+		}
+		assertions.Equal(http.StatusOK, recorder.Code)
+		assertions.NoError(json.Unmarshal(recorder.Body.Bytes(), &body))
+		assertions.Equal(69500, body.Content.LowestPrice30d)
+		assertions.Len(body.Content.History, 2)
+		assertions.Equal(79500, body.Content.History[0].Price)
+		assertions.Equal(69500, body.Content.History[1].Price)
+	})
+}
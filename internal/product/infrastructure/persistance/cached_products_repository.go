@@ -0,0 +1,190 @@
+package persistance
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/shared/cache"
+)
+
+type CacheOptions struct {
+	// Size maximum number of cached queries, caching is disabled when it is 0
+	Size int
+	// TTL bounds how stale a cached query can be when the database is written by someone else and there is no
+	// CatalogVersion
+	TTL time.Duration
+	// LoadTimeout bounds the queries loading the cache, defaultLoadTimeout when it is not positive
+	LoadTimeout time.Duration
+	// CatalogVersion when set is read before every query and the cache is purged once it changes, so writes made by
+	// other processes are seen at once instead of after TTL
+	CatalogVersion domain.CatalogVersionRepository
+}
+
+const defaultLoadTimeout = 10 * time.Second
+
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+	Size   int
+}
+
+// CachedProductsRepository read-through cache decorating a ProductRepository, every write through it or change of the
+// catalog version purges the cache
+type CachedProductsRepository struct {
+	repository     domain.ProductRepository
+	products       *cache.LRU[string, []domain.Product]
+	loads          cache.Group[loadKey, []domain.Product]
+	loadTimeout    time.Duration
+	catalogVersion domain.CatalogVersionRepository
+	// version last catalog version seen, -1 before the first query
+	version    atomic.Int64
+	generation atomic.Uint64
+	hits       atomic.Uint64
+	misses     atomic.Uint64
+}
+
+// loadKey loads started before an invalidation are not joined by the callers arriving after it
+type loadKey struct {
+	generation uint64
+	key        string
+}
+
+func NewCachedProductsRepository(repository domain.ProductRepository, options CacheOptions) *CachedProductsRepository {
+	loadTimeout := options.LoadTimeout
+	if loadTimeout <= 0 {
+		loadTimeout = defaultLoadTimeout
+	}
+
+	cached := &CachedProductsRepository{
+		repository:     repository,
+		products:       cache.NewLRU[string, []domain.Product](options.Size, options.TTL),
+		loadTimeout:    loadTimeout,
+		catalogVersion: options.CatalogVersion,
+	}
+	cached.version.Store(-1)
+
+	return cached
+}
+
+func (r *CachedProductsRepository) GetProducts(ctx context.Context, filters domain.ProductsFilters) ([]domain.Product, error) {
+	return r.load(ctx, filtersCacheKey(filters), func(ctx context.Context) ([]domain.Product, error) {
+		return r.repository.GetProducts(ctx, filters)
+	})
+}
+
+func (r *CachedProductsRepository) GetProduct(ctx context.Context, sku string) (*domain.Product, error) {
+	products, err := r.load(ctx, "sku="+sku, func(ctx context.Context) ([]domain.Product, error) {
+		product, err := r.repository.GetProduct(ctx, sku)
+		if err != nil {
+			return nil, err
+		}
+
+		return []domain.Product{*product}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &products[0], nil
+}
+
+func (r *CachedProductsRepository) CreateProduct(ctx context.Context, product domain.CreateProductDTO) error {
+	defer r.Invalidate()
+
+	return r.repository.CreateProduct(ctx, product)
+}
+
+func (r *CachedProductsRepository) UpdateProduct(ctx context.Context, product *domain.Product) error {
+	defer r.Invalidate()
+
+	return r.repository.UpdateProduct(ctx, product)
+}
+
+// Invalidate purges every cached query, loads already in flight are neither stored nor shared afterwards
+func (r *CachedProductsRepository) Invalidate() {
+	r.generation.Add(1)
+	r.products.Purge()
+}
+
+func (r *CachedProductsRepository) Stats() CacheStats {
+	return CacheStats{
+		Hits:   r.hits.Load(),
+		Misses: r.misses.Load(),
+		Size:   r.products.Len(),
+	}
+}
+
+// load returned products are copies so callers cannot modify cached entries, reads bypassing the cache are neither
+// served from it nor stored
+func (r *CachedProductsRepository) load(ctx context.Context, key string, fetch func(ctx context.Context) ([]domain.Product, error)) ([]domain.Product, error) {
+	if cache.Bypassed(ctx) {
+		return fetch(ctx)
+	}
+
+	// without knowing the catalog version cached entries may be stale, the query goes to the repository
+	if err := r.syncCatalogVersion(ctx); err != nil {
+		return fetch(ctx)
+	}
+
+	if products, ok := r.products.Get(key); ok {
+		r.hits.Add(1)
+
+		return slices.Clone(products), nil
+	}
+
+	r.misses.Add(1)
+	generation := r.generation.Load()
+	products, err, _ := r.loads.Do(loadKey{generation: generation, key: key}, func() ([]domain.Product, error) {
+		// the load is shared by every caller waiting on the key, the one starting it leaving must not fail the others
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), r.loadTimeout)
+		defer cancel()
+
+		products, err := fetch(ctx)
+		if err == nil && r.generation.Load() == generation {
+			r.products.Set(key, products)
+		}
+
+		return products, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.Clone(products), nil
+}
+
+// syncCatalogVersion purges the cache when the catalog changed since the last query, whoever wrote it
+func (r *CachedProductsRepository) syncCatalogVersion(ctx context.Context) error {
+	if r.catalogVersion == nil {
+		return nil
+	}
+
+	version, err := r.catalogVersion.GetCatalogVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	if r.version.Swap(int64(version)) != int64(version) {
+		r.Invalidate()
+	}
+
+	return nil
+}
+
+// filtersCacheKey equivalent filters produce the same key no matter which pointers hold their values
+func filtersCacheKey(filters domain.ProductsFilters) string {
+	return fmt.Sprintf("category=%s|price_less_than=%s|status=%s|limit=%s",
+		formatFilter(filters.Category), formatFilter(filters.PriceLessThan), formatFilter(filters.Status), formatFilter(filters.Limit))
+}
+
+func formatFilter[T any](value *T) string {
+	if value == nil {
+		return "-"
+	}
+
+	return fmt.Sprint(*value)
+}
@@ -0,0 +1,218 @@
+package persistance
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/shared/cache"
+)
+
+func TestCachedProductsRepository(t *testing.T) {
+	assertions := require.New(t)
+
+	newRepository := func(release <-chan struct{}) *domain.ProductRepositoryMock {
+		return &domain.ProductRepositoryMock{
+			GetProductsFunc: func(ctx context.Context, filters domain.ProductsFilters) ([]domain.Product, error) {
+				if release != nil {
+					<-release
+				}
+
+				return []domain.Product{{Sku: "0001", Name: "Product 1", Category: "boots", Price: 100}}, nil
+			},
+			CreateProductFunc: func(ctx context.Context, product domain.CreateProductDTO) error {
+				return nil
+			},
+		}
+	}
+
+	t.Run("Equivalent filters are served from cache", func(t *testing.T) {
+		repository := newRepository(nil)
+		cached := NewCachedProductsRepository(repository, CacheOptions{Size: 10, TTL: time.Minute})
+
+		first, second := "boots", "boots"
+		_, err := cached.GetProducts(context.Background(), domain.ProductsFilters{Category: &first})
+		assertions.NoError(err)
+		products, err := cached.GetProducts(context.Background(), domain.ProductsFilters{Category: &second})
+		assertions.NoError(err)
+
+		assertions.Len(products, 1)
+		assertions.Len(repository.GetProductsCalls(), 1)
+		assertions.Equal(CacheStats{Hits: 1, Misses: 1, Size: 1}, cached.Stats())
+	})
+
+	t.Run("Writes invalidate the cache", func(t *testing.T) {
+		repository := newRepository(nil)
+		cached := NewCachedProductsRepository(repository, CacheOptions{Size: 10, TTL: time.Minute})
+
+		_, err := cached.GetProducts(context.Background(), domain.ProductsFilters{})
+		assertions.NoError(err)
+		assertions.NoError(cached.CreateProduct(context.Background(), domain.CreateProductDTO{Sku: "0002"}))
+		_, err = cached.GetProducts(context.Background(), domain.ProductsFilters{})
+		assertions.NoError(err)
+
+		assertions.Len(repository.GetProductsCalls(), 2)
+	})
+
+	t.Run("Concurrent misses of the same filters load once", func(t *testing.T) {
+		release := make(chan struct{})
+		repository := newRepository(release)
+		cached := NewCachedProductsRepository(repository, CacheOptions{Size: 10, TTL: time.Minute})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _ = cached.GetProducts(context.Background(), domain.ProductsFilters{})
+			}()
+		}
+
+		assertions.Eventually(func() bool { return len(repository.GetProductsCalls()) == 1 }, time.Second, time.Millisecond)
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assertions.Len(repository.GetProductsCalls(), 1)
+	})
+
+	t.Run("Callers leaving do not fail the load shared with others", func(t *testing.T) {
+		release := make(chan struct{})
+		repository := &domain.ProductRepositoryMock{
+			GetProductsFunc: func(ctx context.Context, filters domain.ProductsFilters) ([]domain.Product, error) {
+				<-release
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+
+				return []domain.Product{{Sku: "0001", Name: "Product 1", Category: "boots", Price: 100}}, nil
+			},
+		}
+		cached := NewCachedProductsRepository(repository, CacheOptions{Size: 10, TTL: time.Minute})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		results := make(chan error, 2)
+		go func() {
+			_, err := cached.GetProducts(ctx, domain.ProductsFilters{})
+			results <- err
+		}()
+		assertions.Eventually(func() bool { return len(repository.GetProductsCalls()) == 1 }, time.Second, time.Millisecond)
+		go func() {
+			_, err := cached.GetProducts(context.Background(), domain.ProductsFilters{})
+			results <- err
+		}()
+
+		cancel()
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+
+		assertions.NoError(<-results)
+		assertions.NoError(<-results)
+		assertions.Len(repository.GetProductsCalls(), 1)
+		assertions.Equal(1, cached.Stats().Size)
+	})
+
+	t.Run("Reads after a write do not join loads started before it", func(t *testing.T) {
+		release := make(chan struct{})
+		var calls atomic.Int32
+		repository := &domain.ProductRepositoryMock{
+			GetProductsFunc: func(ctx context.Context, filters domain.ProductsFilters) ([]domain.Product, error) {
+				if calls.Add(1) == 1 {
+					<-release
+
+					return []domain.Product{{Sku: "0001", Name: "Before the write"}}, nil
+				}
+
+				return []domain.Product{{Sku: "0001", Name: "After the write"}}, nil
+			},
+			UpdateProductFunc: func(ctx context.Context, product *domain.Product) error {
+				return nil
+			},
+		}
+		cached := NewCachedProductsRepository(repository, CacheOptions{Size: 10, TTL: time.Minute})
+
+		before := make(chan []domain.Product, 1)
+		go func() {
+			products, _ := cached.GetProducts(context.Background(), domain.ProductsFilters{})
+			before <- products
+		}()
+		assertions.Eventually(func() bool { return calls.Load() == 1 }, time.Second, time.Millisecond)
+
+		assertions.NoError(cached.UpdateProduct(context.Background(), &domain.Product{Sku: "0001"}))
+		products, err := cached.GetProducts(context.Background(), domain.ProductsFilters{})
+		assertions.NoError(err)
+		assertions.Equal("After the write", products[0].Name)
+
+		close(release)
+		assertions.Equal("Before the write", (<-before)[0].Name)
+
+		products, err = cached.GetProducts(context.Background(), domain.ProductsFilters{})
+		assertions.NoError(err)
+		assertions.Equal("After the write", products[0].Name)
+		assertions.Len(repository.GetProductsCalls(), 2)
+	})
+
+	t.Run("Catalog version changes purge the cache", func(t *testing.T) {
+		repository := newRepository(nil)
+		catalogVersion := &catalogVersionStub{version: 1}
+		cached := NewCachedProductsRepository(repository, CacheOptions{Size: 10, TTL: time.Minute, CatalogVersion: catalogVersion})
+
+		for i := 0; i < 2; i++ {
+			_, err := cached.GetProducts(context.Background(), domain.ProductsFilters{})
+			assertions.NoError(err)
+		}
+		assertions.Len(repository.GetProductsCalls(), 1)
+
+		// written by another process
+		catalogVersion.version = 2
+		_, err := cached.GetProducts(context.Background(), domain.ProductsFilters{})
+		assertions.NoError(err)
+		assertions.Len(repository.GetProductsCalls(), 2)
+
+		// the cache is skipped while the version can't be read
+		catalogVersion.err = errors.New("database is locked")
+		_, err = cached.GetProducts(context.Background(), domain.ProductsFilters{})
+		assertions.NoError(err)
+		assertions.Len(repository.GetProductsCalls(), 3)
+	})
+
+	t.Run("Bypassing reads always reach the repository", func(t *testing.T) {
+		version := 1
+		repository := &domain.ProductRepositoryMock{
+			GetProductFunc: func(ctx context.Context, sku string) (*domain.Product, error) {
+				return &domain.Product{Sku: sku, Version: version}, nil
+			},
+		}
+		cached := NewCachedProductsRepository(repository, CacheOptions{Size: 10, TTL: time.Minute})
+
+		product, err := cached.GetProduct(context.Background(), "0001")
+		assertions.NoError(err)
+		assertions.Equal(1, product.Version)
+
+		version = 2
+		product, err = cached.GetProduct(context.Background(), "0001")
+		assertions.NoError(err)
+		assertions.Equal(1, product.Version)
+
+		product, err = cached.GetProduct(cache.Bypass(context.Background()), "0001")
+		assertions.NoError(err)
+		assertions.Equal(2, product.Version)
+		assertions.Len(repository.GetProductCalls(), 2)
+		assertions.Equal(CacheStats{Hits: 1, Misses: 1, Size: 1}, cached.Stats())
+	})
+}
+
+type catalogVersionStub struct {
+	version int
+	err     error
+}
+
+func (s *catalogVersionStub) GetCatalogVersion(context.Context) (int, error) {
+	return s.version, s.err
+}
@@ -0,0 +1,21 @@
+package persistance
+
+import (
+	"context"
+	"database/sql"
+)
+
+type CatalogVersionSQLiteRepository struct {
+	db *sql.DB
+}
+
+func NewCatalogVersionSQLiteRepository(db *sql.DB) *CatalogVersionSQLiteRepository {
+	return &CatalogVersionSQLiteRepository{db: db}
+}
+
+func (r *CatalogVersionSQLiteRepository) GetCatalogVersion(ctx context.Context) (int, error) {
+	var version int
+	err := r.db.QueryRowContext(ctx, "SELECT version FROM catalog_version WHERE id = 1;").Scan(&version)
+
+	return version, err
+}
@@ -0,0 +1,92 @@
+package persistance
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go-products.com/m/internal/product/domain"
+	domainErrors "go-products.com/m/internal/product/domain/errors"
+)
+
+type CategoriesSQLiteRepository struct {
+	db *sql.DB
+}
+
+var ErrGetCategories = errors.New("error getting categories")
+
+func NewCategoriesSQLiteRepository(db *sql.DB) *CategoriesSQLiteRepository {
+	return &CategoriesSQLiteRepository{db: db}
+}
+
+func (r *CategoriesSQLiteRepository) GetCategories(ctx context.Context) ([]domain.Category, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT slug, name, parent_slug FROM categories ORDER BY slug;")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrGetCategories, err)
+	}
+	defer rows.Close()
+
+	categories := make([]domain.Category, 0)
+	for rows.Next() {
+		var category domain.Category
+		var parentSlug sql.NullString
+		if err := rows.Scan(&category.Slug, &category.Name, &parentSlug); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrParseRow, err)
+		}
+
+		if parentSlug.Valid {
+			category.ParentSlug = &parentSlug.String
+		}
+
+		categories = append(categories, category)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrGetCategories, err)
+	}
+
+	return categories, nil
+}
+
+func (r *CategoriesSQLiteRepository) CreateCategory(ctx context.Context, category domain.CreateCategoryDTO) error {
+	domainCategory, err := domain.NewCategory(category.Slug, category.Name, category.Parent)
+	if err != nil {
+		return err
+	}
+
+	if !domainCategory.IsRoot() {
+		exists, err := categoryExists(ctx, r.db, *domainCategory.ParentSlug)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return domainErrors.NewInvalidField("parent", domainErrors.NewUnknownCategory(*domainCategory.ParentSlug))
+		}
+	}
+
+	_, err = r.db.ExecContext(ctx, "INSERT INTO categories (slug, name, parent_slug) VALUES (?, ?, ?);", domainCategory.Slug, domainCategory.Name, domainCategory.ParentSlug)
+	if isUniqueViolation(err) {
+		return domainErrors.CategoryAlreadyExists
+	}
+
+	return err
+}
+
+func categoryExists(ctx context.Context, db *sql.DB, slug string) (bool, error) {
+	var exists bool
+	err := db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM categories WHERE slug = ?);", slug).Scan(&exists)
+
+	return exists, err
+}
+
+func isUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	errMsg := err.Error()
+	return strings.Contains(errMsg, "UNIQUE constraint") || strings.Contains(errMsg, "PRIMARY KEY")
+}
@@ -0,0 +1,158 @@
+package persistance
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"go-products.com/m/internal/product/domain"
+	domainErrors "go-products.com/m/internal/product/domain/errors"
+)
+
+type DiscountRulesSQLiteRepository struct {
+	db *sql.DB
+}
+
+var (
+	ErrGetDiscountRules   = errors.New("error getting discount rules")
+	ErrCreateDiscountRule = errors.New("error creating discount rule")
+	ErrUpdateDiscountRule = errors.New("error updating discount rule")
+	ErrDeleteDiscountRule = errors.New("error deleting discount rule")
+	ErrSeedDiscountRules  = errors.New("error seeding discount rules")
+)
+
+func NewDiscountRulesSQLiteRepository(db *sql.DB) *DiscountRulesSQLiteRepository {
+	return &DiscountRulesSQLiteRepository{db: db}
+}
+
+func (r *DiscountRulesSQLiteRepository) GetDiscountRules(ctx context.Context) ([]domain.DiscountRule, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT id, kind, key, percentage, channel, segment, country FROM discount_rules ORDER BY id;")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrGetDiscountRules, err)
+	}
+	defer rows.Close()
+
+	rules := make([]domain.DiscountRule, 0)
+	for rows.Next() {
+		var rule domain.DiscountRule
+		if err := rows.Scan(&rule.ID, &rule.Kind, &rule.Key, &rule.Percentage, &rule.Target.Channel, &rule.Target.Segment, &rule.Target.Country); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrParseRow, err)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrGetDiscountRules, err)
+	}
+
+	return rules, nil
+}
+
+func (r *DiscountRulesSQLiteRepository) GetDiscountRule(ctx context.Context, id int) (*domain.DiscountRule, error) {
+	rule := domain.DiscountRule{ID: id}
+	err := r.db.QueryRowContext(ctx, "SELECT kind, key, percentage, channel, segment, country FROM discount_rules WHERE id = ?;", id).
+		Scan(&rule.Kind, &rule.Key, &rule.Percentage, &rule.Target.Channel, &rule.Target.Segment, &rule.Target.Country)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domainErrors.DiscountRuleNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrGetDiscountRules, err)
+	}
+
+	return &rule, nil
+}
+
+func (r *DiscountRulesSQLiteRepository) CreateDiscountRule(ctx context.Context, rule *domain.DiscountRule) error {
+	result, err := r.db.ExecContext(ctx, "INSERT INTO discount_rules (kind, key, percentage, channel, segment, country) VALUES (?, ?, ?, ?, ?, ?);",
+		rule.Kind, rule.Key, rule.Percentage, rule.Target.Channel, rule.Target.Segment, rule.Target.Country)
+	if isUniqueViolation(err) {
+		return domainErrors.DiscountRuleAlreadyExists
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrCreateDiscountRule, err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrCreateDiscountRule, err)
+	}
+
+	rule.ID = int(id)
+
+	return nil
+}
+
+func (r *DiscountRulesSQLiteRepository) UpdateDiscountRule(ctx context.Context, rule *domain.DiscountRule) error {
+	result, err := r.db.ExecContext(ctx, "UPDATE discount_rules SET kind = ?, key = ?, percentage = ?, channel = ?, segment = ?, country = ? WHERE id = ?;",
+		rule.Kind, rule.Key, rule.Percentage, rule.Target.Channel, rule.Target.Segment, rule.Target.Country, rule.ID)
+	if isUniqueViolation(err) {
+		return domainErrors.DiscountRuleAlreadyExists
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUpdateDiscountRule, err)
+	}
+
+	return discountRuleAffected(result, ErrUpdateDiscountRule)
+}
+
+func (r *DiscountRulesSQLiteRepository) DeleteDiscountRule(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM discount_rules WHERE id = ?;", id)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDeleteDiscountRule, err)
+	}
+
+	return discountRuleAffected(result, ErrDeleteDiscountRule)
+}
+
+// SeedDiscountRules stores rules unless discount rules were ever stored, rules deleted through the API must not come
+// back on the next start. It returns whether rules were stored
+func (r *DiscountRulesSQLiteRepository) SeedDiscountRules(ctx context.Context, rules []domain.DiscountRule) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrSeedDiscountRules, err)
+	}
+	defer tx.Rollback()
+
+	// sqlite_sequence keeps the last id given by discount_rules from its first insert on, even once it is empty
+	var stored bool
+	err = tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM sqlite_sequence WHERE name = 'discount_rules');").Scan(&stored)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrSeedDiscountRules, err)
+	}
+
+	if stored {
+		return false, nil
+	}
+
+	for _, rule := range rules {
+		_, err := tx.ExecContext(ctx, "INSERT INTO discount_rules (kind, key, percentage) VALUES (?, ?, ?);", rule.Kind, rule.Key, rule.Percentage)
+		if err != nil {
+			return false, fmt.Errorf("%w: %w", ErrSeedDiscountRules, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("%w: %w", ErrSeedDiscountRules, err)
+	}
+
+	return len(rules) > 0, nil
+}
+
+// discountRuleAffected DiscountRuleNotFound when the write matched no rule, other errors are wrapped in sentinel
+func discountRuleAffected(result sql.Result, sentinel error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: %w", sentinel, err)
+	}
+
+	if affected == 0 {
+		return domainErrors.DiscountRuleNotFound
+	}
+
+	return nil
+}
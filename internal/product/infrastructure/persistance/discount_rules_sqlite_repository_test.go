@@ -0,0 +1,70 @@
+package persistance
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go-products.com/m/internal/product/domain"
+	domainErrors "go-products.com/m/internal/product/domain/errors"
+	"go-products.com/m/internal/product/infrastructure/persistance/migrations"
+	"go-products.com/m/internal/shared/database"
+)
+
+func TestDiscountRulesSQLiteRepository_Errors(t *testing.T) {
+	assertions := require.New(t)
+
+	db, err := database.GenerateDatabaseConnection(database.DatabaseConnection{DatabaseName: filepath.Join(t.TempDir(), "products.db")}, nil)
+	assertions.NoError(err)
+
+	_, err = migrations.Up(context.Background(), db)
+	assertions.NoError(err)
+
+	repository := NewDiscountRulesSQLiteRepository(db)
+	rule := domain.DiscountRule{Kind: domain.DiscountRuleCategory, Key: "boots", Percentage: 0.3}
+	assertions.NoError(repository.CreateDiscountRule(context.Background(), &rule))
+
+	duplicate := domain.DiscountRule{Kind: domain.DiscountRuleCategory, Key: "boots", Percentage: 0.2}
+	assertions.ErrorIs(repository.CreateDiscountRule(context.Background(), &duplicate), domainErrors.DiscountRuleAlreadyExists)
+	assertions.ErrorIs(repository.DeleteDiscountRule(context.Background(), rule.ID+1), domainErrors.DiscountRuleNotFound)
+
+	assertions.NoError(db.Close())
+
+	tests := []struct {
+		name    string
+		write   func() error
+		wantErr error
+	}{
+		{
+			name:    "Create",
+			write:   func() error { return repository.CreateDiscountRule(context.Background(), &duplicate) },
+			wantErr: ErrCreateDiscountRule,
+		},
+		{
+			name:    "Update",
+			write:   func() error { return repository.UpdateDiscountRule(context.Background(), &rule) },
+			wantErr: ErrUpdateDiscountRule,
+		},
+		{
+			name:    "Delete",
+			write:   func() error { return repository.DeleteDiscountRule(context.Background(), rule.ID) },
+			wantErr: ErrDeleteDiscountRule,
+		},
+		{
+			name: "Seed",
+			write: func() error {
+				_, err := repository.SeedDiscountRules(context.Background(), []domain.DiscountRule{duplicate})
+
+				return err
+			},
+			wantErr: ErrSeedDiscountRules,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertions.ErrorIs(tt.write(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package persistance
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go-products.com/m/internal/product/domain"
+	domainErrors "go-products.com/m/internal/product/domain/errors"
+	"go-products.com/m/internal/shared/metrics"
+	"go-products.com/m/internal/shared/tracing"
+)
+
+// QueryMetrics durations and errors of the queries of every instrumented repository, a registry must only have one
+type QueryMetrics struct {
+	durations *metrics.HistogramVec
+	errors    *metrics.CounterVec
+}
+
+func NewQueryMetrics(registry *metrics.Registry) *QueryMetrics {
+	return &QueryMetrics{
+		durations: registry.NewHistogram("repository_query_duration_seconds", "Repository query latency in seconds.", metrics.DefaultBuckets, "repository", "operation"),
+		errors:    registry.NewCounter("repository_errors_total", "Repository queries that failed by error kind.", "repository", "operation", "error"),
+	}
+}
+
+// InstrumentedProductsRepository traces and records durations and errors of every query made through the decorated
+// repository
+type InstrumentedProductsRepository struct {
+	repository domain.ProductRepository
+	metrics    *QueryMetrics
+}
+
+func NewInstrumentedProductsRepository(repository domain.ProductRepository, queryMetrics *QueryMetrics) *InstrumentedProductsRepository {
+	return &InstrumentedProductsRepository{repository: repository, metrics: queryMetrics}
+}
+
+func (r *InstrumentedProductsRepository) GetProducts(ctx context.Context, filters domain.ProductsFilters) ([]domain.Product, error) {
+	ctx, done := r.metrics.observe(ctx, "products", "get_products")
+	products, err := r.repository.GetProducts(ctx, filters)
+	done(err)
+
+	return products, err
+}
+
+func (r *InstrumentedProductsRepository) GetProduct(ctx context.Context, sku string) (*domain.Product, error) {
+	ctx, done := r.metrics.observe(ctx, "products", "get_product")
+	product, err := r.repository.GetProduct(ctx, sku)
+	done(err)
+
+	return product, err
+}
+
+func (r *InstrumentedProductsRepository) CreateProduct(ctx context.Context, product domain.CreateProductDTO) error {
+	ctx, done := r.metrics.observe(ctx, "products", "create_product")
+	err := r.repository.CreateProduct(ctx, product)
+	done(err)
+
+	return err
+}
+
+func (r *InstrumentedProductsRepository) UpdateProduct(ctx context.Context, product *domain.Product) error {
+	ctx, done := r.metrics.observe(ctx, "products", "update_product")
+	err := r.repository.UpdateProduct(ctx, product)
+	done(err)
+
+	return err
+}
+
+type InstrumentedCategoriesRepository struct {
+	repository domain.CategoryRepository
+	metrics    *QueryMetrics
+}
+
+func NewInstrumentedCategoriesRepository(repository domain.CategoryRepository, queryMetrics *QueryMetrics) *InstrumentedCategoriesRepository {
+	return &InstrumentedCategoriesRepository{repository: repository, metrics: queryMetrics}
+}
+
+func (r *InstrumentedCategoriesRepository) GetCategories(ctx context.Context) ([]domain.Category, error) {
+	ctx, done := r.metrics.observe(ctx, "categories", "get_categories")
+	categories, err := r.repository.GetCategories(ctx)
+	done(err)
+
+	return categories, err
+}
+
+func (r *InstrumentedCategoriesRepository) CreateCategory(ctx context.Context, category domain.CreateCategoryDTO) error {
+	ctx, done := r.metrics.observe(ctx, "categories", "create_category")
+	err := r.repository.CreateCategory(ctx, category)
+	done(err)
+
+	return err
+}
+
+type InstrumentedPriceHistoryRepository struct {
+	repository domain.PriceHistoryRepository
+	metrics    *QueryMetrics
+}
+
+func NewInstrumentedPriceHistoryRepository(repository domain.PriceHistoryRepository, queryMetrics *QueryMetrics) *InstrumentedPriceHistoryRepository {
+	return &InstrumentedPriceHistoryRepository{repository: repository, metrics: queryMetrics}
+}
+
+func (r *InstrumentedPriceHistoryRepository) GetPriceHistory(ctx context.Context, sku string) ([]domain.PriceChange, error) {
+	ctx, done := r.metrics.observe(ctx, "price_history", "get_price_history")
+	history, err := r.repository.GetPriceHistory(ctx, sku)
+	done(err)
+
+	return history, err
+}
+
+func (r *InstrumentedPriceHistoryRepository) GetLowestPrices(ctx context.Context, skus []string, since time.Time) (map[string]int, error) {
+	ctx, done := r.metrics.observe(ctx, "price_history", "get_lowest_prices")
+	prices, err := r.repository.GetLowestPrices(ctx, skus, since)
+	done(err)
+
+	return prices, err
+}
+
+type InstrumentedDiscountRulesRepository struct {
+	repository domain.DiscountRuleRepository
+	metrics    *QueryMetrics
+}
+
+func NewInstrumentedDiscountRulesRepository(repository domain.DiscountRuleRepository, queryMetrics *QueryMetrics) *InstrumentedDiscountRulesRepository {
+	return &InstrumentedDiscountRulesRepository{repository: repository, metrics: queryMetrics}
+}
+
+func (r *InstrumentedDiscountRulesRepository) GetDiscountRules(ctx context.Context) ([]domain.DiscountRule, error) {
+	ctx, done := r.metrics.observe(ctx, "discount_rules", "get_discount_rules")
+	rules, err := r.repository.GetDiscountRules(ctx)
+	done(err)
+
+	return rules, err
+}
+
+func (r *InstrumentedDiscountRulesRepository) GetDiscountRule(ctx context.Context, id int) (*domain.DiscountRule, error) {
+	ctx, done := r.metrics.observe(ctx, "discount_rules", "get_discount_rule")
+	rule, err := r.repository.GetDiscountRule(ctx, id)
+	done(err)
+
+	return rule, err
+}
+
+func (r *InstrumentedDiscountRulesRepository) CreateDiscountRule(ctx context.Context, rule *domain.DiscountRule) error {
+	ctx, done := r.metrics.observe(ctx, "discount_rules", "create_discount_rule")
+	err := r.repository.CreateDiscountRule(ctx, rule)
+	done(err)
+
+	return err
+}
+
+func (r *InstrumentedDiscountRulesRepository) UpdateDiscountRule(ctx context.Context, rule *domain.DiscountRule) error {
+	ctx, done := r.metrics.observe(ctx, "discount_rules", "update_discount_rule")
+	err := r.repository.UpdateDiscountRule(ctx, rule)
+	done(err)
+
+	return err
+}
+
+func (r *InstrumentedDiscountRulesRepository) DeleteDiscountRule(ctx context.Context, id int) error {
+	ctx, done := r.metrics.observe(ctx, "discount_rules", "delete_discount_rule")
+	err := r.repository.DeleteDiscountRule(ctx, id)
+	done(err)
+
+	return err
+}
+
+// observe starts a span for the query, the returned function ends it and records the query duration and error
+func (m *QueryMetrics) observe(ctx context.Context, repository, operation string) (context.Context, func(err error)) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, repository+"."+operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(semconv.DBOperationName(operation)))
+
+	return ctx, func(err error) {
+		m.durations.Observe(time.Since(start).Seconds(), repository, operation)
+
+		kind := errorKind(err)
+		if kind != "" {
+			m.errors.Inc(repository, operation, kind)
+			span.SetAttributes(attribute.String("error.type", kind))
+		}
+
+		tracing.End(span, err)
+	}
+}
+
+// errorKind label for a failed query, outcomes expected by the domain such as a missing product are not errors
+func errorKind(err error) string {
+	var emptyStringErr domainErrors.ErrEmptyString
+	var unknownCategoryErr domainErrors.ErrUnknownCategory
+
+	// context errors come first, repository errors wrap the context error that interrupted the query
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "deadline_exceeded"
+	case err == nil,
+		errors.Is(err, domainErrors.ProductNotFound),
+		errors.Is(err, domainErrors.VersionMismatch),
+		errors.Is(err, domainErrors.InvalidPrice),
+		errors.Is(err, domainErrors.InvalidStatus),
+		errors.Is(err, domainErrors.InvalidSlug),
+		errors.Is(err, domainErrors.CategoryAlreadyExists),
+		errors.Is(err, domainErrors.CategoryOwnParent),
+		errors.Is(err, domainErrors.DiscountRuleNotFound),
+		errors.Is(err, domainErrors.DiscountRuleAlreadyExists),
+		errors.As(err, &emptyStringErr),
+		errors.As(err, &unknownCategoryErr):
+		return ""
+	case errors.Is(err, ErrGetProducts):
+		return "get_products"
+	case errors.Is(err, ErrParseRow):
+		return "parse_row"
+	case errors.Is(err, ErrGetCategories):
+		return "get_categories"
+	case errors.Is(err, ErrGetPriceHistory):
+		return "get_price_history"
+	case errors.Is(err, ErrGetDiscountRules):
+		return "get_discount_rules"
+	default:
+		return "other"
+	}
+}
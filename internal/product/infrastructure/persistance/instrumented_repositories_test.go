@@ -0,0 +1,77 @@
+package persistance
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go-products.com/m/internal/product/domain"
+	domainErrors "go-products.com/m/internal/product/domain/errors"
+	"go-products.com/m/internal/shared/metrics"
+)
+
+func TestInstrumentedProductsRepository_Metrics(t *testing.T) {
+	assertions := require.New(t)
+
+	registry := metrics.NewRegistry()
+	queryMetrics := NewQueryMetrics(registry)
+
+	var err error
+	repository := NewInstrumentedProductsRepository(&domain.ProductRepositoryMock{
+		GetProductFunc: func(ctx context.Context, sku string) (*domain.Product, error) {
+			return nil, err
+		},
+	}, queryMetrics)
+
+	for _, err = range []error{
+		nil,
+		domainErrors.ProductNotFound,
+		fmt.Errorf("%w: database is locked", ErrGetProducts),
+		context.DeadlineExceeded,
+	} {
+		_, _ = repository.GetProduct(context.Background(), "000001")
+	}
+
+	assertions.Equal(uint64(4), queryMetrics.durations.Count("products", "get_product"))
+	assertions.Equal(1.0, queryMetrics.errors.Value("products", "get_product", "get_products"))
+	assertions.Equal(1.0, queryMetrics.errors.Value("products", "get_product", "deadline_exceeded"))
+	assertions.Equal(0.0, queryMetrics.errors.Value("products", "get_product", ""))
+
+	var rendered strings.Builder
+	assertions.NoError(registry.Render(&rendered))
+	assertions.Contains(rendered.String(), `repository_query_duration_seconds_count{repository="products",operation="get_product"} 4`)
+}
+
+func TestInstrumentedDiscountRulesRepository_Metrics(t *testing.T) {
+	assertions := require.New(t)
+
+	queryMetrics := NewQueryMetrics(metrics.NewRegistry())
+	repository := NewInstrumentedDiscountRulesRepository(&discountRulesStub{err: fmt.Errorf("%w: disk I/O error", ErrGetDiscountRules)}, queryMetrics)
+
+	_, err := repository.GetDiscountRules(context.Background())
+	assertions.ErrorIs(err, ErrGetDiscountRules)
+	assertions.Equal(uint64(1), queryMetrics.durations.Count("discount_rules", "get_discount_rules"))
+	assertions.Equal(1.0, queryMetrics.errors.Value("discount_rules", "get_discount_rules", "get_discount_rules"))
+
+	_, err = repository.GetDiscountRule(context.Background(), 7)
+	assertions.ErrorIs(err, domainErrors.DiscountRuleNotFound)
+	assertions.Equal(uint64(1), queryMetrics.durations.Count("discount_rules", "get_discount_rule"))
+	assertions.Equal(0.0, queryMetrics.errors.Value("discount_rules", "get_discount_rule", "other"))
+}
+
+// discountRulesStub fails reading every rule with err, single rules are never found
+type discountRulesStub struct {
+	domain.DiscountRuleRepository
+	err error
+}
+
+func (s *discountRulesStub) GetDiscountRules(context.Context) ([]domain.DiscountRule, error) {
+	return nil, s.err
+}
+
+func (s *discountRulesStub) GetDiscountRule(context.Context, int) (*domain.DiscountRule, error) {
+	return nil, domainErrors.DiscountRuleNotFound
+}
@@ -0,0 +1,69 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// catalogTables every change on these tables invalidates the responses built from the catalog
+var catalogTables = []string{"categories", "products", "price_history"}
+
+// createCatalogVersion keeps a counter bumped by triggers on every catalog write so readers can cheaply know if
+// anything changed, even when the write comes from another process
+func createCatalogVersion(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS catalog_version (
+    		id INTEGER PRIMARY KEY CHECK (id = 1),
+    		version INTEGER NOT NULL
+);
+INSERT OR IGNORE INTO catalog_version (id, version) VALUES (1, 1);`)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range catalogTables {
+		if err := createCatalogVersionTriggers(ctx, tx, table); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func dropCatalogVersion(ctx context.Context, tx *sql.Tx) error {
+	for _, table := range catalogTables {
+		if err := dropCatalogVersionTriggers(ctx, tx, table); err != nil {
+			return err
+		}
+	}
+
+	_, err := tx.ExecContext(ctx, "DROP TABLE IF EXISTS catalog_version;")
+
+	return err
+}
+
+// createCatalogVersionTriggers bumps the catalog version on every write to table, tables created after the catalog
+// version add their own triggers
+func createCatalogVersionTriggers(ctx context.Context, tx *sql.Tx, table string) error {
+	for _, event := range []string{"INSERT", "UPDATE", "DELETE"} {
+		_, err := tx.ExecContext(ctx, fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS %[1]s_%[2]s_catalog_version AFTER %[2]s ON %[1]s
+BEGIN
+	UPDATE catalog_version SET version = version + 1 WHERE id = 1;
+END;`, table, event))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func dropCatalogVersionTriggers(ctx context.Context, tx *sql.Tx, table string) error {
+	for _, event := range []string{"INSERT", "UPDATE", "DELETE"} {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DROP TRIGGER IF EXISTS %s_%s_catalog_version;", table, event)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
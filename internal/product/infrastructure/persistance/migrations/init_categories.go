@@ -0,0 +1,52 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"time"
+
+	"go-products.com/m/internal/product/domain"
+	domainErrors "go-products.com/m/internal/product/domain/errors"
+	"go-products.com/m/internal/shared/logging"
+)
+
+// InitCategories categories must be listed after their parent in the migration file so parent existence can be validated
+func InitCategories(ctx context.Context, categoriesRepository domain.CategoryRepository, migrationFilePath string) error {
+	fileContent, err := os.ReadFile(migrationFilePath)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	created, skipped := 0, 0
+
+	categoriesCh := ReadJson[domain.CreateCategoryDTO](fileContent)
+	for categoryDTO := range categoriesCh {
+		if categoryDTO.Error != nil {
+			return categoryDTO.Error
+		}
+
+		err := categoriesRepository.CreateCategory(ctx, categoryDTO.Item)
+		if err != nil && !errors.Is(err, domainErrors.CategoryAlreadyExists) {
+			return err
+		}
+
+		if err != nil {
+			skipped++
+			continue
+		}
+
+		created++
+	}
+
+	logging.FromContext(ctx).Info("categories seeded",
+		slog.String("file", migrationFilePath),
+		slog.Int("created", created),
+		slog.Int("skipped", skipped),
+		slog.Duration("duration", time.Since(start)),
+	)
+
+	return nil
+}
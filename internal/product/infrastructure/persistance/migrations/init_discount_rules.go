@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"context"
+	"log/slog"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/shared/logging"
+)
+
+type discountRulesSeeder interface {
+	SeedDiscountRules(ctx context.Context, rules []domain.DiscountRule) (bool, error)
+}
+
+// InitDiscountRules stores the rules of the configured policy the first time, afterwards discount rules are managed
+// through the API and the configured policy is ignored
+func InitDiscountRules(ctx context.Context, repository discountRulesSeeder, policy domain.DiscountPolicy) error {
+	rules := policy.Rules()
+	seeded, err := repository.SeedDiscountRules(ctx, rules)
+	if err != nil {
+		return err
+	}
+
+	if seeded {
+		logging.FromContext(ctx).Info("discount rules seeded", slog.Int("created", len(rules)))
+	}
+
+	return nil
+}
@@ -2,33 +2,124 @@ package migrations
 
 import (
 	"context"
-	"database/sql"
+	"fmt"
+	"log/slog"
 	"os"
 	"strings"
+	"time"
 
 	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/shared/logging"
 )
 
+type SeedMode string
+
+const (
+	// SeedModeInsert products already stored are left untouched
+	SeedModeInsert SeedMode = "insert"
+	// SeedModeUpsert products already stored are updated with the file content
+	SeedModeUpsert SeedMode = "upsert"
+)
+
+func ParseSeedMode(mode string) (SeedMode, error) {
+	switch SeedMode(mode) {
+	case SeedModeInsert, SeedModeUpsert:
+		return SeedMode(mode), nil
+	default:
+		return "", fmt.Errorf("invalid seed mode %q, must be %s or %s", mode, SeedModeInsert, SeedModeUpsert)
+	}
+}
+
 func InitProducts(ctx context.Context, productsRepository domain.ProductRepository, migrationFilePath string) error {
+	return SeedProducts(ctx, productsRepository, migrationFilePath, SeedModeInsert)
+}
+
+func SeedProducts(ctx context.Context, productsRepository domain.ProductRepository, migrationFilePath string, mode SeedMode) error {
 	fileContent, err := os.ReadFile(migrationFilePath)
 	if err != nil {
 		return err
 	}
 
+	start := time.Now()
+	created, updated, skipped := 0, 0, 0
+
 	productsCh := ReadJson[domain.CreateProductDTO](fileContent)
 	for productDTO := range productsCh {
 		if productDTO.Error != nil {
-			return err
+			return productDTO.Error
 		}
 
-		if err := productsRepository.CreateProduct(ctx, productDTO.Item); err != nil && !isPrimaryKeyViolation(err) {
+		err := productsRepository.CreateProduct(ctx, productDTO.Item)
+		if err != nil && !isPrimaryKeyViolation(err) {
 			return err
 		}
+
+		if err == nil {
+			created++
+			continue
+		}
+
+		if mode != SeedModeUpsert {
+			skipped++
+			continue
+		}
+
+		changed, err := upsertProduct(ctx, productsRepository, productDTO.Item)
+		if err != nil {
+			return fmt.Errorf("updating product %s: %w", productDTO.Item.Sku, err)
+		}
+
+		if !changed {
+			skipped++
+			continue
+		}
+
+		updated++
 	}
 
+	logging.FromContext(ctx).Info("products seeded",
+		slog.String("file", migrationFilePath),
+		slog.String("mode", string(mode)),
+		slog.Int("created", created),
+		slog.Int("updated", updated),
+		slog.Int("skipped", skipped),
+		slog.Duration("duration", time.Since(start)),
+	)
+
 	return nil
 }
 
+// upsertProduct goes through the domain so seeded data follows the same rules as API updates, status changes must be
+// allowed transitions. Products already matching the file are not updated so their version is kept
+func upsertProduct(ctx context.Context, productsRepository domain.ProductRepository, productDTO domain.CreateProductDTO) (bool, error) {
+	product, err := productsRepository.GetProduct(ctx, productDTO.Sku)
+	if err != nil {
+		return false, err
+	}
+
+	current := *product
+	if err := product.Update(productDTO.Name, productDTO.Category, productDTO.Price); err != nil {
+		return false, err
+	}
+
+	if productDTO.Status != "" && domain.ProductStatus(productDTO.Status) != product.Status {
+		status, err := domain.ParseProductStatus(productDTO.Status)
+		if err != nil {
+			return false, err
+		}
+
+		if err := product.TransitionTo(status); err != nil {
+			return false, err
+		}
+	}
+
+	if *product == current {
+		return false, nil
+	}
+
+	return true, productsRepository.UpdateProduct(ctx, product)
+}
+
 func isPrimaryKeyViolation(err error) bool {
 	if err == nil {
 		return false
@@ -37,14 +128,3 @@ func isPrimaryKeyViolation(err error) bool {
 	errMsg := err.Error()
 	return strings.Contains(errMsg, "UNIQUE constraint") || strings.Contains(errMsg, "PRIMARY KEY")
 }
-
-func CreateProductsDatabase(db *sql.DB) error {
-	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS products (
-    		sku TEXT PRIMARY KEY,
-    		name TEXT NOT NULL,
-    		category TEXT NOT NULL,
-    		price INTEGER NOT NULL
-);`)
-
-	return err
-}
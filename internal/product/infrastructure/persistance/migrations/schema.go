@@ -0,0 +1,414 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Migration a versioned schema change, Up must be idempotent so databases created before migrations were tracked can
+// be brought under version control
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, tx *sql.Tx) error
+	Down    func(ctx context.Context, tx *sql.Tx) error
+}
+
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+// productsMigrations ordered by version, applied migrations must never be modified, add a new one instead
+var productsMigrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create_categories",
+		Up: exec(`CREATE TABLE IF NOT EXISTS categories (
+    		slug TEXT PRIMARY KEY,
+    		name TEXT NOT NULL,
+    		parent_slug TEXT REFERENCES categories(slug)
+);`),
+		Down: exec("DROP TABLE IF EXISTS categories;"),
+	},
+	{
+		Version: 2,
+		Name:    "create_products",
+		Up: exec(`CREATE TABLE IF NOT EXISTS products (
+    		sku TEXT PRIMARY KEY,
+    		name TEXT NOT NULL,
+    		category TEXT NOT NULL REFERENCES categories(slug),
+    		price INTEGER NOT NULL
+);`),
+		Down: exec("DROP TABLE IF EXISTS products;"),
+	},
+	{
+		Version: 3,
+		Name:    "add_products_status",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			return addColumnIfNotExists(ctx, tx, "products", "status", "TEXT NOT NULL DEFAULT 'active'")
+		},
+		Down: exec("ALTER TABLE products DROP COLUMN status;"),
+	},
+	{
+		Version: 4,
+		Name:    "add_products_version",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			return addColumnIfNotExists(ctx, tx, "products", "version", "INTEGER NOT NULL DEFAULT 1")
+		},
+		Down: exec("ALTER TABLE products DROP COLUMN version;"),
+	},
+	{
+		Version: 5,
+		Name:    "create_price_history",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS price_history (
+    		id INTEGER PRIMARY KEY AUTOINCREMENT,
+    		sku TEXT NOT NULL REFERENCES products(sku),
+    		price INTEGER NOT NULL,
+    		changed_at INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS price_history_sku_changed_at ON price_history (sku, changed_at);`)
+			if err != nil {
+				return err
+			}
+
+			// products created before price history existed get their current price as the first history entry
+			_, err = tx.ExecContext(ctx, `INSERT INTO price_history (sku, price, changed_at)
+		SELECT sku, price, ? FROM products WHERE sku NOT IN (SELECT sku FROM price_history);`, time.Now().UnixMilli())
+
+			return err
+		},
+		Down: exec("DROP INDEX IF EXISTS price_history_sku_changed_at; DROP TABLE IF EXISTS price_history;"),
+	},
+	{
+		Version: 6,
+		Name:    "create_catalog_version",
+		Up:      createCatalogVersion,
+		Down:    dropCatalogVersion,
+	},
+	{
+		Version: 7,
+		Name:    "create_api_keys",
+		Up: exec(`CREATE TABLE IF NOT EXISTS api_keys (
+    		id TEXT PRIMARY KEY,
+    		name TEXT NOT NULL,
+    		role TEXT NOT NULL,
+    		key_hash TEXT NOT NULL UNIQUE,
+    		created_at INTEGER NOT NULL,
+    		revoked_at INTEGER
+);`),
+		Down: exec("DROP TABLE IF EXISTS api_keys;"),
+	},
+	{
+		Version: 8,
+		Name:    "create_rate_limits",
+		Up: exec(`CREATE TABLE IF NOT EXISTS rate_limits (
+    		key TEXT PRIMARY KEY,
+    		tokens REAL NOT NULL,
+    		allowed INTEGER NOT NULL,
+    		updated_at INTEGER NOT NULL,
+    		full_at INTEGER NOT NULL
+);`),
+		Down: exec("DROP TABLE IF EXISTS rate_limits;"),
+	},
+	{
+		Version: 9,
+		Name:    "create_discount_rules",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			// AUTOINCREMENT keeps ids of deleted rules from being reused and records in sqlite_sequence that rules were
+			// stored, so the configured discounts are only seeded once
+			_, err := tx.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS discount_rules (
+    		id INTEGER PRIMARY KEY AUTOINCREMENT,
+    		kind TEXT NOT NULL,
+    		key TEXT NOT NULL,
+    		percentage REAL NOT NULL,
+    		UNIQUE (kind, key)
+);`)
+			if err != nil {
+				return err
+			}
+
+			return createCatalogVersionTriggers(ctx, tx, "discount_rules")
+		},
+		Down: func(ctx context.Context, tx *sql.Tx) error {
+			if err := dropCatalogVersionTriggers(ctx, tx, "discount_rules"); err != nil {
+				return err
+			}
+
+			_, err := tx.ExecContext(ctx, "DROP TABLE IF EXISTS discount_rules;")
+
+			return err
+		},
+	},
+	{
+		Version: 10,
+		Name:    "add_discount_rules_targets",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			var exists bool
+			err := tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM pragma_table_info('discount_rules') WHERE name = 'channel');").Scan(&exists)
+			if err != nil || exists {
+				return err
+			}
+
+			return rebuildDiscountRules(ctx, tx, `CREATE TABLE discount_rules_rebuild (
+    		id INTEGER PRIMARY KEY AUTOINCREMENT,
+    		kind TEXT NOT NULL,
+    		key TEXT NOT NULL,
+    		percentage REAL NOT NULL,
+    		channel TEXT NOT NULL DEFAULT '',
+    		segment TEXT NOT NULL DEFAULT '',
+    		country TEXT NOT NULL DEFAULT '',
+    		UNIQUE (kind, key, channel, segment, country)
+);`)
+		},
+		Down: func(ctx context.Context, tx *sql.Tx) error {
+			// rules targeting a pricing context can't be told apart from the ones granted everywhere without the columns
+			_, err := tx.ExecContext(ctx, "DELETE FROM discount_rules WHERE channel != '' OR segment != '' OR country != '';")
+			if err != nil {
+				return err
+			}
+
+			return rebuildDiscountRules(ctx, tx, `CREATE TABLE discount_rules_rebuild (
+    		id INTEGER PRIMARY KEY AUTOINCREMENT,
+    		kind TEXT NOT NULL,
+    		key TEXT NOT NULL,
+    		percentage REAL NOT NULL,
+    		UNIQUE (kind, key)
+);`)
+		},
+	},
+}
+
+// CreateProductsDatabase applies every pending migration
+func CreateProductsDatabase(db *sql.DB) error {
+	_, err := Up(context.Background(), db)
+
+	return err
+}
+
+// Up applies pending migrations in order, each one in its own transaction, and returns the applied ones
+func Up(ctx context.Context, db *sql.DB) ([]Migration, error) {
+	applied, err := appliedVersions(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	migrated := make([]Migration, 0)
+	for _, migration := range productsMigrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		err := inTransaction(ctx, db, func(tx *sql.Tx) error {
+			if err := migration.Up(ctx, tx); err != nil {
+				return err
+			}
+
+			_, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?);",
+				migration.Version, migration.Name, time.Now().UnixMilli())
+
+			return err
+		})
+		if err != nil {
+			return migrated, fmt.Errorf("applying migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+
+		migrated = append(migrated, migration)
+	}
+
+	return migrated, nil
+}
+
+// Down reverts the last steps applied migrations, newest first, and returns the reverted ones
+func Down(ctx context.Context, db *sql.DB, steps int) ([]Migration, error) {
+	applied, err := appliedVersions(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	reverted := make([]Migration, 0)
+	for i := len(productsMigrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+		migration := productsMigrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		err := inTransaction(ctx, db, func(tx *sql.Tx) error {
+			if err := migration.Down(ctx, tx); err != nil {
+				return err
+			}
+
+			_, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?;", migration.Version)
+
+			return err
+		})
+		if err != nil {
+			return reverted, fmt.Errorf("reverting migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+
+		reverted = append(reverted, migration)
+	}
+
+	return reverted, nil
+}
+
+// Status every known migration with the time it was applied, nil when it is pending
+func Status(ctx context.Context, db *sql.DB) ([]MigrationStatus, error) {
+	applied, err := appliedVersions(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(productsMigrations))
+	for _, migration := range productsMigrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Pending number of migrations not applied yet
+func Pending(ctx context.Context, db *sql.DB) (int, error) {
+	statuses, err := Status(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+
+	pending := 0
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending++
+		}
+	}
+
+	return pending, nil
+}
+
+var ErrPendingMigrations = errors.New("pending migrations")
+
+// CheckProductsDatabase fails while there are pending migrations
+func CheckProductsDatabase(ctx context.Context, db *sql.DB) error {
+	pending, err := Pending(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	if pending > 0 {
+		return fmt.Errorf("%d %w", pending, ErrPendingMigrations)
+	}
+
+	return nil
+}
+
+func appliedVersions(ctx context.Context, db *sql.DB) (map[int]time.Time, error) {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+    		version INTEGER PRIMARY KEY,
+    		name TEXT NOT NULL,
+    		applied_at INTEGER NOT NULL
+);`)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations;")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt int64
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+
+		applied[version] = time.UnixMilli(appliedAt)
+	}
+
+	return applied, rows.Err()
+}
+
+func inTransaction(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func exec(query string) func(ctx context.Context, tx *sql.Tx) error {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, query)
+
+		return err
+	}
+}
+
+// addColumnIfNotExists allows evolving tables created by previous versions since SQLite has no ADD COLUMN IF NOT EXISTS
+func addColumnIfNotExists(ctx context.Context, tx *sql.Tx, table, column, definition string) error {
+	var exists bool
+	err := tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM pragma_table_info(?) WHERE name = ?);", table, column).Scan(&exists)
+	if err != nil || exists {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;", table, column, definition))
+
+	return err
+}
+
+// rebuildDiscountRules replaces discount_rules with the table created by definition as discount_rules_rebuild, since
+// SQLite can't change the constraints of a table in place. The rules keep their ids and sqlite_sequence its value, so
+// ids of deleted rules are still not reused and the configured discounts are not seeded again
+func rebuildDiscountRules(ctx context.Context, tx *sql.Tx, definition string) error {
+	var sequence sql.NullInt64
+	err := tx.QueryRowContext(ctx, "SELECT seq FROM sqlite_sequence WHERE name = 'discount_rules';").Scan(&sequence)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	statements := []string{
+		definition,
+		"INSERT INTO discount_rules_rebuild (id, kind, key, percentage) SELECT id, kind, key, percentage FROM discount_rules;",
+		"DROP TABLE discount_rules;",
+		"ALTER TABLE discount_rules_rebuild RENAME TO discount_rules;",
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	// copying registers the rebuilt table even when there were no rules, which would look like rules were once stored
+	_, err = tx.ExecContext(ctx, "DELETE FROM sqlite_sequence WHERE name = 'discount_rules';")
+	if err != nil {
+		return err
+	}
+
+	if sequence.Valid {
+		_, err := tx.ExecContext(ctx, "INSERT INTO sqlite_sequence (name, seq) VALUES ('discount_rules', ?);", sequence.Int64)
+		if err != nil {
+			return err
+		}
+	}
+
+	return createCatalogVersionTriggers(ctx, tx, "discount_rules")
+}
@@ -0,0 +1,141 @@
+package migrations
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sharedDatabaseUtils "go-products.com/m/internal/shared/database"
+)
+
+func TestMigrations(t *testing.T) {
+	assertions := require.New(t)
+	ctx := context.Background()
+
+	db, err := sharedDatabaseUtils.GenerateDatabaseConnection(sharedDatabaseUtils.DatabaseConnection{
+		DatabaseName: filepath.Join(t.TempDir(), "products.db"),
+	}, nil)
+	assertions.NoError(err)
+	defer db.Close()
+
+	pending, err := Pending(ctx, db)
+	assertions.NoError(err)
+	assertions.Equal(len(productsMigrations), pending)
+	assertions.ErrorIs(CheckProductsDatabase(ctx, db), ErrPendingMigrations)
+
+	applied, err := Up(ctx, db)
+	assertions.NoError(err)
+	assertions.Len(applied, len(productsMigrations))
+	assertions.NoError(CheckProductsDatabase(ctx, db))
+
+	applied, err = Up(ctx, db)
+	assertions.NoError(err)
+	assertions.Empty(applied, "applied migrations are not run again")
+
+	// migrations are reverted down to the one under test, so the checks keep working as newer migrations are added
+	latest := productsMigrations[len(productsMigrations)-1].Version
+
+	// rules stored before they could target a pricing context keep their ids, and deleted ids are still not reused
+	reverted, err := Down(ctx, db, latest-10+1)
+	assertions.NoError(err)
+	assertions.Equal(versionRange(latest, 10), versions(reverted))
+	_, err = db.ExecContext(ctx, `INSERT INTO discount_rules (kind, key, percentage) VALUES ('category', 'boots', 0.3), ('sku', '000003', 0.15);
+		DELETE FROM discount_rules WHERE kind = 'sku';`)
+	assertions.NoError(err)
+
+	applied, err = Up(ctx, db)
+	assertions.NoError(err)
+	assertions.Equal(versionRange(10, latest), versions(applied))
+	_, err = db.ExecContext(ctx, "INSERT INTO discount_rules (kind, key, percentage, channel) VALUES ('category', 'boots', 0.4, 'wholesale');")
+	assertions.NoError(err)
+
+	var ids string
+	assertions.NoError(db.QueryRowContext(ctx, "SELECT GROUP_CONCAT(id || ':' || channel, ',') FROM discount_rules;").Scan(&ids))
+	assertions.Equal("1:,3:wholesale", ids)
+
+	reverted, err = Down(ctx, db, latest-9+1)
+	assertions.NoError(err)
+	assertions.Equal(versionRange(latest, 9), versions(reverted))
+
+	statuses, err := Status(ctx, db)
+	assertions.NoError(err)
+	for _, status := range statuses {
+		assertions.Equal(status.Version <= 8, status.AppliedAt != nil, "migration %d", status.Version)
+	}
+
+	applied, err = Up(ctx, db)
+	assertions.NoError(err)
+	assertions.Equal(versionRange(9, latest), versions(applied))
+
+	// products stored before price history existed get their current price as first entry
+	_, err = Down(ctx, db, latest-5+1)
+	assertions.NoError(err)
+	_, err = db.ExecContext(ctx, "INSERT INTO categories (slug, name) VALUES ('boots', 'Boots');")
+	assertions.NoError(err)
+	_, err = db.ExecContext(ctx, "INSERT INTO products (sku, name, category, price) VALUES ('000001', 'Boots', 'boots', 100);")
+	assertions.NoError(err)
+
+	applied, err = Up(ctx, db)
+	assertions.NoError(err)
+	assertions.Equal(versionRange(5, latest), versions(applied))
+
+	var history int
+	assertions.NoError(db.QueryRowContext(ctx, "SELECT COUNT(*) FROM price_history WHERE sku = '000001' AND price = 100;").Scan(&history))
+	assertions.Equal(1, history)
+
+	// reverting everything and migrating again proves every down migration undoes its up migration
+	reverted, err = Down(ctx, db, len(productsMigrations))
+	assertions.NoError(err)
+	assertions.Equal(versionRange(latest, 1), versions(reverted))
+
+	applied, err = Up(ctx, db)
+	assertions.NoError(err)
+	assertions.Len(applied, len(productsMigrations))
+}
+
+func TestCreateProductsDatabase_UntrackedDatabase(t *testing.T) {
+	assertions := require.New(t)
+	ctx := context.Background()
+
+	db, err := sharedDatabaseUtils.GenerateDatabaseConnection(sharedDatabaseUtils.DatabaseConnection{
+		DatabaseName: filepath.Join(t.TempDir(), "products.db"),
+	}, nil)
+	assertions.NoError(err)
+	defer db.Close()
+
+	// databases created before migrations were versioned already have the tables and columns
+	_, err = db.ExecContext(ctx, `CREATE TABLE categories (slug TEXT PRIMARY KEY, name TEXT NOT NULL, parent_slug TEXT);
+		CREATE TABLE products (sku TEXT PRIMARY KEY, name TEXT NOT NULL, category TEXT NOT NULL, price INTEGER NOT NULL,
+			status TEXT NOT NULL DEFAULT 'active');`)
+	assertions.NoError(err)
+
+	assertions.NoError(CreateProductsDatabase(db))
+	assertions.NoError(CheckProductsDatabase(ctx, db))
+}
+
+func versions(migrations []Migration) []int {
+	result := make([]int, 0, len(migrations))
+	for _, migration := range migrations {
+		result = append(result, migration.Version)
+	}
+
+	return result
+}
+
+// versionRange every version from first to last, counting down when first is the greater
+func versionRange(first, last int) []int {
+	step := 1
+	if first > last {
+		step = -1
+	}
+
+	result := []int{first}
+	for version := first; version != last; {
+		version += step
+		result = append(result, version)
+	}
+
+	return result
+}
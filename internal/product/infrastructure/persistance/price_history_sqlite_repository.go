@@ -0,0 +1,99 @@
+package persistance
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-products.com/m/internal/product/domain"
+	domainErrors "go-products.com/m/internal/product/domain/errors"
+)
+
+type PriceHistorySQLiteRepository struct {
+	db *sql.DB
+}
+
+var ErrGetPriceHistory = errors.New("error getting price history")
+
+func NewPriceHistorySQLiteRepository(db *sql.DB) *PriceHistorySQLiteRepository {
+	return &PriceHistorySQLiteRepository{db: db}
+}
+
+func (r *PriceHistorySQLiteRepository) GetPriceHistory(ctx context.Context, sku string) ([]domain.PriceChange, error) {
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM products WHERE sku = ?);", sku).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrGetPriceHistory, err)
+	}
+
+	if !exists {
+		return nil, domainErrors.ProductNotFound
+	}
+
+	rows, err := r.db.QueryContext(ctx, "SELECT sku, price, changed_at FROM price_history WHERE sku = ? ORDER BY changed_at, id;", sku)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrGetPriceHistory, err)
+	}
+	defer rows.Close()
+
+	history := make([]domain.PriceChange, 0)
+	for rows.Next() {
+		var change domain.PriceChange
+		var changedAt int64
+		if err := rows.Scan(&change.Sku, &change.Price, &changedAt); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrParseRow, err)
+		}
+
+		change.ChangedAt = time.UnixMilli(changedAt).UTC()
+		history = append(history, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrGetPriceHistory, err)
+	}
+
+	return history, nil
+}
+
+// GetLowestPrices lowest price per sku from since onward, including the price that was already in effect at since
+func (r *PriceHistorySQLiteRepository) GetLowestPrices(ctx context.Context, skus []string, since time.Time) (map[string]int, error) {
+	lowestPrices := make(map[string]int, len(skus))
+	if len(skus) == 0 {
+		return lowestPrices, nil
+	}
+
+	params := []interface{}{since.UnixMilli(), since.UnixMilli()}
+	for _, sku := range skus {
+		params = append(params, sku)
+	}
+
+	query := `SELECT sku, MIN(price) FROM price_history AS history
+		WHERE (changed_at >= ? OR changed_at = (
+			SELECT MAX(changed_at) FROM price_history WHERE sku = history.sku AND changed_at < ?
+		)) AND sku IN (?` + strings.Repeat(", ?", len(skus)-1) + `)
+		GROUP BY sku;`
+
+	rows, err := r.db.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrGetPriceHistory, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sku string
+		var price int
+		if err := rows.Scan(&sku, &price); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrParseRow, err)
+		}
+
+		lowestPrices[sku] = price
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrGetPriceHistory, err)
+	}
+
+	return lowestPrices, nil
+}
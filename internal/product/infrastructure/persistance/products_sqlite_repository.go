@@ -4,9 +4,14 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"log/slog"
 	"strings"
+	"time"
 
 	"go-products.com/m/internal/product/domain"
+	domainErrors "go-products.com/m/internal/product/domain/errors"
+	"go-products.com/m/internal/shared/logging"
 )
 
 type ProductsSQLiteRepository struct {
@@ -23,66 +28,225 @@ func NewProductsSQLiteRepository(db *sql.DB) *ProductsSQLiteRepository {
 }
 
 func (r *ProductsSQLiteRepository) GetProducts(ctx context.Context, filters domain.ProductsFilters) ([]domain.Product, error) {
+	start := time.Now()
+	logger := logging.FromContext(ctx).With(slog.String("filters", filtersCacheKey(filters)))
+
 	query, params := getQuery(filters)
 	rows, err := r.db.QueryContext(ctx, query, params...)
 	if err != nil {
-		return nil, ErrGetProducts
+		return nil, fmt.Errorf("%w: %w", ErrGetProducts, err)
 	}
 	defer rows.Close()
 
 	products := make([]domain.Product, 0)
 	for rows.Next() {
-		var product domain.Product
-		if err := rows.Scan(&product.Sku, &product.Name, &product.Category, &product.Price); err != nil {
-			return nil, ErrParseRow
-		}
-
-		validatedProduct, err := domain.NewProduct(product.Sku, product.Name, product.Category, product.Price)
+		product, err := scanProduct(rows)
 		if err != nil {
 			return nil, err
 		}
 
-		products = append(products, *validatedProduct)
+		products = append(products, *product)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrGetProducts, err)
+	}
+
+	logger.Debug("products fetched", slog.Int("rows", len(products)), slog.Duration("duration", time.Since(start)))
+
 	return products, nil
 }
 
+func (r *ProductsSQLiteRepository) GetProduct(ctx context.Context, sku string) (*domain.Product, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT sku, name, category, price, status, version FROM products WHERE sku = ?;", sku)
+
+	product, err := scanProduct(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domainErrors.ProductNotFound
+	}
+
+	return product, err
+}
+
 func (r *ProductsSQLiteRepository) CreateProduct(ctx context.Context, product domain.CreateProductDTO) error {
 	domainProduct, err := domain.NewProduct(product.Sku, product.Name, product.Category, product.Price)
 	if err != nil {
 		return err
 	}
 
-	_, err = r.db.ExecContext(ctx, "INSERT INTO products (sku, name, category, price) VALUES (?, ?, ?, ?);", domainProduct.Sku, domainProduct.Name, domainProduct.Category, domainProduct.Price)
+	if product.Status != "" {
+		if domainProduct.Status, err = domain.ParseProductStatus(product.Status); err != nil {
+			return domainErrors.NewInvalidField("status", err)
+		}
+	}
+
+	exists, err := categoryExists(ctx, r.db, domainProduct.Category)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return domainErrors.NewInvalidField("category", domainErrors.NewUnknownCategory(domainProduct.Category))
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO products (sku, name, category, price, status) VALUES (?, ?, ?, ?, ?);", domainProduct.Sku, domainProduct.Name, domainProduct.Category, domainProduct.Price, domainProduct.Status)
+	if err != nil {
+		return err
+	}
+
+	if err := recordPriceChange(ctx, tx, domainProduct.Sku, domainProduct.Price); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	logging.FromContext(ctx).Debug("product created", slog.String("sku", domainProduct.Sku), slog.String("status", string(domainProduct.Status)))
+
+	return nil
+}
+
+// UpdateProduct price changes are recorded in the price history within the same transaction as the update
+func (r *ProductsSQLiteRepository) UpdateProduct(ctx context.Context, product *domain.Product) error {
+	exists, err := categoryExists(ctx, r.db, product.Category)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return domainErrors.NewInvalidField("category", domainErrors.NewUnknownCategory(product.Category))
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var currentPrice int
+	err = tx.QueryRowContext(ctx, "SELECT price FROM products WHERE sku = ?;", product.Sku).Scan(&currentPrice)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domainErrors.ProductNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, "UPDATE products SET name = ?, category = ?, price = ?, status = ?, version = version + 1 WHERE sku = ? AND version = ?;",
+		product.Name, product.Category, product.Price, product.Status, product.Sku, product.Version)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return domainErrors.VersionMismatch
+	}
+
+	if currentPrice != product.Price {
+		if err := recordPriceChange(ctx, tx, product.Sku, product.Price); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	product.Version++
+	logging.FromContext(ctx).Debug("product updated",
+		slog.String("sku", product.Sku),
+		slog.Int("version", product.Version),
+		slog.Bool("price_changed", currentPrice != product.Price),
+	)
+
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanProduct(row rowScanner) (*domain.Product, error) {
+	var product domain.Product
+	var status string
+	if err := row.Scan(&product.Sku, &product.Name, &product.Category, &product.Price, &status, &product.Version); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("%w: %w", ErrParseRow, err)
+	}
+
+	// stored products breaking the domain rules are corrupted data, not something the client can fix, so the
+	// validation error is kept in the message only
+	validatedProduct, err := domain.NewProduct(product.Sku, product.Name, product.Category, product.Price)
+	if err != nil {
+		return nil, fmt.Errorf("%w: product %s: %v", ErrParseRow, product.Sku, err)
+	}
+
+	if validatedProduct.Status, err = domain.ParseProductStatus(status); err != nil {
+		return nil, fmt.Errorf("%w: product %s: %v", ErrParseRow, product.Sku, err)
+	}
+
+	validatedProduct.Version = product.Version
+
+	return validatedProduct, nil
+}
+
+func recordPriceChange(ctx context.Context, tx *sql.Tx, sku string, price int) error {
+	_, err := tx.ExecContext(ctx, "INSERT INTO price_history (sku, price, changed_at) VALUES (?, ?, ?);", sku, price, time.Now().UnixMilli())
 
 	return err
 }
 
+// getQuery category filter walks down the categories tree so filtering by a parent category includes products of its descendants
 func getQuery(filters domain.ProductsFilters) (string, []interface{}) {
 	params := []interface{}{}
 
 	query := strings.Builder{}
-	query.WriteString("SELECT sku, name, category, price FROM products")
-
-	if filters.Category != nil || filters.PriceLessThan != nil {
-		query.WriteString(" WHERE")
-	}
-
 	if filters.Category != nil {
-		query.WriteString(" category = ?")
+		query.WriteString(`WITH RECURSIVE category_tree(slug) AS (
+			SELECT slug FROM categories WHERE slug = ?
+			UNION ALL
+			SELECT categories.slug FROM categories JOIN category_tree ON categories.parent_slug = category_tree.slug
+		) `)
 		params = append(params, *filters.Category)
 	}
 
-	if filters.Category != nil && filters.PriceLessThan != nil {
-		query.WriteString(" AND")
+	query.WriteString("SELECT sku, name, category, price, status, version FROM products")
+
+	conditions := make([]string, 0)
+	if filters.Category != nil {
+		conditions = append(conditions, "category IN (SELECT slug FROM category_tree)")
 	}
 
 	if filters.PriceLessThan != nil {
-		query.WriteString(" price <= ?")
+		conditions = append(conditions, "price <= ?")
 		params = append(params, *filters.PriceLessThan)
 	}
 
+	if filters.Status != nil {
+		conditions = append(conditions, "status = ?")
+		params = append(params, *filters.Status)
+	}
+
+	if len(conditions) > 0 {
+		query.WriteString(" WHERE " + strings.Join(conditions, " AND "))
+	}
+
 	if filters.Limit != nil {
 		query.WriteString(" LIMIT ?")
 		params = append(params, *filters.Limit)
@@ -0,0 +1,26 @@
+package use_cases
+
+import (
+	"context"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/shared/tracing"
+)
+
+type ChangeProductStatusUseCase struct {
+	productRepository domain.ProductRepository
+}
+
+func NewChangeProductStatusUseCase(productRepository domain.ProductRepository) ChangeProductStatusUseCase {
+	return ChangeProductStatusUseCase{productRepository: productRepository}
+}
+
+func (u ChangeProductStatusUseCase) Execute(ctx context.Context, sku string, status domain.ProductStatus, expectedVersion int) (*domain.Product, error) {
+	ctx, span := tracing.Start(ctx, "ChangeProductStatusUseCase.Execute")
+	product, err := updateProduct(ctx, u.productRepository, sku, expectedVersion, func(product *domain.Product) error {
+		return product.TransitionTo(status)
+	})
+	tracing.End(span, err)
+
+	return product, err
+}
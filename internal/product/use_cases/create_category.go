@@ -0,0 +1,35 @@
+package use_cases
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/shared/tracing"
+)
+
+type CreateCategoryUseCase struct {
+	categoryRepository domain.CategoryRepository
+}
+
+func NewCreateCategoryUseCase(categoryRepository domain.CategoryRepository) CreateCategoryUseCase {
+	return CreateCategoryUseCase{categoryRepository: categoryRepository}
+}
+
+func (u CreateCategoryUseCase) Execute(ctx context.Context, category domain.CreateCategoryDTO) (_ *domain.Category, err error) {
+	ctx, span := tracing.Start(ctx, "CreateCategoryUseCase.Execute", trace.WithAttributes(attribute.String("category.slug", category.Slug)))
+	defer func() { tracing.End(span, err) }()
+
+	domainCategory, err := domain.NewCategory(category.Slug, category.Name, category.Parent)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.categoryRepository.CreateCategory(ctx, category); err != nil {
+		return nil, err
+	}
+
+	return domainCategory, nil
+}
@@ -0,0 +1,32 @@
+package use_cases
+
+import (
+	"context"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/shared/tracing"
+)
+
+type CreateDiscountRuleUseCase struct {
+	discountRuleRepository domain.DiscountRuleRepository
+}
+
+func NewCreateDiscountRuleUseCase(discountRuleRepository domain.DiscountRuleRepository) CreateDiscountRuleUseCase {
+	return CreateDiscountRuleUseCase{discountRuleRepository: discountRuleRepository}
+}
+
+func (u CreateDiscountRuleUseCase) Execute(ctx context.Context, rule domain.DiscountRuleDTO) (_ *domain.DiscountRule, err error) {
+	ctx, span := tracing.Start(ctx, "CreateDiscountRuleUseCase.Execute")
+	defer func() { tracing.End(span, err) }()
+
+	domainRule, err := domain.NewDiscountRule(rule.Kind, rule.Key, rule.Percentage, rule.Target())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.discountRuleRepository.CreateDiscountRule(ctx, domainRule); err != nil {
+		return nil, err
+	}
+
+	return domainRule, nil
+}
@@ -0,0 +1,24 @@
+package use_cases
+
+import (
+	"context"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/shared/tracing"
+)
+
+type DeleteDiscountRuleUseCase struct {
+	discountRuleRepository domain.DiscountRuleRepository
+}
+
+func NewDeleteDiscountRuleUseCase(discountRuleRepository domain.DiscountRuleRepository) DeleteDiscountRuleUseCase {
+	return DeleteDiscountRuleUseCase{discountRuleRepository: discountRuleRepository}
+}
+
+func (u DeleteDiscountRuleUseCase) Execute(ctx context.Context, id int) error {
+	ctx, span := tracing.Start(ctx, "DeleteDiscountRuleUseCase.Execute")
+	err := u.discountRuleRepository.DeleteDiscountRule(ctx, id)
+	tracing.End(span, err)
+
+	return err
+}
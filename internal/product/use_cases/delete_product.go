@@ -0,0 +1,27 @@
+package use_cases
+
+import (
+	"context"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/shared/tracing"
+)
+
+// DeleteProductUseCase products are soft deleted by archiving them so they can be restored
+type DeleteProductUseCase struct {
+	productRepository domain.ProductRepository
+}
+
+func NewDeleteProductUseCase(productRepository domain.ProductRepository) DeleteProductUseCase {
+	return DeleteProductUseCase{productRepository: productRepository}
+}
+
+func (u DeleteProductUseCase) Execute(ctx context.Context, sku string, expectedVersion int) (*domain.Product, error) {
+	ctx, span := tracing.Start(ctx, "DeleteProductUseCase.Execute")
+	product, err := updateProduct(ctx, u.productRepository, sku, expectedVersion, func(product *domain.Product) error {
+		return product.Archive()
+	})
+	tracing.End(span, err)
+
+	return product, err
+}
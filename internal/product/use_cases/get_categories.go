@@ -0,0 +1,24 @@
+package use_cases
+
+import (
+	"context"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/shared/tracing"
+)
+
+type GetCategoriesUseCase struct {
+	categoryRepository domain.CategoryRepository
+}
+
+func NewGetCategoriesUseCase(categoryRepository domain.CategoryRepository) GetCategoriesUseCase {
+	return GetCategoriesUseCase{categoryRepository: categoryRepository}
+}
+
+func (u GetCategoriesUseCase) Execute(ctx context.Context) ([]domain.Category, error) {
+	ctx, span := tracing.Start(ctx, "GetCategoriesUseCase.Execute")
+	categories, err := u.categoryRepository.GetCategories(ctx)
+	tracing.End(span, err)
+
+	return categories, err
+}
@@ -0,0 +1,28 @@
+package use_cases
+
+import (
+	"context"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/shared/tracing"
+)
+
+// GetDiscountPolicyUseCase builds the policy from the stored discount rules, so rule changes apply at once
+type GetDiscountPolicyUseCase struct {
+	discountRuleRepository domain.DiscountRuleRepository
+}
+
+func NewGetDiscountPolicyUseCase(discountRuleRepository domain.DiscountRuleRepository) GetDiscountPolicyUseCase {
+	return GetDiscountPolicyUseCase{discountRuleRepository: discountRuleRepository}
+}
+
+func (u GetDiscountPolicyUseCase) Execute(ctx context.Context) (domain.DiscountPolicy, error) {
+	ctx, span := tracing.Start(ctx, "GetDiscountPolicyUseCase.Execute")
+	rules, err := u.discountRuleRepository.GetDiscountRules(ctx)
+	tracing.End(span, err)
+	if err != nil {
+		return domain.DiscountPolicy{}, err
+	}
+
+	return domain.NewDiscountPolicy(rules), nil
+}
@@ -0,0 +1,24 @@
+package use_cases
+
+import (
+	"context"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/shared/tracing"
+)
+
+type GetDiscountRuleUseCase struct {
+	discountRuleRepository domain.DiscountRuleRepository
+}
+
+func NewGetDiscountRuleUseCase(discountRuleRepository domain.DiscountRuleRepository) GetDiscountRuleUseCase {
+	return GetDiscountRuleUseCase{discountRuleRepository: discountRuleRepository}
+}
+
+func (u GetDiscountRuleUseCase) Execute(ctx context.Context, id int) (*domain.DiscountRule, error) {
+	ctx, span := tracing.Start(ctx, "GetDiscountRuleUseCase.Execute")
+	rule, err := u.discountRuleRepository.GetDiscountRule(ctx, id)
+	tracing.End(span, err)
+
+	return rule, err
+}
@@ -0,0 +1,24 @@
+package use_cases
+
+import (
+	"context"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/shared/tracing"
+)
+
+type GetDiscountRulesUseCase struct {
+	discountRuleRepository domain.DiscountRuleRepository
+}
+
+func NewGetDiscountRulesUseCase(discountRuleRepository domain.DiscountRuleRepository) GetDiscountRulesUseCase {
+	return GetDiscountRulesUseCase{discountRuleRepository: discountRuleRepository}
+}
+
+func (u GetDiscountRulesUseCase) Execute(ctx context.Context) ([]domain.DiscountRule, error) {
+	ctx, span := tracing.Start(ctx, "GetDiscountRulesUseCase.Execute")
+	rules, err := u.discountRuleRepository.GetDiscountRules(ctx)
+	tracing.End(span, err)
+
+	return rules, err
+}
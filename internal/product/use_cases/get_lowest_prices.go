@@ -0,0 +1,26 @@
+package use_cases
+
+import (
+	"context"
+	"time"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/shared/tracing"
+)
+
+type GetLowestPricesUseCase struct {
+	priceHistoryRepository domain.PriceHistoryRepository
+}
+
+func NewGetLowestPricesUseCase(priceHistoryRepository domain.PriceHistoryRepository) GetLowestPricesUseCase {
+	return GetLowestPricesUseCase{priceHistoryRepository: priceHistoryRepository}
+}
+
+// Execute lowest price of every product within domain.LowestPriceWindow keyed by sku
+func (u GetLowestPricesUseCase) Execute(ctx context.Context, skus []string) (map[string]int, error) {
+	ctx, span := tracing.Start(ctx, "GetLowestPricesUseCase.Execute")
+	lowestPrices, err := u.priceHistoryRepository.GetLowestPrices(ctx, skus, time.Now().Add(-domain.LowestPriceWindow))
+	tracing.End(span, err)
+
+	return lowestPrices, err
+}
@@ -0,0 +1,42 @@
+package use_cases
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/shared/tracing"
+)
+
+type PriceHistory struct {
+	Sku                 string
+	Changes             []domain.PriceChange
+	LowestPriceInWindow *int
+}
+
+type GetPriceHistoryUseCase struct {
+	priceHistoryRepository domain.PriceHistoryRepository
+}
+
+func NewGetPriceHistoryUseCase(priceHistoryRepository domain.PriceHistoryRepository) GetPriceHistoryUseCase {
+	return GetPriceHistoryUseCase{priceHistoryRepository: priceHistoryRepository}
+}
+
+func (u GetPriceHistoryUseCase) Execute(ctx context.Context, sku string) (_ PriceHistory, err error) {
+	ctx, span := tracing.Start(ctx, "GetPriceHistoryUseCase.Execute", trace.WithAttributes(attribute.String("product.sku", sku)))
+	defer func() { tracing.End(span, err) }()
+
+	changes, err := u.priceHistoryRepository.GetPriceHistory(ctx, sku)
+	if err != nil {
+		return PriceHistory{}, err
+	}
+
+	return PriceHistory{
+		Sku:                 sku,
+		Changes:             changes,
+		LowestPriceInWindow: domain.LowestPriceSince(changes, time.Now().Add(-domain.LowestPriceWindow)),
+	}, nil
+}
@@ -0,0 +1,38 @@
+package use_cases
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/shared/tracing"
+)
+
+type GetProductUseCase struct {
+	productRepository domain.ProductRepository
+	discounts         domain.DiscountPolicySource
+}
+
+func NewGetProductUseCase(productRepository domain.ProductRepository, discounts domain.DiscountPolicySource) GetProductUseCase {
+	return GetProductUseCase{productRepository: productRepository, discounts: discounts}
+}
+
+// Execute the product with the discount it gets in the pricing context
+func (u GetProductUseCase) Execute(ctx context.Context, sku string, pricing domain.PricingContext) (*domain.PricedProduct, error) {
+	ctx, span := tracing.Start(ctx, "GetProductUseCase.Execute", trace.WithAttributes(attribute.String("product.sku", sku)))
+	product, err := u.productRepository.GetProduct(ctx, sku)
+
+	var priced []domain.PricedProduct
+	if err == nil {
+		priced, err = priceProducts(ctx, u.discounts, []domain.Product{*product}, pricing)
+	}
+
+	tracing.End(span, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return &priced[0], nil
+}
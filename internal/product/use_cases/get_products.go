@@ -3,17 +3,47 @@ package use_cases
 import (
 	"context"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/shared/tracing"
 )
 
 type GetProductsUseCase struct {
 	productRepository domain.ProductRepository
+	discounts         domain.DiscountPolicySource
+}
+
+func NewGetProductsUseCase(productRepository domain.ProductRepository, discounts domain.DiscountPolicySource) GetProductsUseCase {
+	return GetProductsUseCase{productRepository: productRepository, discounts: discounts}
 }
 
-func NewGetProductsUseCase(productRepository domain.ProductRepository) GetProductsUseCase {
-	return GetProductsUseCase{productRepository: productRepository}
+// Execute the products matching filters with the discount they get in the pricing context
+func (u GetProductsUseCase) Execute(ctx context.Context, filters domain.ProductsFilters, pricing domain.PricingContext) ([]domain.PricedProduct, error) {
+	ctx, span := tracing.Start(ctx, "GetProductsUseCase.Execute")
+	products, err := u.productRepository.GetProducts(ctx, filters)
+	span.SetAttributes(attribute.Int("products.count", len(products)))
+
+	var priced []domain.PricedProduct
+	if err == nil {
+		priced, err = priceProducts(ctx, u.discounts, products, pricing)
+	}
+
+	tracing.End(span, err)
+
+	return priced, err
 }
 
-func (u GetProductsUseCase) Execute(ctx context.Context, filters domain.ProductsFilters) ([]domain.Product, error) {
-	return u.productRepository.GetProducts(ctx, filters)
+// priceProducts applies the current policy of discounts to products
+func priceProducts(ctx context.Context, discounts domain.DiscountPolicySource, products []domain.Product, pricing domain.PricingContext) ([]domain.PricedProduct, error) {
+	policy, err := discounts.GetDiscountPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, span := tracing.Start(ctx, "discounts.apply", trace.WithAttributes(attribute.Int("products.count", len(products))))
+	defer span.End()
+
+	return domain.PriceProducts(products, policy, pricing), nil
 }
@@ -0,0 +1,26 @@
+package use_cases
+
+import (
+	"context"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/shared/tracing"
+)
+
+type RestoreProductUseCase struct {
+	productRepository domain.ProductRepository
+}
+
+func NewRestoreProductUseCase(productRepository domain.ProductRepository) RestoreProductUseCase {
+	return RestoreProductUseCase{productRepository: productRepository}
+}
+
+func (u RestoreProductUseCase) Execute(ctx context.Context, sku string, expectedVersion int) (*domain.Product, error) {
+	ctx, span := tracing.Start(ctx, "RestoreProductUseCase.Execute")
+	product, err := updateProduct(ctx, u.productRepository, sku, expectedVersion, func(product *domain.Product) error {
+		return product.Restore()
+	})
+	tracing.End(span, err)
+
+	return product, err
+}
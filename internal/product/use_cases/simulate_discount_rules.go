@@ -0,0 +1,50 @@
+package use_cases
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/shared/tracing"
+)
+
+// SimulateDiscountRulesUseCase dry run of a rule set replacing the stored one, nothing is persisted
+type SimulateDiscountRulesUseCase struct {
+	productRepository      domain.ProductRepository
+	discountRuleRepository domain.DiscountRuleRepository
+}
+
+func NewSimulateDiscountRulesUseCase(productRepository domain.ProductRepository, discountRuleRepository domain.DiscountRuleRepository) SimulateDiscountRulesUseCase {
+	return SimulateDiscountRulesUseCase{productRepository: productRepository, discountRuleRepository: discountRuleRepository}
+}
+
+// Execute compares the final prices of active products, the ones customers see, under the stored and proposed rules for
+// customers in the pricing context
+func (u SimulateDiscountRulesUseCase) Execute(ctx context.Context, proposed []domain.DiscountRuleDTO, pricing domain.PricingContext) (_ domain.DiscountSimulation, err error) {
+	ctx, span := tracing.Start(ctx, "SimulateDiscountRulesUseCase.Execute", trace.WithAttributes(attribute.Int("discount_rules.count", len(proposed))))
+	defer func() { tracing.End(span, err) }()
+
+	proposedRules := make([]domain.DiscountRule, 0, len(proposed))
+	for _, rule := range proposed {
+		proposedRules = append(proposedRules, domain.DiscountRule{Kind: rule.Kind, Key: rule.Key, Percentage: rule.Percentage, Target: rule.Target()})
+	}
+
+	if err := domain.ValidateDiscountRules(proposedRules); err != nil {
+		return domain.DiscountSimulation{}, err
+	}
+
+	currentRules, err := u.discountRuleRepository.GetDiscountRules(ctx)
+	if err != nil {
+		return domain.DiscountSimulation{}, err
+	}
+
+	active := domain.ProductStatusActive
+	products, err := u.productRepository.GetProducts(ctx, domain.ProductsFilters{Status: &active})
+	if err != nil {
+		return domain.DiscountSimulation{}, err
+	}
+
+	return domain.SimulateDiscounts(products, domain.NewDiscountPolicy(currentRules), domain.NewDiscountPolicy(proposedRules), pricing)
+}
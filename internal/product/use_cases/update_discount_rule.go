@@ -0,0 +1,36 @@
+package use_cases
+
+import (
+	"context"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/shared/tracing"
+)
+
+type UpdateDiscountRuleUseCase struct {
+	discountRuleRepository domain.DiscountRuleRepository
+}
+
+func NewUpdateDiscountRuleUseCase(discountRuleRepository domain.DiscountRuleRepository) UpdateDiscountRuleUseCase {
+	return UpdateDiscountRuleUseCase{discountRuleRepository: discountRuleRepository}
+}
+
+func (u UpdateDiscountRuleUseCase) Execute(ctx context.Context, id int, changes domain.DiscountRuleDTO) (_ *domain.DiscountRule, err error) {
+	ctx, span := tracing.Start(ctx, "UpdateDiscountRuleUseCase.Execute")
+	defer func() { tracing.End(span, err) }()
+
+	rule, err := u.discountRuleRepository.GetDiscountRule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rule.Change(changes.Kind, changes.Key, changes.Percentage, changes.Target()); err != nil {
+		return nil, err
+	}
+
+	if err := u.discountRuleRepository.UpdateDiscountRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
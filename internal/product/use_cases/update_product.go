@@ -0,0 +1,63 @@
+package use_cases
+
+import (
+	"context"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/shared/cache"
+	"go-products.com/m/internal/shared/tracing"
+)
+
+type UpdateProductUseCase struct {
+	productRepository domain.ProductRepository
+}
+
+func NewUpdateProductUseCase(productRepository domain.ProductRepository) UpdateProductUseCase {
+	return UpdateProductUseCase{productRepository: productRepository}
+}
+
+func (u UpdateProductUseCase) Execute(ctx context.Context, sku string, changes domain.UpdateProductDTO, expectedVersion int) (*domain.Product, error) {
+	ctx, span := tracing.Start(ctx, "UpdateProductUseCase.Execute")
+	product, err := updateProduct(ctx, u.productRepository, sku, expectedVersion, func(product *domain.Product) error {
+		name, category, price := product.Name, product.Category, product.Price
+		if changes.Name != nil {
+			name = *changes.Name
+		}
+
+		if changes.Category != nil {
+			category = *changes.Category
+		}
+
+		if changes.Price != nil {
+			price = *changes.Price
+		}
+
+		return product.Update(name, category, price)
+	})
+	tracing.End(span, err)
+
+	return product, err
+}
+
+// updateProduct loads the product, applies the change and saves it as long as nobody else modified it since expectedVersion
+func updateProduct(ctx context.Context, productRepository domain.ProductRepository, sku string, expectedVersion int, change func(product *domain.Product) error) (*domain.Product, error) {
+	// a cached product could be older than expectedVersion and fail a write based on the current one
+	product, err := productRepository.GetProduct(cache.Bypass(ctx), sku)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := product.CheckVersion(expectedVersion); err != nil {
+		return nil, err
+	}
+
+	if err := change(product); err != nil {
+		return nil, err
+	}
+
+	if err := productRepository.UpdateProduct(ctx, product); err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
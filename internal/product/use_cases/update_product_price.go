@@ -0,0 +1,26 @@
+package use_cases
+
+import (
+	"context"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/shared/tracing"
+)
+
+type UpdateProductPriceUseCase struct {
+	productRepository domain.ProductRepository
+}
+
+func NewUpdateProductPriceUseCase(productRepository domain.ProductRepository) UpdateProductPriceUseCase {
+	return UpdateProductPriceUseCase{productRepository: productRepository}
+}
+
+func (u UpdateProductPriceUseCase) Execute(ctx context.Context, sku string, price int, expectedVersion int) (*domain.Product, error) {
+	ctx, span := tracing.Start(ctx, "UpdateProductPriceUseCase.Execute")
+	product, err := updateProduct(ctx, u.productRepository, sku, expectedVersion, func(product *domain.Product) error {
+		return product.ChangePrice(price)
+	})
+	tracing.End(span, err)
+
+	return product, err
+}
@@ -1,18 +1,175 @@
 package internal
 
 import (
+	"log/slog"
 	"net/http"
+	"time"
 
 	"go-products.com/m/internal/product/domain"
 	"go-products.com/m/internal/product/infrastructure/handler"
+	"go-products.com/m/internal/product/infrastructure/persistance"
 	"go-products.com/m/internal/shared/api"
+	"go-products.com/m/internal/shared/auth"
+	"go-products.com/m/internal/shared/health"
+	"go-products.com/m/internal/shared/metrics"
+	"go-products.com/m/internal/shared/ratelimit"
 )
 
-func SetupServer(productsRepository domain.ProductRepository) http.Handler {
-	router := http.NewServeMux()
+type ServerDependencies struct {
+	ProductsRepository       domain.ProductRepository
+	CategoriesRepository     domain.CategoryRepository
+	PriceHistoryRepository   domain.PriceHistoryRepository
+	CatalogVersionRepository domain.CatalogVersionRepository
+	CachePolicy              api.CachePolicy
+	ProductsCache            persistance.CacheOptions
+	Catalog                  handler.CatalogOptions
+	RequestTimeout           time.Duration
+	CORS                     api.CORSOptions
+	Logger                   *slog.Logger
+	// DiscountRulesRepository when set prices are computed with the stored rules instead of Catalog.Discounts
+	DiscountRulesRepository domain.DiscountRuleRepository
+	// ErrorFormat api.ErrorFormatProblem answers every error as a problem document, ErrorResponse is used otherwise
+	ErrorFormat string
+	// Authenticator resolves bearer tokens, routes requiring a role reject every request when nil
+	Authenticator auth.Authenticator
+	// PricingAttributes principals resolved by Authenticator may carry pricing attributes winning over the headers
+	PricingAttributes bool
+	// RateLimitStore keeps the rate limit buckets, API routes are not limited when nil
+	RateLimitStore ratelimit.Store
+	RateLimits     api.RateLimitPolicy
+	// Metrics registry served at /metrics, metrics.Default when nil
+	Metrics *metrics.Registry
+	// Readiness checks reported at /readyz, the service is always ready when nil. API routes are not gated by them,
+	// orchestrators keep traffic away until the checks pass and direct callers get the catalog seeded so far
+	Readiness *health.Checks
+	// Seed is opened once the catalog has been seeded, cached queries answered while seeding are dropped then
+	Seed *health.Gate
+	// Shutdown is closed when the server stops, background work started for it ends then
+	Shutdown <-chan struct{}
+}
+
+func SetupServer(dependencies ServerDependencies) http.Handler {
+	registry := dependencies.Metrics
+	if registry == nil {
+		registry = metrics.Default
+	}
+
+	router := api.NewRouter(
+		api.Metrics(registry),
+		api.RequestID(),
+		api.Tracing(),
+		api.Logger(dependencies.Logger),
+		api.AccessLog(),
+		api.ErrorFormat(dependencies.ErrorFormat),
+		api.Recover(),
+		api.CORS(dependencies.CORS),
+		api.RateLimitAuthentication(dependencies.RateLimitStore, dependencies.RateLimits),
+		api.Authenticate(dependencies.Authenticator),
+		api.Gzip(),
+		api.Timeout(dependencies.RequestTimeout),
+	)
+
+	queryMetrics := persistance.NewQueryMetrics(registry)
+	var productsRepository domain.ProductRepository = persistance.NewInstrumentedProductsRepository(dependencies.ProductsRepository, queryMetrics)
+	if dependencies.ProductsCache.Size > 0 {
+		cacheOptions := dependencies.ProductsCache
+		cacheOptions.CatalogVersion = dependencies.CatalogVersionRepository
+		cachedProductsRepository := persistance.NewCachedProductsRepository(productsRepository, cacheOptions)
+		registerCacheMetrics(registry, cachedProductsRepository)
+		if dependencies.Seed != nil {
+			go func() {
+				select {
+				case <-dependencies.Seed.Done():
+					cachedProductsRepository.Invalidate()
+				case <-dependencies.Shutdown:
+				}
+			}()
+		}
+
+		productsRepository = cachedProductsRepository
+	}
+
+	priceHistoryRepository := persistance.NewInstrumentedPriceHistoryRepository(dependencies.PriceHistoryRepository, queryMetrics)
+	categoriesRepository := persistance.NewInstrumentedCategoriesRepository(dependencies.CategoriesRepository, queryMetrics)
+
+	catalog := dependencies.Catalog
+	catalog.Metrics = handler.NewCatalogMetrics(registry)
+	var discountRulesRepository domain.DiscountRuleRepository
+	if dependencies.DiscountRulesRepository != nil {
+		discountRulesRepository = persistance.NewInstrumentedDiscountRulesRepository(dependencies.DiscountRulesRepository, queryMetrics)
+		catalog.DiscountRules = discountRulesRepository
+		catalog.CatalogVersion = dependencies.CatalogVersionRepository
+	}
+
+	catalogCache := api.Cache(dependencies.CachePolicy, handler.CatalogVersion(dependencies.CatalogVersionRepository))
+	responseCache := api.Cache(dependencies.CachePolicy, nil)
+	// prices depend on who asks, so the pricing context is resolved before caches compute their tags
+	pricing := handler.Pricing(dependencies.PricingAttributes)
+
+	// probes and metrics are left out so scrapers and orchestrators are never limited and keep their own formats
+	v1Middlewares := make([]api.Middleware, 0, 2)
+	if dependencies.RateLimitStore != nil {
+		v1Middlewares = append(v1Middlewares, api.RateLimit(dependencies.RateLimitStore, dependencies.RateLimits))
+	}
 
-	router.HandleFunc("/api/v1/products", api.Method(http.MethodGet, handler.HandleGetProducts(productsRepository)))
+	v1 := router.With(append(v1Middlewares, api.Negotiate(api.DefaultEncoders...))...)
+
+	v1.HandleFunc(http.MethodGet, "/api/v1/products", handler.HandleGetProducts(productsRepository, priceHistoryRepository, categoriesRepository, catalog), pricing, catalogCache)
+	v1.HandleFunc(http.MethodGet, "/api/v1/products/{sku}", handler.HandleGetProduct(productsRepository, priceHistoryRepository, categoriesRepository, catalog), pricing, responseCache)
+	v1.HandleFunc(http.MethodGet, "/api/v1/products/{sku}/price-history", handler.HandleGetPriceHistory(priceHistoryRepository), catalogCache)
+	v1.HandleFunc(http.MethodGet, "/api/v1/categories", handler.HandleGetCategories(categoriesRepository), catalogCache)
+
+	editor := v1.With(api.Audit(), api.RequireRole(auth.RoleEditor))
+	editor.HandleFunc(http.MethodPut, "/api/v1/products/{sku}", handler.HandleUpdateProduct(productsRepository))
+	editor.HandleFunc(http.MethodPatch, "/api/v1/products/{sku}", handler.HandlePatchProduct(productsRepository))
+	editor.HandleFunc(http.MethodPut, "/api/v1/products/{sku}/status", handler.HandleChangeProductStatus(productsRepository))
+	editor.HandleFunc(http.MethodPut, "/api/v1/products/{sku}/price", handler.HandleUpdateProductPrice(productsRepository))
+	editor.HandleFunc(http.MethodPost, "/api/v1/categories", handler.HandleCreateCategory(categoriesRepository))
+	if discountRulesRepository != nil {
+		editor.HandleFunc(http.MethodPost, "/api/v1/discounts", handler.HandleCreateDiscountRule(discountRulesRepository))
+		editor.HandleFunc(http.MethodPut, "/api/v1/discounts/{id}", handler.HandleUpdateDiscountRule(discountRulesRepository))
+	}
+
+	destructive := v1.With(api.Audit(), api.RequireRole(auth.RoleAdmin))
+	destructive.HandleFunc(http.MethodDelete, "/api/v1/products/{sku}", handler.HandleDeleteProduct(productsRepository))
+	destructive.HandleFunc(http.MethodPost, "/api/v1/products/{sku}/restore", handler.HandleRestoreProduct(productsRepository))
+	if discountRulesRepository != nil {
+		destructive.HandleFunc(http.MethodDelete, "/api/v1/discounts/{id}", handler.HandleDeleteDiscountRule(discountRulesRepository))
+	}
+
+	readiness := dependencies.Readiness
+	if readiness == nil {
+		readiness = health.NewChecks()
+	}
+
+	router.HandleFunc(http.MethodGet, "/healthz", health.LivenessHandler())
+	router.HandleFunc(http.MethodGet, "/readyz", health.ReadinessHandler(readiness))
+	router.HandleFunc(http.MethodGet, "/metrics", metrics.Handler(registry))
+
+	// draft and archived products are only listed to admins, they are not meant to be seen before they are published
+	admin := v1.With(api.RequireRole(auth.RoleAdmin))
+	admin.HandleFunc(http.MethodGet, "/api/v1/admin/products", handler.HandleAdminGetProducts(productsRepository, priceHistoryRepository, categoriesRepository, catalog), pricing)
+	admin.HandleFunc(http.MethodGet, "/api/v1/admin/products/{sku}", handler.HandleAdminGetProduct(productsRepository, priceHistoryRepository, categoriesRepository, catalog), pricing)
+
+	viewer := v1.With(api.RequireRole(auth.RoleViewer))
+	if discountRulesRepository != nil {
+		viewer.HandleFunc(http.MethodGet, "/api/v1/discounts", handler.HandleGetDiscountRules(discountRulesRepository))
+		viewer.HandleFunc(http.MethodGet, "/api/v1/discounts/{id}", handler.HandleGetDiscountRule(discountRulesRepository))
+		// simulations only read, viewers can try rule sets before asking editors to store them
+		viewer.HandleFunc(http.MethodPost, "/api/v1/discount-simulations", handler.HandleSimulateDiscountRules(productsRepository, discountRulesRepository), pricing)
+	}
 
 	return router
+}
 
+func registerCacheMetrics(registry *metrics.Registry, repository *persistance.CachedProductsRepository) {
+	registry.NewCounterFunc("products_cache_hits_total", "Products queries answered from the cache.", func() float64 {
+		return float64(repository.Stats().Hits)
+	})
+	registry.NewCounterFunc("products_cache_misses_total", "Products queries that reached the database.", func() float64 {
+		return float64(repository.Stats().Misses)
+	})
+	registry.NewGaugeFunc("products_cache_entries", "Products queries currently cached.", func() float64 {
+		return float64(repository.Stats().Size)
+	})
 }
@@ -0,0 +1,144 @@
+package internal
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go-products.com/m/internal/product/domain"
+	"go-products.com/m/internal/product/infrastructure/handler"
+	"go-products.com/m/internal/product/infrastructure/persistance"
+	"go-products.com/m/internal/product/infrastructure/persistance/migrations"
+	"go-products.com/m/internal/shared/api"
+	"go-products.com/m/internal/shared/auth"
+	"go-products.com/m/internal/shared/database"
+	"go-products.com/m/internal/shared/health"
+	"go-products.com/m/internal/shared/metrics"
+	"go-products.com/m/internal/shared/ratelimit"
+)
+
+// testServer a server built with every dependency set, tokens holds an API key per role
+type testServer struct {
+	handler http.Handler
+	tokens  map[auth.Role]string
+}
+
+func newTestServer(t *testing.T) testServer {
+	t.Helper()
+
+	db, err := database.GenerateDatabaseConnection(database.DatabaseConnection{
+		DatabaseName: filepath.Join(t.TempDir(), "products.db"),
+	}, migrations.CreateProductsDatabase)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	ctx := context.Background()
+	categoriesRepository := persistance.NewCategoriesSQLiteRepository(db)
+	productsRepository := persistance.NewProductsSQLiteRepository(db)
+	require.NoError(t, migrations.InitCategories(ctx, categoriesRepository, filepath.Join("..", "infra", "migrations", "categories.json")))
+	require.NoError(t, migrations.InitProducts(ctx, productsRepository, filepath.Join("..", "infra", "migrations", "data.json")))
+
+	apiKeys := auth.NewAPIKeysSQLiteRepository(db)
+	tokens := make(map[auth.Role]string)
+	for _, role := range []auth.Role{auth.RoleViewer, auth.RoleEditor, auth.RoleAdmin} {
+		key, token, err := auth.NewAPIKey(string(role), role)
+		require.NoError(t, err)
+		require.NoError(t, apiKeys.CreateAPIKey(ctx, key))
+		tokens[role] = token
+	}
+
+	seed := health.NewGate()
+	seed.Open()
+	readiness := health.NewChecks()
+	readiness.Add("database", health.Ping(db))
+	readiness.Add("seed", seed.Check)
+
+	return testServer{
+		handler: SetupServer(ServerDependencies{
+			ProductsRepository:       productsRepository,
+			CategoriesRepository:     categoriesRepository,
+			PriceHistoryRepository:   persistance.NewPriceHistorySQLiteRepository(db),
+			CatalogVersionRepository: persistance.NewCatalogVersionSQLiteRepository(db),
+			DiscountRulesRepository:  persistance.NewDiscountRulesSQLiteRepository(db),
+			CachePolicy:              api.DefaultCachePolicy,
+			ProductsCache:            persistance.CacheOptions{Size: 16, TTL: time.Minute},
+			Catalog:                  handler.CatalogOptions{DefaultLimit: 5, Discounts: domain.DefaultDiscountPolicy()},
+			RequestTimeout:           time.Second,
+			CORS:                     api.DefaultCORSOptions,
+			Logger:                   slog.New(slog.NewTextHandler(io.Discard, nil)),
+			ErrorFormat:              api.ErrorFormatJSON,
+			Authenticator:            auth.NewAPIKeyAuthenticator(apiKeys),
+			PricingAttributes:        true,
+			RateLimitStore:           ratelimit.NewMemoryStore(),
+			RateLimits:               api.RateLimitPolicy{Default: ratelimit.Limit{Rate: 100, Burst: 100}},
+			Metrics:                  metrics.NewRegistry(),
+			Readiness:                readiness,
+			Seed:                     seed,
+		}),
+		tokens: tokens,
+	}
+}
+
+func (s testServer) serve(method, path string, role auth.Role, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token, ok := s.tokens[role]; ok {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	recorder := httptest.NewRecorder()
+	s.handler.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func TestSetupServer(t *testing.T) {
+	assertions := require.New(t)
+
+	server := newTestServer(t)
+
+	tests := []struct {
+		name               string
+		method             string
+		path               string
+		role               auth.Role
+		body               string
+		expectedStatusCode int
+	}{
+		{name: "Liveness", method: http.MethodGet, path: "/healthz", expectedStatusCode: http.StatusOK},
+		{name: "Readiness", method: http.MethodGet, path: "/readyz", expectedStatusCode: http.StatusOK},
+		{name: "Metrics", method: http.MethodGet, path: "/metrics", expectedStatusCode: http.StatusOK},
+		{name: "Products", method: http.MethodGet, path: "/api/v1/products", expectedStatusCode: http.StatusOK},
+		{name: "Product", method: http.MethodGet, path: "/api/v1/products/000001", expectedStatusCode: http.StatusOK},
+		{name: "Categories", method: http.MethodGet, path: "/api/v1/categories", expectedStatusCode: http.StatusOK},
+		{name: "Discount rules", method: http.MethodGet, path: "/api/v1/discounts", role: auth.RoleViewer, expectedStatusCode: http.StatusOK},
+		{
+			name:               "Discount simulation",
+			method:             http.MethodPost,
+			path:               "/api/v1/discount-simulations",
+			role:               auth.RoleViewer,
+			body:               `{"rules": [{"kind": "category", "key": "boots", "percentage": 0.2}]}`,
+			expectedStatusCode: http.StatusOK,
+		},
+		{name: "Admin products for viewers", method: http.MethodGet, path: "/api/v1/admin/products", role: auth.RoleViewer, expectedStatusCode: http.StatusForbidden},
+		{name: "Admin product for editors", method: http.MethodGet, path: "/api/v1/admin/products/000001", role: auth.RoleEditor, expectedStatusCode: http.StatusForbidden},
+		{name: "Admin products", method: http.MethodGet, path: "/api/v1/admin/products?status=draft", role: auth.RoleAdmin, expectedStatusCode: http.StatusOK},
+		{name: "Admin product", method: http.MethodGet, path: "/api/v1/admin/products/000001", role: auth.RoleAdmin, expectedStatusCode: http.StatusOK},
+		{name: "Unsupported method", method: http.MethodDelete, path: "/api/v1/categories", role: auth.RoleAdmin, expectedStatusCode: http.StatusMethodNotAllowed},
+		{name: "Unknown route", method: http.MethodGet, path: "/api/v2/products", expectedStatusCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := server.serve(tt.method, tt.path, tt.role, tt.body)
+
+			assertions.Equal(tt.expectedStatusCode, recorder.Code, recorder.Body.String())
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go-products.com/m/internal/shared/auth"
+	"go-products.com/m/internal/shared/logging"
+)
+
+// Authenticate stores in the request context the principal of the bearer token, requests without Authorization header
+// go on anonymously and RequireRole decides whether they are allowed. Invalid tokens are always rejected so clients
+// notice them instead of silently losing access
+func Authenticate(authenticator auth.Authenticator) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			header := request.Header.Get("Authorization")
+			if header == "" {
+				next.ServeHTTP(response, request)
+
+				return
+			}
+
+			scheme, token, ok := strings.Cut(header, " ")
+			if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+				unauthorized(response, "authorization header must be Bearer <token>")
+
+				return
+			}
+
+			if authenticator == nil {
+				unauthorized(response, "invalid credentials")
+
+				return
+			}
+
+			principal, err := authenticator.Authenticate(request.Context(), strings.TrimSpace(token))
+			if errors.Is(err, auth.ErrInvalidCredentials) {
+				unauthorized(response, "invalid credentials")
+
+				return
+			}
+
+			if err != nil {
+				logging.FromContext(request.Context()).Error("authenticating request", slog.Any("error", err))
+				InternalServerError(response, "internal server error")
+
+				return
+			}
+
+			trace.SpanFromContext(request.Context()).SetAttributes(
+				attribute.String("enduser.id", principal.Subject),
+				attribute.String("enduser.role", string(principal.Role)),
+			)
+
+			ctx := auth.WithPrincipal(request.Context(), principal)
+			ctx = logging.WithLogger(ctx, logging.FromContext(ctx).With(slog.String("principal", principal.Subject)))
+			next.ServeHTTP(response, request.WithContext(ctx))
+		})
+	}
+}
+
+// RequireRole rejects anonymous requests with 401 and principals whose role doesn't grant role with 403, it must run
+// after Authenticate
+func RequireRole(role auth.Role) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			principal, ok := auth.PrincipalFromContext(request.Context())
+			if !ok {
+				unauthorized(response, "authentication required")
+
+				return
+			}
+
+			if !principal.Role.Grants(role) {
+				Forbidden(response, "role "+string(principal.Role)+" can't access this resource, "+string(role)+" required")
+
+				return
+			}
+
+			next.ServeHTTP(response, request)
+		})
+	}
+}
+
+// Audit logs who changed what once the request is answered, it must run after Authenticate and before RequireRole so
+// denied attempts are audited too
+func Audit() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			recorder := NewStatusRecorder(response)
+
+			next.ServeHTTP(recorder, request)
+
+			principal, _ := auth.PrincipalFromContext(request.Context())
+			logging.FromContext(request.Context()).Info("audit",
+				slog.String("subject", principal.Subject),
+				slog.String("name", principal.Name),
+				slog.String("role", string(principal.Role)),
+				slog.String("method", request.Method),
+				slog.String("route", routeFromContext(request.Context())),
+				slog.String("uri", request.URL.RequestURI()),
+				slog.Int("status", recorder.Status()),
+			)
+		})
+	}
+}
+
+func unauthorized(response http.ResponseWriter, message string) {
+	response.Header().Set("WWW-Authenticate", `Bearer realm="products"`)
+	Unauthorized(response, message)
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go-products.com/m/internal/shared/auth"
+	"go-products.com/m/internal/shared/logging"
+)
+
+func TestAuthenticateAndRequireRole(t *testing.T) {
+	assertions := require.New(t)
+
+	authenticator := auth.AuthenticatorFunc(func(ctx context.Context, token string) (auth.Principal, error) {
+		switch token {
+		case "viewer-key":
+			return auth.Principal{Subject: "1", Role: auth.RoleViewer}, nil
+		case "editor-key":
+			return auth.Principal{Subject: "2", Role: auth.RoleEditor}, nil
+		case "broken-key":
+			return auth.Principal{}, errors.New("database is locked")
+		default:
+			return auth.Principal{}, auth.ErrInvalidCredentials
+		}
+	})
+
+	handler := Chain(Authenticate(authenticator), RequireRole(auth.RoleEditor))(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		principal, ok := auth.PrincipalFromContext(request.Context())
+		assertions.True(ok)
+		Success(response, principal.Subject)
+	}))
+
+	tests := []struct {
+		name               string
+		authorization      string
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name:               "Allowed role",
+			authorization:      "Bearer editor-key",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"content": "2"}`,
+		},
+		{
+			name:               "Anonymous request",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedResponse:   `{"message": "authentication required", "app_code": "UNAUTHORIZED"}`,
+		},
+		{
+			name:               "Unknown key",
+			authorization:      "Bearer stolen-key",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedResponse:   `{"message": "invalid credentials", "app_code": "UNAUTHORIZED"}`,
+		},
+		{
+			name:               "Not a bearer token",
+			authorization:      "Basic dXNlcjpwYXNz",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedResponse:   `{"message": "authorization header must be Bearer <token>", "app_code": "UNAUTHORIZED"}`,
+		},
+		{
+			name:               "Insufficient role",
+			authorization:      "Bearer viewer-key",
+			expectedStatusCode: http.StatusForbidden,
+			expectedResponse:   `{"message": "role viewer can't access this resource, editor required", "app_code": "FORBIDDEN"}`,
+		},
+		{
+			name:               "Authenticator failure",
+			authorization:      "Bearer broken-key",
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse:   `{"message": "internal server error", "app_code": "INTERNAL_SERVER_ERROR"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPut, "/api/v1/products/000001", nil)
+			if tt.authorization != "" {
+				request.Header.Set("Authorization", tt.authorization)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			assertions.Equal(tt.expectedStatusCode, recorder.Code)
+			assertions.JSONEq(tt.expectedResponse, recorder.Body.String())
+			if tt.expectedStatusCode == http.StatusUnauthorized {
+				assertions.Equal(`Bearer realm="products"`, recorder.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestAuthenticate_AnonymousReads(t *testing.T) {
+	assertions := require.New(t)
+
+	handler := Authenticate(nil)(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		_, ok := auth.PrincipalFromContext(request.Context())
+		assertions.False(ok)
+		NoContent(response)
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/products", nil))
+
+	assertions.Equal(http.StatusNoContent, recorder.Code)
+}
+
+func TestAudit(t *testing.T) {
+	assertions := require.New(t)
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	authenticator := auth.AuthenticatorFunc(func(ctx context.Context, token string) (auth.Principal, error) {
+		if token == "viewer" {
+			return auth.Principal{Subject: "user-7", Name: "Grace", Role: auth.RoleViewer}, nil
+		}
+
+		return auth.Principal{Subject: "user-42", Name: "Ada", Role: auth.RoleEditor}, nil
+	})
+
+	router := NewRouter(Tracing(), Logger(logger), Authenticate(authenticator))
+	router.With(Audit(), RequireRole(auth.RoleEditor)).HandleFunc(http.MethodPut, "/api/v1/products/{sku}", func(response http.ResponseWriter, request *http.Request) {
+		logging.FromContext(request.Context()).Info("product updated")
+		NoContent(response)
+	})
+
+	request := httptest.NewRequest(http.MethodPut, "/api/v1/products/000001", nil)
+	request.Header.Set("Authorization", "Bearer token")
+	router.ServeHTTP(httptest.NewRecorder(), request)
+
+	assertions.Regexp(`msg="product updated" .*principal=user-42`, logs.String())
+	assertions.Regexp(`msg=audit .*subject=user-42 name=Ada role=editor method=PUT route=/api/v1/products/{sku} uri=/api/v1/products/000001 status=204`, logs.String())
+
+	// denied writes are audited as well
+	request = httptest.NewRequest(http.MethodPut, "/api/v1/products/000002", nil)
+	request.Header.Set("Authorization", "Bearer viewer")
+	router.ServeHTTP(httptest.NewRecorder(), request)
+
+	assertions.Regexp(`msg=audit .*subject=user-7 name=Grace role=viewer method=PUT route=/api/v1/products/{sku} uri=/api/v1/products/000002 status=403`, logs.String())
+}
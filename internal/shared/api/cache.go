@@ -0,0 +1,220 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CachePolicy describes the Cache-Control header sent with cacheable responses
+type CachePolicy struct {
+	MaxAge         time.Duration
+	Private        bool
+	MustRevalidate bool
+}
+
+// DefaultCachePolicy lets shared caches store responses but forces them to revalidate through If-None-Match every time
+var DefaultCachePolicy = CachePolicy{MaxAge: 0, MustRevalidate: true}
+
+func (p CachePolicy) String() string {
+	directives := []string{"public"}
+	if p.Private {
+		directives[0] = "private"
+	}
+
+	directives = append(directives, "max-age="+strconv.Itoa(int(p.MaxAge.Seconds())))
+	if p.MustRevalidate {
+		directives = append(directives, "must-revalidate")
+	}
+
+	return strings.Join(directives, ", ")
+}
+
+// VersionFunc returns a value that changes whenever the data behind a response changes
+type VersionFunc func(ctx context.Context) (string, error)
+
+// Cache answers conditional GETs with 304 Not Modified and sets Cache-Control on successful responses.
+//
+// When version is given the ETag is derived from it and the request URL, so unchanged resources are answered without
+// calling the handler at all. Otherwise the handler runs and the ETag is the one it set or a hash of the response body
+func Cache(policy CachePolicy, version VersionFunc) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			if request.Method != http.MethodGet && request.Method != http.MethodHead {
+				next.ServeHTTP(response, request)
+
+				return
+			}
+
+			if version != nil {
+				serveVersioned(policy, version, next, response, request)
+
+				return
+			}
+
+			serveBuffered(policy, next, response, request)
+		})
+	}
+}
+
+func serveVersioned(policy CachePolicy, version VersionFunc, next http.Handler, response http.ResponseWriter, request *http.Request) {
+	currentVersion, err := version(request.Context())
+	if err != nil {
+		next.ServeHTTP(response, request)
+
+		return
+	}
+
+	etag := hashETag([]byte(currentVersion + "|" + request.URL.RequestURI() + "|" + request.Header.Get("Accept")))
+	if matchesETag(request.Header.Get("If-None-Match"), etag) {
+		notModified(response, policy, etag)
+
+		return
+	}
+
+	next.ServeHTTP(&cacheHeadersWriter{ResponseWriter: response, policy: policy, etag: etag}, request)
+}
+
+func serveBuffered(policy CachePolicy, next http.Handler, response http.ResponseWriter, request *http.Request) {
+	buffered := &bufferedResponseWriter{response: response, header: make(http.Header), status: http.StatusOK}
+	next.ServeHTTP(buffered, request)
+
+	for key, values := range buffered.header {
+		response.Header()[key] = values
+	}
+
+	if buffered.status != http.StatusOK {
+		response.WriteHeader(buffered.status)
+		_, _ = response.Write(buffered.body.Bytes())
+
+		return
+	}
+
+	etag := buffered.header.Get("ETag")
+	if etag == "" {
+		etag = hashETag(buffered.body.Bytes())
+	}
+
+	if matchesETag(request.Header.Get("If-None-Match"), etag) {
+		notModified(response, policy, etag)
+
+		return
+	}
+
+	setCacheHeaders(response, policy, etag)
+	response.WriteHeader(http.StatusOK)
+	_, _ = response.Write(buffered.body.Bytes())
+}
+
+func notModified(response http.ResponseWriter, policy CachePolicy, etag string) {
+	for _, header := range []string{"Content-Type", "Content-Length"} {
+		response.Header().Del(header)
+	}
+
+	setCacheHeaders(response, policy, etag)
+	response.WriteHeader(http.StatusNotModified)
+}
+
+func setCacheHeaders(response http.ResponseWriter, policy CachePolicy, etag string) {
+	response.Header().Set("ETag", etag)
+	response.Header().Set("Cache-Control", policy.String())
+	response.Header().Add("Vary", "Accept")
+}
+
+func hashETag(content []byte) string {
+	sum := sha256.Sum256(content)
+
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// matchesETag If-None-Match uses the weak comparison, W/ prefixes are ignored
+func matchesETag(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cacheHeadersWriter adds the cache headers only when the handler answers successfully
+type cacheHeadersWriter struct {
+	http.ResponseWriter
+	policy      CachePolicy
+	etag        string
+	wroteHeader bool
+}
+
+func (w *cacheHeadersWriter) WriteHeader(status int) {
+	if !w.wroteHeader && status == http.StatusOK {
+		setCacheHeaders(w.ResponseWriter, w.policy, w.etag)
+	}
+
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *cacheHeadersWriter) Write(content []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	return w.ResponseWriter.Write(content)
+}
+
+func (w *cacheHeadersWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// bufferedResponseWriter holds the response until it is known whether it can be answered as not modified, content
+// and errors are rendered in the formats negotiated for the response it buffers for
+type bufferedResponseWriter struct {
+	response    http.ResponseWriter
+	header      http.Header
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (w *bufferedResponseWriter) errorFormat() errorFormat {
+	return errorFormatOf(w.response)
+}
+
+func (w *bufferedResponseWriter) responseEncoder() Encoder {
+	return encoderOf(w.response)
+}
+
+func (w *bufferedResponseWriter) responseLogger() *slog.Logger {
+	return loggerOf(w.response)
+}
+
+func (w *bufferedResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+
+	w.status = status
+	w.wroteHeader = true
+}
+
+func (w *bufferedResponseWriter) Write(content []byte) (int, error) {
+	w.wroteHeader = true
+
+	return w.body.Write(content)
+}
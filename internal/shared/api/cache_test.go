@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	assertions := require.New(t)
+
+	successHandler := func(calls *int) http.Handler {
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			*calls++
+			Success(response, "content")
+		})
+	}
+
+	t.Run("Buffered response gets an ETag and is answered with 304 when it matches", func(t *testing.T) {
+		calls := 0
+		handler := Cache(DefaultCachePolicy, nil)(successHandler(&calls))
+
+		first := httptest.NewRecorder()
+		handler.ServeHTTP(first, httptest.NewRequest(http.MethodGet, "/resource", nil))
+
+		etag := first.Header().Get("ETag")
+		assertions.Equal(http.StatusOK, first.Code)
+		assertions.NotEmpty(etag)
+		assertions.Equal("public, max-age=0, must-revalidate", first.Header().Get("Cache-Control"))
+		assertions.JSONEq(`{"content": "content"}`, first.Body.String())
+
+		request := httptest.NewRequest(http.MethodGet, "/resource", nil)
+		request.Header.Set("If-None-Match", `"other", `+etag)
+		second := httptest.NewRecorder()
+		handler.ServeHTTP(second, request)
+
+		assertions.Equal(http.StatusNotModified, second.Code)
+		assertions.Empty(second.Body.String())
+		assertions.Equal(etag, second.Header().Get("ETag"))
+	})
+
+	t.Run("Versioned response skips the handler when the version did not change", func(t *testing.T) {
+		calls := 0
+		version := "1"
+		handler := Cache(CachePolicy{MaxAge: time.Minute, Private: true}, func(ctx context.Context) (string, error) {
+			return version, nil
+		})(successHandler(&calls))
+
+		first := httptest.NewRecorder()
+		handler.ServeHTTP(first, httptest.NewRequest(http.MethodGet, "/resource?page=1", nil))
+		etag := first.Header().Get("ETag")
+		assertions.Equal("private, max-age=60", first.Header().Get("Cache-Control"))
+
+		request := httptest.NewRequest(http.MethodGet, "/resource?page=1", nil)
+		request.Header.Set("If-None-Match", etag)
+		second := httptest.NewRecorder()
+		handler.ServeHTTP(second, request)
+
+		assertions.Equal(http.StatusNotModified, second.Code)
+		assertions.Equal(1, calls)
+
+		version = "2"
+		third := httptest.NewRecorder()
+		handler.ServeHTTP(third, request)
+
+		assertions.Equal(http.StatusOK, third.Code)
+		assertions.NotEqual(etag, third.Header().Get("ETag"))
+		assertions.Equal(2, calls)
+	})
+
+	t.Run("Error responses are not cached", func(t *testing.T) {
+		handler := Cache(DefaultCachePolicy, nil)(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			InternalServerError(response, "boom")
+		}))
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/resource", nil))
+
+		assertions.Equal(http.StatusInternalServerError, recorder.Code)
+		assertions.Empty(recorder.Header().Get("ETag"))
+		assertions.Empty(recorder.Header().Get("Cache-Control"))
+	})
+}
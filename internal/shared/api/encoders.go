@@ -0,0 +1,272 @@
+package api
+
+import (
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Encoder renders the content of successful responses in a media type
+type Encoder interface {
+	ContentType() string
+	Encode(writer io.Writer, content any) error
+}
+
+var (
+	// JSON content is wrapped in SuccessResponse
+	JSON Encoder = jsonEncoder{}
+	// XML content is wrapped in a response element, slices are written as a list of item elements
+	XML Encoder = xmlEncoder{}
+	// CSV slices of structs are written as one row per element, nested structs are flattened into dotted columns and
+	// lists are written as JSON
+	CSV Encoder = csvEncoder{}
+	// MessagePack content is wrapped like SuccessResponse
+	MessagePack Encoder = msgpackEncoder{}
+
+	// DefaultEncoders JSON is preferred when the client accepts anything
+	DefaultEncoders = []Encoder{JSON, XML, CSV, MessagePack}
+)
+
+type jsonEncoder struct{}
+
+func (jsonEncoder) ContentType() string {
+	return "application/json"
+}
+
+func (jsonEncoder) Encode(writer io.Writer, content any) error {
+	return json.NewEncoder(writer).Encode(SuccessResponse{Content: content})
+}
+
+type xmlEncoder struct{}
+
+func (xmlEncoder) ContentType() string {
+	return "application/xml"
+}
+
+func (xmlEncoder) Encode(writer io.Writer, content any) error {
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(writer)
+	response := xml.StartElement{Name: xml.Name{Local: "response"}}
+	if err := encoder.EncodeToken(response); err != nil {
+		return err
+	}
+
+	if err := encodeXMLContent(encoder, reflect.ValueOf(content)); err != nil {
+		return err
+	}
+
+	if err := encoder.EncodeToken(response.End()); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
+func encodeXMLContent(encoder *xml.Encoder, content reflect.Value) error {
+	element := xml.StartElement{Name: xml.Name{Local: "content"}}
+	if !content.IsValid() {
+		return encoder.EncodeElement("", element)
+	}
+
+	if content.Kind() != reflect.Slice && content.Kind() != reflect.Array {
+		return encoder.EncodeElement(content.Interface(), element)
+	}
+
+	if err := encoder.EncodeToken(element); err != nil {
+		return err
+	}
+
+	for i := 0; i < content.Len(); i++ {
+		if err := encoder.EncodeElement(content.Index(i).Interface(), xml.StartElement{Name: xml.Name{Local: "item"}}); err != nil {
+			return err
+		}
+	}
+
+	return encoder.EncodeToken(element.End())
+}
+
+type msgpackEncoder struct{}
+
+func (msgpackEncoder) ContentType() string {
+	return "application/msgpack"
+}
+
+// Encode structs are keyed by their json tags, like the JSON document
+func (msgpackEncoder) Encode(writer io.Writer, content any) error {
+	encoder := msgpack.NewEncoder(writer)
+	encoder.SetCustomStructTag("json")
+	encoder.SetSortMapKeys(true)
+
+	return encoder.Encode(SuccessResponse{Content: content})
+}
+
+type csvEncoder struct{}
+
+func (csvEncoder) ContentType() string {
+	return "text/csv"
+}
+
+func (csvEncoder) Encode(writer io.Writer, content any) error {
+	value := reflect.ValueOf(content)
+	rows := []reflect.Value{value}
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		rows = make([]reflect.Value, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			rows = append(rows, value.Index(i))
+		}
+	}
+
+	columns := []csvColumn{{name: "value"}}
+	if rowType := elementType(value); isFlattened(rowType) {
+		columns = csvColumns("", nil, rowType)
+	}
+
+	csvWriter := csv.NewWriter(writer)
+	header := make([]string, 0, len(columns))
+	for _, column := range columns {
+		header = append(header, column.name)
+	}
+
+	if err := csvWriter.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		record := make([]string, 0, len(columns))
+		for _, column := range columns {
+			cell, err := csvCell(row, column.index)
+			if err != nil {
+				return fmt.Errorf("%s: %w", column.name, err)
+			}
+
+			record = append(record, cell)
+		}
+
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
+
+// csvColumn a field reached from the row through the field indexes in index
+type csvColumn struct {
+	name  string
+	index []int
+}
+
+func elementType(value reflect.Value) reflect.Type {
+	if !value.IsValid() {
+		return nil
+	}
+
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		return value.Type().Elem()
+	}
+
+	return value.Type()
+}
+
+// isFlattened structs are written as a column per field, unless they know how to write themselves as text
+func isFlattened(valueType reflect.Type) bool {
+	if valueType == nil {
+		return false
+	}
+
+	for valueType.Kind() == reflect.Pointer {
+		valueType = valueType.Elem()
+	}
+
+	return valueType.Kind() == reflect.Struct && !reflect.PointerTo(valueType).Implements(textMarshalerType)
+}
+
+func csvColumns(prefix string, index []int, valueType reflect.Type) []csvColumn {
+	for valueType.Kind() == reflect.Pointer {
+		valueType = valueType.Elem()
+	}
+
+	columns := make([]csvColumn, 0, valueType.NumField())
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		name, skip := jsonFieldName(field)
+		if !field.IsExported() || skip {
+			continue
+		}
+
+		fieldIndex := append(append([]int{}, index...), i)
+		if isFlattened(field.Type) {
+			columns = append(columns, csvColumns(prefix+name+".", fieldIndex, field.Type)...)
+
+			continue
+		}
+
+		columns = append(columns, csvColumn{name: prefix + name, index: fieldIndex})
+	}
+
+	return columns
+}
+
+// csvCell nil values are written as empty cells
+func csvCell(value reflect.Value, index []int) (string, error) {
+	if !value.IsValid() {
+		return "", nil
+	}
+
+	for _, i := range index {
+		for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+			if value.IsNil() {
+				return "", nil
+			}
+
+			value = value.Elem()
+		}
+
+		value = value.Field(i)
+	}
+
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return "", nil
+		}
+
+		value = value.Elem()
+	}
+
+	if marshaler, ok := value.Interface().(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+
+		return string(text), err
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, 64), nil
+	default:
+		content, err := json.Marshal(value.Interface())
+
+		return string(content), err
+	}
+}
+
+var textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
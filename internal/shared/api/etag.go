@@ -0,0 +1,39 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrMissingIfMatch = errors.New("If-Match header is required, use the ETag returned when fetching the resource")
+	ErrInvalidIfMatch = errors.New("If-Match header must be a single strong ETag or *")
+)
+
+// SetETag writes value as a strong entity tag
+func SetETag(response http.ResponseWriter, value string) {
+	response.Header().Set("ETag", `"`+value+`"`)
+}
+
+// AnyETag If-Match value matching any current representation of the resource
+const AnyETag = "*"
+
+// GetIfMatch returns the unquoted entity tag sent in the If-Match header, or AnyETag. Weak tags are rejected since
+// they cannot be used for write preconditions
+func GetIfMatch(request *http.Request) (string, error) {
+	ifMatch := strings.TrimSpace(request.Header.Get("If-Match"))
+	if ifMatch == "" {
+		return "", ErrMissingIfMatch
+	}
+
+	if ifMatch == AnyETag {
+		return AnyETag, nil
+	}
+
+	if len(ifMatch) < 2 || !strings.HasPrefix(ifMatch, `"`) || !strings.HasSuffix(ifMatch, `"`) || strings.Contains(ifMatch, ",") {
+		return "", ErrInvalidIfMatch
+	}
+
+	return strings.Trim(ifMatch, `"`), nil
+}
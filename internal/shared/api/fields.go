@@ -0,0 +1,244 @@
+package api
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+const UnknownFieldCode = "UNKNOWN_FIELD"
+
+// ErrUnknownFields names requested through a query parameter that the response does not have, each of them is reported
+type ErrUnknownFields struct {
+	Parameter string
+	Names     []string
+}
+
+func (e ErrUnknownFields) Error() string {
+	messages := make([]string, 0, len(e.Names))
+	for _, field := range e.FieldErrors() {
+		messages = append(messages, field.Message)
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+func (e ErrUnknownFields) FieldErrors() []FieldError {
+	fields := make([]FieldError, 0, len(e.Names))
+	for _, name := range e.Names {
+		fields = append(fields, FieldError{Field: e.Parameter, Code: UnknownFieldCode, Message: e.Parameter + ": unknown field " + `"` + name + `"`})
+	}
+
+	return fields
+}
+
+// Fieldset fields of a response kept for the client, keyed by their json name. A field mapped to nil is kept whole,
+// a nil Fieldset keeps every field
+type Fieldset map[string]Fieldset
+
+// ParseFieldset reads a comma separated list of dotted paths (sku,price.final) checking every path exists in schema,
+// an empty value keeps every field
+func ParseFieldset(parameter, value string, schema reflect.Type) (Fieldset, error) {
+	var fieldset Fieldset
+	unknown := make([]string, 0)
+	for _, path := range strings.Split(value, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+
+		if !hasPath(schema, strings.Split(path, ".")) {
+			unknown = append(unknown, path)
+
+			continue
+		}
+
+		if fieldset == nil {
+			fieldset = make(Fieldset)
+		}
+
+		fieldset.add(strings.Split(path, "."))
+	}
+
+	if len(unknown) > 0 {
+		return nil, ErrUnknownFields{Parameter: parameter, Names: unknown}
+	}
+
+	return fieldset, nil
+}
+
+// ParseIncludes reads a comma separated list of expansions, every one of them must be in available
+func ParseIncludes(parameter, value string, available ...string) (map[string]bool, error) {
+	includes := make(map[string]bool)
+	unknown := make([]string, 0)
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		if !slices.Contains(available, name) {
+			unknown = append(unknown, name)
+
+			continue
+		}
+
+		includes[name] = true
+	}
+
+	if len(unknown) > 0 {
+		return nil, ErrUnknownFields{Parameter: parameter, Names: unknown}
+	}
+
+	return includes, nil
+}
+
+// add keeps the field at path, a field already kept whole is not narrowed by a longer path
+func (f Fieldset) add(path []string) {
+	nested, ok := f[path[0]]
+	if len(path) == 1 {
+		f[path[0]] = nil
+
+		return
+	}
+
+	if ok && nested == nil {
+		return
+	}
+
+	if nested == nil {
+		nested = make(Fieldset)
+		f[path[0]] = nested
+	}
+
+	nested.add(path[1:])
+}
+
+// Apply a copy of content holding only the fields of the set. The copy is built from struct types made on the fly
+// carrying the original tags, so it is encoded by every Encoder like content would be
+func (f Fieldset) Apply(content any) any {
+	if f == nil || content == nil {
+		return content
+	}
+
+	return f.shapeValue(reflect.ValueOf(content)).Interface()
+}
+
+func (f Fieldset) shapeType(valueType reflect.Type) reflect.Type {
+	if f == nil {
+		return valueType
+	}
+
+	switch valueType.Kind() {
+	case reflect.Pointer:
+		return reflect.PointerTo(f.shapeType(valueType.Elem()))
+	case reflect.Slice:
+		return reflect.SliceOf(f.shapeType(valueType.Elem()))
+	case reflect.Struct:
+		fields := make([]reflect.StructField, 0, len(f))
+		for i := 0; i < valueType.NumField(); i++ {
+			field := valueType.Field(i)
+			nested, ok := f.lookup(field)
+			if !ok {
+				continue
+			}
+
+			fields = append(fields, reflect.StructField{Name: field.Name, Type: nested.shapeType(field.Type), Tag: field.Tag})
+		}
+
+		return reflect.StructOf(fields)
+	default:
+		return valueType
+	}
+}
+
+func (f Fieldset) shapeValue(value reflect.Value) reflect.Value {
+	if f == nil {
+		return value
+	}
+
+	shaped := reflect.New(f.shapeType(value.Type())).Elem()
+	switch value.Kind() {
+	case reflect.Pointer:
+		if !value.IsNil() {
+			shaped.Set(f.shapeValue(value.Elem()).Addr())
+		}
+	case reflect.Slice:
+		if !value.IsNil() {
+			shaped.Set(reflect.MakeSlice(shaped.Type(), value.Len(), value.Len()))
+			for i := 0; i < value.Len(); i++ {
+				shaped.Index(i).Set(f.shapeValue(value.Index(i)))
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			nested, ok := f.lookup(value.Type().Field(i))
+			if !ok {
+				continue
+			}
+
+			shaped.FieldByName(value.Type().Field(i).Name).Set(nested.shapeValue(value.Field(i)))
+		}
+	default:
+		shaped.Set(value)
+	}
+
+	return shaped
+}
+
+// lookup the fields kept of field, ok is false when field is not kept at all
+func (f Fieldset) lookup(field reflect.StructField) (Fieldset, bool) {
+	name, skip := jsonFieldName(field)
+	if !field.IsExported() || skip {
+		return nil, false
+	}
+
+	nested, ok := f[name]
+
+	return nested, ok
+}
+
+// hasPath whether the fields of path can be reached from schema, through pointers and lists
+func hasPath(schema reflect.Type, path []string) bool {
+	for _, name := range path {
+		for schema.Kind() == reflect.Pointer || schema.Kind() == reflect.Slice {
+			schema = schema.Elem()
+		}
+
+		if schema.Kind() != reflect.Struct {
+			return false
+		}
+
+		found := false
+		for i := 0; i < schema.NumField(); i++ {
+			field := schema.Field(i)
+			fieldName, skip := jsonFieldName(field)
+			if field.IsExported() && !skip && fieldName == name {
+				schema, found = field.Type, true
+
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// jsonFieldName name of a struct field as encoding/json writes it, skip is set for fields tagged "-"
+func jsonFieldName(field reflect.StructField) (name string, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+
+	name, _, _ = strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+
+	return name, false
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type shapedCategory struct {
+	Slug     string           `json:"slug"`
+	Children []shapedCategory `json:"children"`
+}
+
+type shapedProduct struct {
+	Sku      string          `json:"sku"`
+	Name     string          `json:"name"`
+	Internal string          `json:"-"`
+	Price    negotiatedPrice `json:"price"`
+	Category *shapedCategory `json:"category,omitempty"`
+}
+
+func TestParseFieldset(t *testing.T) {
+	assertions := require.New(t)
+
+	schema := reflect.TypeFor[shapedProduct]()
+
+	tests := []struct {
+		name          string
+		value         string
+		expected      Fieldset
+		expectedError string
+	}{
+		{name: "Empty keeps every field", value: " , ", expected: nil},
+		{name: "Top level fields", value: "sku,name", expected: Fieldset{"sku": nil, "name": nil}},
+		{name: "Nested fields", value: "sku, price.original", expected: Fieldset{"sku": nil, "price": Fieldset{"original": nil}}},
+		{name: "Whole fields are not narrowed", value: "price.original,price", expected: Fieldset{"price": nil}},
+		{name: "Fields through pointers and lists", value: "category.children.slug", expected: Fieldset{"category": Fieldset{"children": Fieldset{"slug": nil}}}},
+		{
+			name:          "Every unknown field is reported",
+			value:         "sku,Internal,price.final,sku.value",
+			expectedError: `fields: unknown field "Internal"; fields: unknown field "price.final"; fields: unknown field "sku.value"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fieldset, err := ParseFieldset("fields", tt.value, schema)
+			if tt.expectedError != "" {
+				assertions.EqualError(err, tt.expectedError)
+				assertions.ErrorAs(err, &ErrUnknownFields{})
+
+				return
+			}
+
+			assertions.NoError(err)
+			assertions.Equal(tt.expected, fieldset)
+		})
+	}
+}
+
+func TestParseIncludes(t *testing.T) {
+	assertions := require.New(t)
+
+	includes, err := ParseIncludes("include", "category, ", "category", "breakdown")
+	assertions.NoError(err)
+	assertions.Equal(map[string]bool{"category": true}, includes)
+
+	_, err = ParseIncludes("include", "category,reviews", "category")
+	assertions.Equal([]FieldError{{Field: "include", Code: UnknownFieldCode, Message: `include: unknown field "reviews"`}}, err.(ErrUnknownFields).FieldErrors())
+}
+
+func TestFieldset_Apply(t *testing.T) {
+	assertions := require.New(t)
+
+	discount := "30%"
+	products := []shapedProduct{
+		{
+			Sku:      "000001",
+			Name:     "BV Lean leather ankle boots",
+			Internal: "hidden",
+			Price:    negotiatedPrice{Original: 89000, DiscountPercentage: &discount},
+			Category: &shapedCategory{Slug: "footwear", Children: []shapedCategory{{Slug: "boots"}}},
+		},
+		{Sku: "000002", Name: "Ashlington leather ankle boots", Price: negotiatedPrice{Original: 99000}},
+	}
+
+	tests := []struct {
+		name        string
+		fields      string
+		expected    string
+		expectedCSV string
+	}{
+		{
+			name:        "Every field",
+			expected:    `[{"sku":"000001","name":"BV Lean leather ankle boots","price":{"original":89000,"discount_percentage":"30%"},"category":{"slug":"footwear","children":[{"slug":"boots","children":null}]}},{"sku":"000002","name":"Ashlington leather ankle boots","price":{"original":99000,"discount_percentage":null}}]`,
+			expectedCSV: "sku,name,price.original,price.discount_percentage,category.slug,category.children\n",
+		},
+		{
+			name:        "Nested fields keep the declaration order",
+			fields:      "price.discount_percentage,sku",
+			expected:    `[{"sku":"000001","price":{"discount_percentage":"30%"}},{"sku":"000002","price":{"discount_percentage":null}}]`,
+			expectedCSV: "sku,price.discount_percentage\n000001,30%\n000002,\n",
+		},
+		{
+			name:        "Fields of lists and nil pointers",
+			fields:      "category.children.slug",
+			expected:    `[{"category":{"children":[{"slug":"boots"}]}},{}]`,
+			expectedCSV: "category.children\n\"[{\"\"slug\"\":\"\"boots\"\"}]\"\n\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fieldset, err := ParseFieldset("fields", tt.fields, reflect.TypeFor[shapedProduct]())
+			assertions.NoError(err)
+
+			shaped := fieldset.Apply(products)
+			content, err := json.Marshal(shaped)
+			assertions.NoError(err)
+			assertions.JSONEq(tt.expected, string(content))
+
+			var csv bytes.Buffer
+			assertions.NoError(CSV.Encode(&csv, shaped))
+			assertions.Contains(csv.String(), tt.expectedCSV)
+		})
+	}
+}
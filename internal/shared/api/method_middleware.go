@@ -4,14 +4,13 @@ import "net/http"
 
 type Middleware func(http.Handler) http.Handler
 
-func Method(method string, handler http.HandlerFunc) http.HandlerFunc {
-	return func(response http.ResponseWriter, request *http.Request) {
-		if request.Method != method {
-			methodNotAllowed(response)
-
-			return
+// Chain composes middlewares so the first one is the outermost, Chain(a, b)(h) behaves as a(b(h))
+func Chain(middlewares ...Middleware) Middleware {
+	return func(handler http.Handler) http.Handler {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			handler = middlewares[i](handler)
 		}
 
-		handler(response, request)
+		return handler
 	}
 }
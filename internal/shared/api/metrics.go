@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-products.com/m/internal/shared/metrics"
+)
+
+// unmatchedRoute label used for requests that didn't match any route, keeping the raw path would make label
+// cardinality unbounded
+const unmatchedRoute = "unmatched"
+
+// otherMethod label used for methods outside the standard ones, clients can send any token as method
+const otherMethod = "OTHER"
+
+var standardMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+	http.MethodConnect: true,
+	http.MethodTrace:   true,
+}
+
+// Metrics records request counts and latencies labeled by route pattern, method and status, it should run before the
+// other middlewares so their work is included in the latency
+func Metrics(registry *metrics.Registry) Middleware {
+	requests := registry.NewCounter("http_requests_total", "Total HTTP requests served.", "route", "method", "status")
+	durations := registry.NewHistogram("http_request_duration_seconds", "HTTP request latency in seconds.", metrics.DefaultBuckets, "route", "method", "status")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			start := time.Now()
+			ctx, route := withMatchedRoute(request.Context())
+			recorder := NewStatusRecorder(response)
+
+			defer func() {
+				path := route.path
+				if path == "" {
+					path = unmatchedRoute
+				}
+
+				method := request.Method
+				if !standardMethods[method] {
+					method = otherMethod
+				}
+
+				status := strconv.Itoa(recorder.Status())
+				requests.Inc(path, method, status)
+				durations.Observe(time.Since(start).Seconds(), path, method, status)
+			}()
+
+			next.ServeHTTP(recorder, request.WithContext(ctx))
+		})
+	}
+}
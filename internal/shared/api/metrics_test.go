@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go-products.com/m/internal/shared/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	assertions := require.New(t)
+
+	registry := metrics.NewRegistry()
+	router := NewRouter(Metrics(registry), Timeout(0))
+	router.HandleFunc(http.MethodGet, "/products/{sku}", func(response http.ResponseWriter, request *http.Request) {
+		Success(response, "content")
+	})
+
+	for _, target := range []string{"/products/1", "/products/2", "/unknown"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/products/1", nil))
+	for _, method := range []string{"FOO1", "FOO2"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/products/1", nil))
+	}
+
+	recorder := httptest.NewRecorder()
+	metrics.Handler(registry).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := recorder.Body.String()
+	assertions.Contains(body, `http_requests_total{route="/products/{sku}",method="GET",status="200"} 2`)
+	assertions.Contains(body, `http_requests_total{route="/products/{sku}",method="POST",status="405"} 1`)
+	assertions.Contains(body, `http_requests_total{route="unmatched",method="GET",status="404"} 1`)
+	assertions.Contains(body, `http_requests_total{route="/products/{sku}",method="OTHER",status="405"} 2`)
+	assertions.NotContains(body, "FOO1")
+	assertions.Contains(body, `http_request_duration_seconds_count{route="/products/{sku}",method="GET",status="200"} 2`)
+}
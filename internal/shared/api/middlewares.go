@@ -0,0 +1,415 @@
+package api
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"maps"
+	"net/http"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"go-products.com/m/internal/shared/logging"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// RequestID keeps the request id sent by the client or generates a new one, it is returned in the response and stored
+// in the request context
+func RequestID() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			requestID := request.Header.Get(RequestIDHeader)
+			if requestID == "" || len(requestID) > 128 {
+				requestID = newRequestID()
+			}
+
+			response.Header().Set(RequestIDHeader, requestID)
+			next.ServeHTTP(response, request.WithContext(context.WithValue(request.Context(), requestIDKey{}, requestID)))
+		})
+	}
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+
+	return requestID
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
+
+// Logger stores in the request context a logger tagged with the request id and the trace id when the request is
+// traced, it must run after RequestID and Tracing
+func Logger(logger *slog.Logger) Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			requestLogger := logger.With(slog.String("request_id", RequestIDFromContext(request.Context())))
+			if spanContext := trace.SpanContextFromContext(request.Context()); spanContext.IsValid() {
+				requestLogger = requestLogger.With(slog.String("trace_id", spanContext.TraceID().String()))
+			}
+
+			next.ServeHTTP(response, request.WithContext(logging.WithLogger(request.Context(), requestLogger)))
+		})
+	}
+}
+
+// Recover turns panics into a JSON internal server error instead of dropping the connection
+func Recover() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+
+				logging.FromContext(request.Context()).Error("panic serving request",
+					slog.String("method", request.Method),
+					slog.String("path", request.URL.Path),
+					slog.Any("panic", recovered),
+					slog.String("stack", string(debug.Stack())),
+				)
+				InternalServerError(response, "internal server error")
+			}()
+
+			next.ServeHTTP(response, request)
+		})
+	}
+}
+
+// AccessLog logs every request once it is answered
+func AccessLog() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			start := time.Now()
+			recorder := NewStatusRecorder(response)
+
+			next.ServeHTTP(recorder, request)
+
+			logging.FromContext(request.Context()).Info("request served",
+				slog.String("method", request.Method),
+				slog.String("uri", request.URL.RequestURI()),
+				slog.Int("status", recorder.Status()),
+				slog.Int("bytes", recorder.BytesWritten()),
+				slog.Duration("duration", time.Since(start)),
+			)
+		})
+	}
+}
+
+// Timeout cancels the request context after timeout so database queries and other work are abandoned, requests not
+// answered by then are answered with a 504
+func Timeout(timeout time.Duration) Middleware {
+	return func(next http.Handler) http.Handler {
+		if timeout <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			ctx, cancel := context.WithTimeout(request.Context(), timeout)
+			defer cancel()
+
+			writer := &timeoutWriter{ResponseWriter: response, ctx: ctx, header: response.Header().Clone()}
+			stop := context.AfterFunc(ctx, writer.timeout)
+			defer stop()
+
+			next.ServeHTTP(writer, request.WithContext(ctx))
+			writer.finish()
+		})
+	}
+}
+
+// timeoutWriter answers 504 once the deadline passes unless the handler answered before, whatever the handler writes
+// afterwards is discarded. Handlers get their own headers so the timeout can be written while they still run
+type timeoutWriter struct {
+	http.ResponseWriter
+	ctx         context.Context
+	header      http.Header
+	mu          sync.Mutex
+	wroteHeader bool
+	timedOut    bool
+	finished    bool
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(status int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.writeHeader(status)
+}
+
+func (w *timeoutWriter) Write(content []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.writeHeader(http.StatusOK)
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+
+	return w.ResponseWriter.Write(content)
+}
+
+func (w *timeoutWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// writeHeader must be called holding mu, answers written past the deadline are replaced by the timeout
+func (w *timeoutWriter) writeHeader(status int) {
+	if w.wroteHeader || w.timedOut {
+		return
+	}
+
+	if w.deadlineExceeded() {
+		w.writeTimeout()
+
+		return
+	}
+
+	w.wroteHeader = true
+	w.copyHeader()
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// timeout runs once the request context is done, requests cancelled by the client are left to the handler
+func (w *timeoutWriter) timeout() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.finished || w.wroteHeader || w.timedOut || !w.deadlineExceeded() {
+		return
+	}
+
+	w.writeTimeout()
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// finish the response can't be written once the handler returns, nothing is written afterwards
+func (w *timeoutWriter) finish() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.finished = true
+	if w.wroteHeader || w.timedOut {
+		return
+	}
+
+	if w.deadlineExceeded() {
+		w.writeTimeout()
+
+		return
+	}
+
+	// handlers writing nothing are answered by net/http, with the headers they set
+	w.copyHeader()
+}
+
+func (w *timeoutWriter) writeTimeout() {
+	w.timedOut = true
+	logging.FromContext(w.ctx).Warn("request timed out")
+	GatewayTimeout(w.ResponseWriter, "request timed out")
+}
+
+func (w *timeoutWriter) deadlineExceeded() bool {
+	return errors.Is(w.ctx.Err(), context.DeadlineExceeded)
+}
+
+func (w *timeoutWriter) copyHeader() {
+	header := w.ResponseWriter.Header()
+	clear(header)
+	maps.Copy(header, w.header)
+}
+
+type CORSOptions struct {
+	// AllowedOrigins "*" allows any origin
+	AllowedOrigins []string
+	AllowedMethods []string
+	AllowedHeaders []string
+	ExposedHeaders []string
+	MaxAge         time.Duration
+}
+
+// DefaultCORSOptions allows any origin to read the API, exposing the headers needed for caching, concurrency control
+// and rate limiting
+var DefaultCORSOptions = CORSOptions{
+	AllowedOrigins: []string{"*"},
+	AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete},
+	AllowedHeaders: []string{"Authorization", "Content-Type", "If-Match", "If-None-Match", RequestIDHeader},
+	ExposedHeaders: []string{"ETag", RequestIDHeader, "Retry-After", RateLimitLimitHeader, RateLimitRemainingHeader, RateLimitResetHeader},
+	MaxAge:         10 * time.Minute,
+}
+
+// CORS answers preflight requests and sets the CORS headers for allowed origins
+func CORS(options CORSOptions) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			origin := request.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(response, request)
+
+				return
+			}
+
+			response.Header().Add("Vary", "Origin")
+			if !slices.Contains(options.AllowedOrigins, "*") && !slices.Contains(options.AllowedOrigins, origin) {
+				next.ServeHTTP(response, request)
+
+				return
+			}
+
+			response.Header().Set("Access-Control-Allow-Origin", origin)
+			if len(options.ExposedHeaders) > 0 {
+				response.Header().Set("Access-Control-Expose-Headers", strings.Join(options.ExposedHeaders, ", "))
+			}
+
+			isPreflight := request.Method == http.MethodOptions && request.Header.Get("Access-Control-Request-Method") != ""
+			if !isPreflight {
+				next.ServeHTTP(response, request)
+
+				return
+			}
+
+			response.Header().Set("Access-Control-Allow-Methods", strings.Join(options.AllowedMethods, ", "))
+			response.Header().Set("Access-Control-Allow-Headers", strings.Join(options.AllowedHeaders, ", "))
+			response.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(options.MaxAge.Seconds())))
+			response.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// Gzip compresses response bodies for clients accepting gzip, responses without body are left untouched
+func Gzip() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			response.Header().Add("Vary", "Accept-Encoding")
+			if !strings.Contains(request.Header.Get("Accept-Encoding"), "gzip") {
+				next.ServeHTTP(response, request)
+
+				return
+			}
+
+			writer := &gzipResponseWriter{ResponseWriter: response}
+			defer writer.Close()
+
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
+
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	gzip        *gzip.Writer
+	wroteHeader bool
+}
+
+func (w *gzipResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+
+	w.wroteHeader = true
+	if status != http.StatusNoContent && status != http.StatusNotModified && status >= http.StatusOK {
+		w.Header().Del("Content-Length")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.gzip = gzip.NewWriter(w.ResponseWriter)
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *gzipResponseWriter) Write(content []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.gzip == nil {
+		return w.ResponseWriter.Write(content)
+	}
+
+	return w.gzip.Write(content)
+}
+
+func (w *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *gzipResponseWriter) Close() {
+	if w.gzip != nil {
+		_ = w.gzip.Close()
+	}
+}
+
+// StatusRecorder remembers the status and size of the response written through it
+type StatusRecorder struct {
+	http.ResponseWriter
+	status       int
+	bytesWritten int
+}
+
+func NewStatusRecorder(response http.ResponseWriter) *StatusRecorder {
+	return &StatusRecorder{ResponseWriter: response}
+}
+
+func (r *StatusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *StatusRecorder) Write(content []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+
+	written, err := r.ResponseWriter.Write(content)
+	r.bytesWritten += written
+
+	return written, err
+}
+
+// Status is 200 when the handler wrote nothing, which is what net/http answers in that case
+func (r *StatusRecorder) Status() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+
+	return r.status
+}
+
+func (r *StatusRecorder) BytesWritten() int {
+	return r.bytesWritten
+}
+
+func (r *StatusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecover(t *testing.T) {
+	assertions := require.New(t)
+
+	handler := Chain(RequestID(), Recover())(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		panic("boom")
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assertions.Equal(http.StatusInternalServerError, recorder.Code)
+	assertions.JSONEq(`{"message": "internal server error", "app_code": "INTERNAL_SERVER_ERROR"}`, recorder.Body.String())
+	assertions.NotEmpty(recorder.Header().Get(RequestIDHeader))
+}
+
+func TestRequestID(t *testing.T) {
+	assertions := require.New(t)
+
+	var requestID string
+	handler := RequestID()(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		requestID = RequestIDFromContext(request.Context())
+	}))
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set(RequestIDHeader, "client-id")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	assertions.Equal("client-id", requestID)
+	assertions.Equal("client-id", recorder.Header().Get(RequestIDHeader))
+}
+
+func TestGzip(t *testing.T) {
+	assertions := require.New(t)
+
+	handler := Gzip()(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		Success(response, "content")
+	}))
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set("Accept-Encoding", "gzip, deflate")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	assertions.Equal("gzip", recorder.Header().Get("Content-Encoding"))
+	reader, err := gzip.NewReader(recorder.Body)
+	assertions.NoError(err)
+	body, err := io.ReadAll(reader)
+	assertions.NoError(err)
+	assertions.JSONEq(`{"content": "content"}`, string(body))
+}
+
+func TestCORS(t *testing.T) {
+	assertions := require.New(t)
+
+	calls := 0
+	handler := CORS(CORSOptions{
+		AllowedOrigins: []string{"https://shop.example"},
+		AllowedMethods: []string{http.MethodGet},
+		ExposedHeaders: []string{"ETag"},
+	})(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		calls++
+	}))
+
+	preflight := httptest.NewRequest(http.MethodOptions, "/", nil)
+	preflight.Header.Set("Origin", "https://shop.example")
+	preflight.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, preflight)
+
+	assertions.Equal(http.StatusNoContent, recorder.Code)
+	assertions.Equal("https://shop.example", recorder.Header().Get("Access-Control-Allow-Origin"))
+	assertions.Equal(http.MethodGet, recorder.Header().Get("Access-Control-Allow-Methods"))
+	assertions.Equal(0, calls)
+
+	other := httptest.NewRequest(http.MethodGet, "/", nil)
+	other.Header.Set("Origin", "https://other.example")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, other)
+
+	assertions.Empty(recorder.Header().Get("Access-Control-Allow-Origin"))
+	assertions.Equal(1, calls)
+}
+
+func TestTimeout(t *testing.T) {
+	assertions := require.New(t)
+
+	tests := []struct {
+		name               string
+		handler            http.HandlerFunc
+		expectedStatusCode int
+		expectedBody       string
+		expectedHeader     string
+	}{
+		{
+			name: "Answers written in time are kept",
+			handler: func(response http.ResponseWriter, request *http.Request) {
+				response.Header().Set("X-Handler", "set")
+				Success(response, "content")
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"content": "content"}`,
+			expectedHeader:     "set",
+		},
+		{
+			name: "Handlers writing nothing keep their headers",
+			handler: func(response http.ResponseWriter, request *http.Request) {
+				response.Header().Set("X-Handler", "set")
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedHeader:     "set",
+		},
+		{
+			name: "Handlers answering after the deadline are answered with a 504",
+			handler: func(response http.ResponseWriter, request *http.Request) {
+				<-request.Context().Done()
+				response.Header().Set("X-Handler", "set")
+				Success(response, "late content")
+			},
+			expectedStatusCode: http.StatusGatewayTimeout,
+			expectedBody:       `{"message": "request timed out", "app_code": "TIMEOUT"}`,
+		},
+		{
+			name: "Handlers returning after the deadline without answering are answered with a 504",
+			handler: func(response http.ResponseWriter, request *http.Request) {
+				<-request.Context().Done()
+			},
+			expectedStatusCode: http.StatusGatewayTimeout,
+			expectedBody:       `{"message": "request timed out", "app_code": "TIMEOUT"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Timeout(10 * time.Millisecond)(tt.handler)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			assertions.Equal(tt.expectedStatusCode, recorder.Code)
+			assertions.Equal(tt.expectedHeader, recorder.Header().Get("X-Handler"))
+			if tt.expectedBody != "" {
+				assertions.JSONEq(tt.expectedBody, recorder.Body.String())
+			}
+		})
+	}
+
+	t.Run("The timeout is sent while the handler still runs", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(Timeout(10 * time.Millisecond)(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			<-release
+			Success(response, "late content")
+		})))
+		defer server.Close()
+		defer close(release)
+
+		response, err := server.Client().Get(server.URL)
+		assertions.NoError(err)
+		defer response.Body.Close()
+
+		assertions.Equal(http.StatusGatewayTimeout, response.StatusCode)
+	})
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go-products.com/m/internal/shared/logging"
+)
+
+// Negotiate picks the encoder of successful responses from the Accept header, the first encoder is used when the
+// client accepts anything. Requests accepting none of them are answered with 406
+func Negotiate(encoders ...Encoder) Middleware {
+	supported := make([]string, 0, len(encoders))
+	for _, encoder := range encoders {
+		supported = append(supported, encoder.ContentType())
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			response.Header().Add("Vary", "Accept")
+
+			encoder, ok := negotiate(request.Header.Get("Accept"), encoders)
+			if !ok {
+				NotAcceptable(response, "none of the accepted media types can be produced, supported: "+strings.Join(supported, ", "))
+
+				return
+			}
+
+			next.ServeHTTP(&encoderWriter{ResponseWriter: response, encoder: encoder, logger: logging.FromContext(request.Context())}, request)
+		})
+	}
+}
+
+// mediaRange an entry of the Accept header
+type mediaRange struct {
+	mediaType string
+	quality   float64
+}
+
+// parseAccept entries with an invalid quality are ignored, a missing header accepts anything
+func parseAccept(accept string) []mediaRange {
+	if strings.TrimSpace(accept) == "" {
+		return []mediaRange{{mediaType: "*/*", quality: 1}}
+	}
+
+	ranges := make([]mediaRange, 0)
+	for _, entry := range strings.Split(accept, ",") {
+		mediaType, parameters, _ := strings.Cut(entry, ";")
+		accepted := mediaRange{mediaType: strings.ToLower(strings.TrimSpace(mediaType)), quality: 1}
+		if accepted.mediaType == "" {
+			continue
+		}
+
+		valid := true
+		for _, parameter := range strings.Split(parameters, ";") {
+			key, value, _ := strings.Cut(parameter, "=")
+			if strings.TrimSpace(key) != "q" {
+				continue
+			}
+
+			quality, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			valid = err == nil && quality >= 0 && quality <= 1
+			accepted.quality = quality
+		}
+
+		if valid {
+			ranges = append(ranges, accepted)
+		}
+	}
+
+	return ranges
+}
+
+// quality given to mediaType by the most specific range matching it, 0 when none does. Clients accepting problem
+// documents accept JSON, the media type of the documents they get on success, unless they say otherwise
+func quality(ranges []mediaRange, mediaType string) float64 {
+	mainType, _, _ := strings.Cut(mediaType, "/")
+	best, bestSpecificity := 0.0, -1
+	for _, accepted := range ranges {
+		specificity := -1
+		switch {
+		case accepted.mediaType == mediaType:
+			specificity = 3
+		case accepted.mediaType == ProblemContentType && mediaType == JSON.ContentType():
+			specificity = 2
+		case accepted.mediaType == mainType+"/*":
+			specificity = 1
+		case accepted.mediaType == "*/*":
+			specificity = 0
+		}
+
+		if specificity > bestSpecificity {
+			best, bestSpecificity = accepted.quality, specificity
+		}
+	}
+
+	return best
+}
+
+// negotiate the encoder with the highest quality, on ties the first one
+func negotiate(accept string, encoders []Encoder) (Encoder, bool) {
+	ranges := parseAccept(accept)
+
+	var chosen Encoder
+	chosenQuality := 0.0
+	for _, encoder := range encoders {
+		if encoderQuality := quality(ranges, encoder.ContentType()); encoderQuality > chosenQuality {
+			chosen, chosenQuality = encoder, encoderQuality
+		}
+	}
+
+	return chosen, chosen != nil
+}
+
+type contentEncoder interface {
+	responseEncoder() Encoder
+}
+
+type responseLogger interface {
+	responseLogger() *slog.Logger
+}
+
+// encoderWriter carries the encoder negotiated for the request, and the logger of the request, down to the response
+// helpers, which only get the response
+type encoderWriter struct {
+	http.ResponseWriter
+	encoder Encoder
+	logger  *slog.Logger
+}
+
+func (w *encoderWriter) responseEncoder() Encoder {
+	return w.encoder
+}
+
+func (w *encoderWriter) responseLogger() *slog.Logger {
+	return w.logger
+}
+
+func (w *encoderWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// encoderOf JSON when Negotiate did not run
+func encoderOf(response http.ResponseWriter) Encoder {
+	if writer, ok := findWriter[contentEncoder](response); ok {
+		return writer.responseEncoder()
+	}
+
+	return JSON
+}
+
+// loggerOf the logger of the request, the default one when Negotiate did not run
+func loggerOf(response http.ResponseWriter) *slog.Logger {
+	if writer, ok := findWriter[responseLogger](response); ok {
+		return writer.responseLogger()
+	}
+
+	return logging.FromContext(context.Background())
+}
+
+// findWriter looks for a writer implementing T through the writers wrapping the response
+func findWriter[T any](response http.ResponseWriter) (T, bool) {
+	for {
+		if writer, ok := response.(T); ok {
+			return writer, true
+		}
+
+		wrapper, ok := response.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			var zero T
+
+			return zero, false
+		}
+
+		response = wrapper.Unwrap()
+	}
+}
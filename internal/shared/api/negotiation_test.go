@@ -0,0 +1,234 @@
+package api
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+
+	"go-products.com/m/internal/shared/logging"
+)
+
+type negotiatedPrice struct {
+	Original           int     `json:"original" xml:"original"`
+	DiscountPercentage *string `json:"discount_percentage" xml:"discount_percentage"`
+}
+
+type negotiatedProduct struct {
+	Sku       string          `json:"sku" xml:"sku"`
+	Internal  string          `json:"-" xml:"-"`
+	Tags      []string        `json:"tags,omitempty" xml:"tags>tag"`
+	Price     negotiatedPrice `json:"price" xml:"price"`
+	UpdatedAt time.Time       `json:"updated_at" xml:"updated_at"`
+}
+
+func TestNegotiate(t *testing.T) {
+	assertions := require.New(t)
+
+	discount := "30%"
+	updatedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	products := []negotiatedProduct{
+		{Sku: "000001", Internal: "hidden", Tags: []string{"boots", "new"}, Price: negotiatedPrice{Original: 89000, DiscountPercentage: &discount}, UpdatedAt: updatedAt},
+		{Sku: "000002", Price: negotiatedPrice{Original: 99000}, UpdatedAt: updatedAt},
+	}
+
+	router := NewRouter(Negotiate(DefaultEncoders...))
+	router.HandleFunc(http.MethodGet, "/products", func(response http.ResponseWriter, request *http.Request) {
+		Success(response, products)
+	})
+	router.HandleFunc(http.MethodGet, "/missing", func(response http.ResponseWriter, request *http.Request) {
+		NotFound(response, "product not found")
+	})
+
+	tests := []struct {
+		name                string
+		path                string
+		accept              string
+		expectedStatusCode  int
+		expectedContentType string
+		expectedResponse    string
+	}{
+		{
+			name:                "JSON without Accept header",
+			path:                "/products",
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "application/json",
+			expectedResponse:    `{"content":[{"sku":"000001","tags":["boots","new"],"price":{"original":89000,"discount_percentage":"30%"},"updated_at":"2024-01-02T03:04:05Z"},{"sku":"000002","price":{"original":99000,"discount_percentage":null},"updated_at":"2024-01-02T03:04:05Z"}]}` + "\n",
+		},
+		{
+			name:                "JSON for clients accepting anything",
+			path:                "/products",
+			accept:              "*/*",
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "application/json",
+		},
+		{
+			name:                "XML",
+			path:                "/products",
+			accept:              "application/xml",
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "application/xml",
+			expectedResponse: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<response><content>` +
+				`<item><sku>000001</sku><tags><tag>boots</tag><tag>new</tag></tags><price><original>89000</original><discount_percentage>30%</discount_percentage></price><updated_at>2024-01-02T03:04:05Z</updated_at></item>` +
+				`<item><sku>000002</sku><tags></tags><price><original>99000</original></price><updated_at>2024-01-02T03:04:05Z</updated_at></item>` +
+				`</content></response>`,
+		},
+		{
+			name:                "CSV flattens nested structs",
+			path:                "/products",
+			accept:              "text/csv",
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "text/csv",
+			expectedResponse: "sku,tags,price.original,price.discount_percentage,updated_at\n" +
+				`000001,"[""boots"",""new""]",89000,30%,2024-01-02T03:04:05Z` + "\n" +
+				"000002,null,99000,,2024-01-02T03:04:05Z\n",
+		},
+		{
+			name:                "MessagePack",
+			path:                "/products",
+			accept:              "application/msgpack",
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "application/msgpack",
+		},
+		{
+			name:                "Highest quality wins",
+			path:                "/products",
+			accept:              "application/json;q=0.5, text/csv;q=0.9, application/xml;q=0.8",
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "text/csv",
+		},
+		{
+			name:                "Specific ranges override wildcards",
+			path:                "/products",
+			accept:              "application/*;q=0.9, application/json;q=0, application/msgpack;q=0.5",
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "application/xml",
+		},
+		{
+			name:                "JSON for clients accepting problem documents",
+			path:                "/products",
+			accept:              "application/problem+json",
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "application/json",
+		},
+		{
+			name:                "Explicit ranges override problem documents",
+			path:                "/products",
+			accept:              "application/problem+json, application/json;q=0.1, application/xml;q=0.5",
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "application/xml",
+		},
+		{
+			name:                "Nothing acceptable",
+			path:                "/products",
+			accept:              "text/html, application/json;q=0",
+			expectedStatusCode:  http.StatusNotAcceptable,
+			expectedContentType: "application/json",
+			expectedResponse:    `{"message":"none of the accepted media types can be produced, supported: application/json, application/xml, text/csv, application/msgpack","app_code":"NOT_ACCEPTABLE"}` + "\n",
+		},
+		{
+			name:                "Errors stay JSON",
+			path:                "/missing",
+			accept:              "text/csv",
+			expectedStatusCode:  http.StatusNotFound,
+			expectedContentType: "application/json",
+			expectedResponse:    `{"message":"product not found","app_code":"NOT_FOUND"}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			request.Header.Set("Accept", tt.accept)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			assertions.Equal(tt.expectedStatusCode, recorder.Code)
+			assertions.Equal(tt.expectedContentType, recorder.Header().Get("Content-Type"))
+			assertions.Equal("Accept", recorder.Header().Get("Vary"))
+			if tt.expectedResponse != "" {
+				assertions.Equal(tt.expectedResponse, recorder.Body.String())
+			}
+		})
+	}
+}
+
+// MessagePack documents have the keys of the JSON ones, times are written with the timestamp extension
+func TestMessagePack(t *testing.T) {
+	assertions := require.New(t)
+
+	discount := "30%"
+	updatedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	products := []negotiatedProduct{
+		{Sku: "000001", Internal: "hidden", Tags: []string{"boots"}, Price: negotiatedPrice{Original: 89000, DiscountPercentage: &discount}, UpdatedAt: updatedAt},
+		{Sku: "000002", Price: negotiatedPrice{Original: 99000}, UpdatedAt: updatedAt},
+	}
+
+	var buffer bytes.Buffer
+	assertions.NoError(MessagePack.Encode(&buffer, products))
+
+	var document map[string]any
+	decoder := msgpack.NewDecoder(&buffer)
+	decoder.UseLooseInterfaceDecoding(true)
+	assertions.NoError(decoder.Decode(&document))
+
+	content, ok := document["content"].([]any)
+	assertions.True(ok)
+	assertions.Len(content, 2)
+	for _, product := range content {
+		updated, ok := product.(map[string]any)["updated_at"].(time.Time)
+		assertions.True(ok)
+		assertions.True(updatedAt.Equal(updated))
+		delete(product.(map[string]any), "updated_at")
+	}
+
+	assertions.Equal([]any{
+		map[string]any{"sku": "000001", "tags": []any{"boots"}, "price": map[string]any{"original": uint64(89000), "discount_percentage": "30%"}},
+		map[string]any{"sku": "000002", "price": map[string]any{"original": uint64(99000), "discount_percentage": nil}},
+	}, content)
+}
+
+func TestNegotiate_CachedResponses(t *testing.T) {
+	assertions := require.New(t)
+
+	router := NewRouter(Negotiate(DefaultEncoders...))
+	router.HandleFunc(http.MethodGet, "/products/{sku}", func(response http.ResponseWriter, request *http.Request) {
+		Success(response, negotiatedPrice{Original: 89000})
+	}, Cache(DefaultCachePolicy, nil))
+
+	request := httptest.NewRequest(http.MethodGet, "/products/000001", nil)
+	request.Header.Set("Accept", "text/csv")
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	assertions.Equal(http.StatusOK, recorder.Code)
+	assertions.Equal("text/csv", recorder.Header().Get("Content-Type"))
+	assertions.Equal("original,discount_percentage\n89000,\n", recorder.Body.String())
+}
+
+func TestNegotiate_EncodingErrors(t *testing.T) {
+	assertions := require.New(t)
+
+	var logs bytes.Buffer
+	router := NewRouter(Negotiate(DefaultEncoders...))
+	router.HandleFunc(http.MethodGet, "/products", func(response http.ResponseWriter, request *http.Request) {
+		Success(response, make(chan int))
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/products", nil)
+	request = request.WithContext(logging.WithLogger(request.Context(), slog.New(slog.NewJSONHandler(&logs, nil)).With(slog.String("request_id", "42"))))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	assertions.Equal(http.StatusInternalServerError, recorder.Code)
+	assertions.Contains(logs.String(), `"msg":"encoding response"`)
+	assertions.Contains(logs.String(), `"request_id":"42"`)
+}
@@ -1,9 +1,26 @@
 package api
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
 )
 
+var ErrInvalidBody = errors.New("request body must be a valid json")
+
 func GetQueryParam(r *http.Request, key string) string {
 	return r.URL.Query().Get(key)
 }
+
+func GetPathParam(r *http.Request, key string) string {
+	return r.PathValue(key)
+}
+
+func DecodeBody[T any](r *http.Request) (T, error) {
+	var body T
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return body, ErrInvalidBody
+	}
+
+	return body, nil
+}
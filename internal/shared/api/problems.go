@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+const (
+	// ErrorFormatJSON errors are answered as ErrorResponse
+	ErrorFormatJSON = "json"
+	// ErrorFormatProblem errors are answered as RFC 7807 problem documents
+	ErrorFormatProblem = "problem"
+
+	ProblemContentType = "application/problem+json"
+)
+
+// Problem RFC 7807 document, app_code and the invalid fields are kept as extension members
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	AppCode  string       `json:"app_code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// ErrorFormat answers errors as problem documents when format is ErrorFormatProblem or the client accepts
+// application/problem+json, otherwise as ErrorResponse. It must run before any middleware writing errors
+func ErrorFormat(format string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			response.Header().Add("Vary", "Accept")
+
+			problem := format == ErrorFormatProblem || accepts(request.Header.Get("Accept"), ProblemContentType)
+			next.ServeHTTP(&errorFormatWriter{
+				ResponseWriter: response,
+				format:         errorFormat{problem: problem, instance: request.URL.RequestURI()},
+			}, request)
+		})
+	}
+}
+
+type errorFormat struct {
+	problem  bool
+	instance string
+}
+
+type errorFormatter interface {
+	errorFormat() errorFormat
+}
+
+// errorFormatWriter carries the format chosen for the request down to the response helpers
+type errorFormatWriter struct {
+	http.ResponseWriter
+	format errorFormat
+}
+
+func (w *errorFormatWriter) errorFormat() errorFormat {
+	return w.format
+}
+
+func (w *errorFormatWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// errorFormatOf ErrorResponse is used when ErrorFormat did not run
+func errorFormatOf(response http.ResponseWriter) errorFormat {
+	if writer, ok := findWriter[errorFormatter](response); ok {
+		return writer.errorFormat()
+	}
+
+	return errorFormat{}
+}
+
+// writeError renders every error response, in the format chosen by ErrorFormat
+func writeError(response http.ResponseWriter, status int, body ErrorResponse) {
+	format := errorFormatOf(response)
+	if !format.problem {
+		response.Header().Set("Content-Type", "application/json")
+		response.WriteHeader(status)
+		_ = json.NewEncoder(response).Encode(body)
+
+		return
+	}
+
+	title := http.StatusText(status)
+	if status == StatusClientClosedRequest {
+		title = "Client Closed Request"
+	}
+
+	response.Header().Set("Content-Type", ProblemContentType)
+	response.WriteHeader(status)
+	_ = json.NewEncoder(response).Encode(Problem{
+		Type:     "about:blank",
+		Title:    title,
+		Status:   status,
+		Detail:   body.Message,
+		Instance: format.instance,
+		AppCode:  body.AppCode,
+		Errors:   body.Errors,
+	})
+}
+
+// accepts whether the Accept header lists mediaType itself, wildcards are not enough
+func accepts(accept, mediaType string) bool {
+	for _, accepted := range parseAccept(accept) {
+		if accepted.mediaType == mediaType {
+			return accepted.quality > 0
+		}
+	}
+
+	return false
+}
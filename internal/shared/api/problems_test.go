@@ -0,0 +1,127 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorFormat(t *testing.T) {
+	assertions := require.New(t)
+
+	newRouter := func(format string) *Router {
+		router := NewRouter(AccessLog(), ErrorFormat(format), Recover(), Gzip())
+		router.HandleFunc(http.MethodPut, "/products/{sku}", func(response http.ResponseWriter, request *http.Request) {
+			InvalidFields(response, "price must be greater than 0", []FieldError{{Field: "price", Code: "INVALID_PRICE", Message: "price must be greater than 0"}})
+		})
+		router.HandleFunc(http.MethodGet, "/panic", func(response http.ResponseWriter, request *http.Request) {
+			panic("boom")
+		})
+
+		return router
+	}
+
+	tests := []struct {
+		name                string
+		format              string
+		method              string
+		path                string
+		accept              string
+		acceptEncoding      string
+		expectedStatusCode  int
+		expectedContentType string
+		expectedResponse    string
+	}{
+		{
+			name:                "Errors are JSON by default",
+			format:              ErrorFormatJSON,
+			method:              http.MethodPut,
+			path:                "/products/000001",
+			expectedStatusCode:  http.StatusBadRequest,
+			expectedContentType: "application/json",
+			expectedResponse:    `{"message": "price must be greater than 0", "app_code": "INVALID_REQUEST", "errors": [{"field": "price", "code": "INVALID_PRICE", "message": "price must be greater than 0"}]}`,
+		},
+		{
+			name:                "Clients accepting problems get problems",
+			format:              ErrorFormatJSON,
+			method:              http.MethodPut,
+			path:                "/products/000001",
+			accept:              "application/json;q=0.5, application/problem+json",
+			acceptEncoding:      "gzip",
+			expectedStatusCode:  http.StatusBadRequest,
+			expectedContentType: ProblemContentType,
+			expectedResponse:    `{"type": "about:blank", "title": "Bad Request", "status": 400, "detail": "price must be greater than 0", "instance": "/products/000001", "app_code": "INVALID_REQUEST", "errors": [{"field": "price", "code": "INVALID_PRICE", "message": "price must be greater than 0"}]}`,
+		},
+		{
+			name:                "Problems rejected by the client are not sent",
+			format:              ErrorFormatJSON,
+			method:              http.MethodGet,
+			path:                "/missing",
+			accept:              "application/problem+json;q=0",
+			expectedStatusCode:  http.StatusNotFound,
+			expectedContentType: "application/json",
+			expectedResponse:    `{"message": "route not found", "app_code": "NOT_FOUND"}`,
+		},
+		{
+			name:                "Configured problems are sent to every client",
+			format:              ErrorFormatProblem,
+			method:              http.MethodDelete,
+			path:                "/products/000001?force=true",
+			expectedStatusCode:  http.StatusMethodNotAllowed,
+			expectedContentType: ProblemContentType,
+			expectedResponse:    `{"type": "about:blank", "title": "Method Not Allowed", "status": 405, "detail": "Method not allowed", "instance": "/products/000001?force=true", "app_code": "METHOD_NOT_ALLOWED"}`,
+		},
+		{
+			name:                "Panics are answered as problems",
+			format:              ErrorFormatProblem,
+			method:              http.MethodGet,
+			path:                "/panic",
+			expectedStatusCode:  http.StatusInternalServerError,
+			expectedContentType: ProblemContentType,
+			expectedResponse:    `{"type": "about:blank", "title": "Internal Server Error", "status": 500, "detail": "internal server error", "instance": "/panic", "app_code": "INTERNAL_SERVER_ERROR"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(tt.method, tt.path, nil)
+			request.Header.Set("Accept", tt.accept)
+			request.Header.Set("Accept-Encoding", tt.acceptEncoding)
+
+			recorder := httptest.NewRecorder()
+			newRouter(tt.format).ServeHTTP(recorder, request)
+
+			var body io.Reader = recorder.Body
+			if recorder.Header().Get("Content-Encoding") == "gzip" {
+				reader, err := gzip.NewReader(recorder.Body)
+				assertions.NoError(err)
+				body = reader
+			}
+
+			content, err := io.ReadAll(body)
+			assertions.NoError(err)
+			assertions.Equal(tt.expectedStatusCode, recorder.Code)
+			assertions.Equal(tt.expectedContentType, recorder.Header().Get("Content-Type"))
+			assertions.JSONEq(tt.expectedResponse, string(content))
+		})
+	}
+}
+
+func TestErrorFormat_BufferedResponses(t *testing.T) {
+	assertions := require.New(t)
+
+	router := NewRouter(ErrorFormat(ErrorFormatProblem))
+	router.HandleFunc(http.MethodGet, "/products/{sku}", func(response http.ResponseWriter, request *http.Request) {
+		NotFound(response, "product not found")
+	}, Cache(DefaultCachePolicy, nil))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/products/000009", nil))
+
+	assertions.Equal(http.StatusNotFound, recorder.Code)
+	assertions.Equal(ProblemContentType, recorder.Header().Get("Content-Type"))
+	assertions.JSONEq(`{"type": "about:blank", "title": "Not Found", "status": 404, "detail": "product not found", "instance": "/products/000009", "app_code": "NOT_FOUND"}`, recorder.Body.String())
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-products.com/m/internal/shared/auth"
+	"go-products.com/m/internal/shared/logging"
+	"go-products.com/m/internal/shared/ratelimit"
+)
+
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset"
+)
+
+// RateLimitPolicy limits by route, routes are named by method and pattern, GET /api/v1/products. Routes without their
+// own limit use Default
+type RateLimitPolicy struct {
+	Default ratelimit.Limit
+	Routes  map[string]ratelimit.Limit
+	// Authentication limit by address of the requests carrying credentials, taken before they are checked
+	Authentication ratelimit.Limit
+	// ClientIPHeader header where a trusted proxy puts the client address, the first one of a list is used. The
+	// connection address is used when empty
+	ClientIPHeader string
+}
+
+func (p RateLimitPolicy) limit(route string) ratelimit.Limit {
+	if limit, ok := p.Routes[route]; ok {
+		return limit
+	}
+
+	return p.Default
+}
+
+// RateLimit gives every client a token bucket per route, authenticated clients are identified by their principal and
+// anonymous ones by address. Requests are let through when the store fails, limiting is not worth an outage. It must
+// wrap routes, as it needs the matched route, and run after Authenticate
+func RateLimit(store ratelimit.Store, policy RateLimitPolicy) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			method := request.Method
+			if method == http.MethodHead {
+				method = http.MethodGet
+			}
+
+			route := method + " " + routeFromContext(request.Context())
+			if take(response, request, store, route+"|"+clientKey(request, policy.ClientIPHeader), policy.limit(route)) {
+				next.ServeHTTP(response, request)
+			}
+		})
+	}
+}
+
+// RateLimitAuthentication limits by address the requests carrying credentials before they are checked, so guessing
+// keys or sending tokens signed by unknown keys can't saturate the authenticator. Requests without credentials and
+// every request when store is nil go through. It must run before Authenticate
+func RateLimitAuthentication(store ratelimit.Store, policy RateLimitPolicy) Middleware {
+	return func(next http.Handler) http.Handler {
+		if store == nil || !policy.Authentication.Enabled() {
+			return next
+		}
+
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			if request.Header.Get("Authorization") == "" {
+				next.ServeHTTP(response, request)
+
+				return
+			}
+
+			if take(response, request, store, "authentication|"+clientKey(request, policy.ClientIPHeader), policy.Authentication) {
+				next.ServeHTTP(response, request)
+			}
+		})
+	}
+}
+
+// take a token from the bucket of key, requests denied are answered and false is returned
+func take(response http.ResponseWriter, request *http.Request, store ratelimit.Store, key string, limit ratelimit.Limit) bool {
+	if !limit.Enabled() {
+		return true
+	}
+
+	result, err := store.Take(request.Context(), key, limit, time.Now())
+	if err != nil {
+		logging.FromContext(request.Context()).Warn("rate limiting request, letting it through", slog.Any("error", err))
+
+		return true
+	}
+
+	response.Header().Set(RateLimitLimitHeader, strconv.Itoa(result.Limit))
+	response.Header().Set(RateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+	response.Header().Set(RateLimitResetHeader, strconv.Itoa(ceilSeconds(result.Reset)))
+
+	if !result.Allowed {
+		retryAfter := ceilSeconds(result.RetryAfter)
+		response.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		TooManyRequests(response, fmt.Sprintf("rate limit exceeded, retry in %d seconds", retryAfter))
+
+		return false
+	}
+
+	return true
+}
+
+func clientKey(request *http.Request, clientIPHeader string) string {
+	if principal, ok := auth.PrincipalFromContext(request.Context()); ok {
+		return "principal:" + principal.Subject
+	}
+
+	if clientIPHeader != "" {
+		if forwarded, _, _ := strings.Cut(request.Header.Get(clientIPHeader), ","); strings.TrimSpace(forwarded) != "" {
+			return "ip:" + strings.TrimSpace(forwarded)
+		}
+	}
+
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		host = request.RemoteAddr
+	}
+
+	return "ip:" + host
+}
+
+// ceilSeconds headers carry whole seconds, rounding up so clients never retry too early
+func ceilSeconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
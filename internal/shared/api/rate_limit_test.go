@@ -0,0 +1,163 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go-products.com/m/internal/shared/auth"
+	"go-products.com/m/internal/shared/ratelimit"
+)
+
+type failingStore struct{}
+
+func (failingStore) Take(ctx context.Context, key string, limit ratelimit.Limit, now time.Time) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("database is locked")
+}
+
+func newRateLimitedRouter(store ratelimit.Store) *Router {
+	authenticator := auth.AuthenticatorFunc(func(ctx context.Context, token string) (auth.Principal, error) {
+		return auth.Principal{Subject: token, Role: auth.RoleViewer}, nil
+	})
+
+	router := NewRouter(Authenticate(authenticator))
+	limited := router.With(RateLimit(store, RateLimitPolicy{
+		// a token every 1000 seconds, buckets are never refilled during the test
+		Default: ratelimit.Limit{Rate: 0.001, Burst: 2},
+		Routes: map[string]ratelimit.Limit{
+			"GET /products/{sku}":    {Rate: 0.001, Burst: 1},
+			"DELETE /products/{sku}": {},
+		},
+		ClientIPHeader: "X-Forwarded-For",
+	}))
+	limited.HandleFunc(http.MethodGet, "/products", func(response http.ResponseWriter, request *http.Request) {
+		NoContent(response)
+	})
+	limited.HandleFunc(http.MethodGet, "/products/{sku}", func(response http.ResponseWriter, request *http.Request) {
+		NoContent(response)
+	})
+	limited.HandleFunc(http.MethodDelete, "/products/{sku}", func(response http.ResponseWriter, request *http.Request) {
+		NoContent(response)
+	})
+
+	return router
+}
+
+func TestRateLimit(t *testing.T) {
+	assertions := require.New(t)
+
+	router := newRateLimitedRouter(ratelimit.NewMemoryStore())
+	serve := func(method, path string, headers map[string]string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, nil)
+		request.RemoteAddr = "192.0.2.1:51234"
+		for name, value := range headers {
+			request.Header.Set(name, value)
+		}
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	for _, remaining := range []string{"1", "0"} {
+		response := serve(http.MethodGet, "/products", nil)
+		assertions.Equal(http.StatusNoContent, response.Code)
+		assertions.Equal("2", response.Header().Get(RateLimitLimitHeader))
+		assertions.Equal(remaining, response.Header().Get(RateLimitRemainingHeader))
+	}
+
+	response := serve(http.MethodGet, "/products", nil)
+	assertions.Equal(http.StatusTooManyRequests, response.Code)
+	assertions.JSONEq(`{"message": "rate limit exceeded, retry in 1000 seconds", "app_code": "RATE_LIMITED"}`, response.Body.String())
+	assertions.Equal("1000", response.Header().Get("Retry-After"))
+	assertions.Equal("0", response.Header().Get(RateLimitRemainingHeader))
+	assertions.Equal("2000", response.Header().Get(RateLimitResetHeader))
+
+	assertions.Equal(http.StatusTooManyRequests, serve(http.MethodHead, "/products", nil).Code, "HEAD shares the GET bucket")
+	assertions.Equal(http.StatusNoContent, serve(http.MethodGet, "/products", map[string]string{"Authorization": "Bearer key-1"}).Code,
+		"authenticated clients are limited by principal")
+	assertions.Equal(http.StatusNoContent, serve(http.MethodGet, "/products", map[string]string{"X-Forwarded-For": "198.51.100.7, 192.0.2.1"}).Code,
+		"the proxy header identifies the client")
+
+	// routes are limited by pattern, not by path
+	assertions.Equal(http.StatusNoContent, serve(http.MethodGet, "/products/000001", nil).Code)
+	assertions.Equal(http.StatusTooManyRequests, serve(http.MethodGet, "/products/000002", nil).Code)
+
+	for i := 0; i < 5; i++ {
+		assertions.Equal(http.StatusNoContent, serve(http.MethodDelete, "/products/000001", nil).Code, "a zero limit disables limiting")
+	}
+}
+
+func TestRateLimit_StoreFailure(t *testing.T) {
+	assertions := require.New(t)
+
+	recorder := httptest.NewRecorder()
+	newRateLimitedRouter(failingStore{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/products", nil))
+
+	assertions.Equal(http.StatusNoContent, recorder.Code)
+	assertions.Empty(recorder.Header().Get(RateLimitLimitHeader))
+}
+
+func TestRateLimitAuthentication(t *testing.T) {
+	assertions := require.New(t)
+
+	checked := 0
+	authenticator := auth.AuthenticatorFunc(func(ctx context.Context, token string) (auth.Principal, error) {
+		checked++
+
+		return auth.Principal{}, auth.ErrInvalidCredentials
+	})
+
+	router := NewRouter(
+		RateLimitAuthentication(ratelimit.NewMemoryStore(), RateLimitPolicy{
+			Authentication: ratelimit.Limit{Rate: 0.001, Burst: 2},
+		}),
+		Authenticate(authenticator),
+	)
+	router.HandleFunc(http.MethodGet, "/products", func(response http.ResponseWriter, request *http.Request) {
+		NoContent(response)
+	})
+	serve := func(headers map[string]string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/products", nil)
+		request.RemoteAddr = "192.0.2.1:51234"
+		for name, value := range headers {
+			request.Header.Set(name, value)
+		}
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	for i, token := range []string{"guess-1", "guess-2"} {
+		response := serve(map[string]string{"Authorization": "Bearer " + token})
+		assertions.Equal(http.StatusUnauthorized, response.Code)
+		assertions.Equal(i+1, checked)
+	}
+
+	response := serve(map[string]string{"Authorization": "Bearer guess-3"})
+	assertions.Equal(http.StatusTooManyRequests, response.Code)
+	assertions.Equal("1000", response.Header().Get("Retry-After"))
+	assertions.Equal(2, checked, "credentials are not checked once the address is limited")
+
+	assertions.Equal(http.StatusNoContent, serve(nil).Code, "requests without credentials are not limited")
+
+	unlimited := RateLimitAuthentication(nil, RateLimitPolicy{Authentication: ratelimit.Limit{Rate: 0.001, Burst: 1}})(
+		http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			NoContent(response)
+		}))
+	for i := 0; i < 3; i++ {
+		request := httptest.NewRequest(http.MethodGet, "/products", nil)
+		request.Header.Set("Authorization", "Bearer guess")
+		recorder := httptest.NewRecorder()
+		unlimited.ServeHTTP(recorder, request)
+		assertions.Equal(http.StatusNoContent, recorder.Code, "a nil store disables limiting")
+	}
+}
@@ -1,7 +1,8 @@
 package api
 
 import (
-	"encoding/json"
+	"bytes"
+	"log/slog"
 	"net/http"
 )
 
@@ -12,34 +13,120 @@ type SuccessResponse struct {
 type ErrorResponse struct {
 	Message string `json:"message"`
 	AppCode string `json:"app_code"`
+	// Errors invalid fields of the request, only sent for validation errors
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError an invalid field, Code is stable and meant for clients while Message is meant for humans
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
 }
 
 const (
-	InvalidRequestCode      = "INVALID_REQUEST"
-	InternalServerErrorCode = "INTERNAL_SERVER_ERROR"
-	MethodNotAllowedCode    = "METHOD_NOT_ALLOWED"
+	InvalidRequestCode       = "INVALID_REQUEST"
+	InternalServerErrorCode  = "INTERNAL_SERVER_ERROR"
+	MethodNotAllowedCode     = "METHOD_NOT_ALLOWED"
+	ConflictCode             = "CONFLICT"
+	NotFoundCode             = "NOT_FOUND"
+	PreconditionFailedCode   = "PRECONDITION_FAILED"
+	PreconditionRequiredCode = "PRECONDITION_REQUIRED"
+	UnauthorizedCode         = "UNAUTHORIZED"
+	ForbiddenCode            = "FORBIDDEN"
+	RateLimitedCode          = "RATE_LIMITED"
+	RequestCancelledCode     = "REQUEST_CANCELLED"
+	TimeoutCode              = "TIMEOUT"
+	NotAcceptableCode        = "NOT_ACCEPTABLE"
 )
 
+// StatusClientClosedRequest non standard status, popularized by nginx, for requests the client gave up on. It is
+// mostly seen in logs and metrics as the client is gone
+const StatusClientClosedRequest = 499
+
 func Success(response http.ResponseWriter, data interface{}) {
-	response.Header().Set("Content-Type", "application/json")
-	response.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(response).Encode(SuccessResponse{Content: data})
+	writeContent(response, http.StatusOK, data)
+}
+
+func Created(response http.ResponseWriter, data interface{}) {
+	writeContent(response, http.StatusCreated, data)
+}
+
+// writeContent renders every successful response with the encoder chosen by Negotiate. Content is encoded before
+// anything is written so content that can't be encoded is answered with an error
+func writeContent(response http.ResponseWriter, status int, content interface{}) {
+	encoder := encoderOf(response)
+
+	var body bytes.Buffer
+	if err := encoder.Encode(&body, content); err != nil {
+		loggerOf(response).Error("encoding response", slog.String("content_type", encoder.ContentType()), slog.Any("error", err))
+		InternalServerError(response, "internal server error")
+
+		return
+	}
+
+	response.Header().Set("Content-Type", encoder.ContentType())
+	response.WriteHeader(status)
+	_, _ = response.Write(body.Bytes())
+}
+
+func NoContent(response http.ResponseWriter) {
+	response.WriteHeader(http.StatusNoContent)
 }
 
 func InternalServerError(response http.ResponseWriter, message string) {
-	response.Header().Set("Content-Type", "application/json")
-	response.WriteHeader(http.StatusInternalServerError)
-	_ = json.NewEncoder(response).Encode(ErrorResponse{Message: message, AppCode: InternalServerErrorCode})
+	writeError(response, http.StatusInternalServerError, ErrorResponse{Message: message, AppCode: InternalServerErrorCode})
 }
 
 func InvalidRequest(response http.ResponseWriter, message string) {
-	response.Header().Set("Content-Type", "application/json")
-	response.WriteHeader(http.StatusBadRequest)
-	_ = json.NewEncoder(response).Encode(ErrorResponse{Message: message, AppCode: InvalidRequestCode})
+	writeError(response, http.StatusBadRequest, ErrorResponse{Message: message, AppCode: InvalidRequestCode})
+}
+
+// InvalidFields answers a request failing validation with every invalid field
+func InvalidFields(response http.ResponseWriter, message string, fields []FieldError) {
+	writeError(response, http.StatusBadRequest, ErrorResponse{Message: message, AppCode: InvalidRequestCode, Errors: fields})
+}
+
+func NotAcceptable(response http.ResponseWriter, message string) {
+	writeError(response, http.StatusNotAcceptable, ErrorResponse{Message: message, AppCode: NotAcceptableCode})
+}
+
+func NotFound(response http.ResponseWriter, message string) {
+	writeError(response, http.StatusNotFound, ErrorResponse{Message: message, AppCode: NotFoundCode})
+}
+
+func Conflict(response http.ResponseWriter, message string) {
+	writeError(response, http.StatusConflict, ErrorResponse{Message: message, AppCode: ConflictCode})
 }
 
 func methodNotAllowed(response http.ResponseWriter) {
-	response.Header().Set("Content-Type", "application/json")
-	response.WriteHeader(http.StatusMethodNotAllowed)
-	_ = json.NewEncoder(response).Encode(ErrorResponse{Message: "Method not allowed", AppCode: MethodNotAllowedCode})
+	writeError(response, http.StatusMethodNotAllowed, ErrorResponse{Message: "Method not allowed", AppCode: MethodNotAllowedCode})
+}
+
+func PreconditionFailed(response http.ResponseWriter, message string) {
+	writeError(response, http.StatusPreconditionFailed, ErrorResponse{Message: message, AppCode: PreconditionFailedCode})
+}
+
+func PreconditionRequired(response http.ResponseWriter, message string) {
+	writeError(response, http.StatusPreconditionRequired, ErrorResponse{Message: message, AppCode: PreconditionRequiredCode})
+}
+
+func Unauthorized(response http.ResponseWriter, message string) {
+	writeError(response, http.StatusUnauthorized, ErrorResponse{Message: message, AppCode: UnauthorizedCode})
+}
+
+func Forbidden(response http.ResponseWriter, message string) {
+	writeError(response, http.StatusForbidden, ErrorResponse{Message: message, AppCode: ForbiddenCode})
+}
+
+func TooManyRequests(response http.ResponseWriter, message string) {
+	writeError(response, http.StatusTooManyRequests, ErrorResponse{Message: message, AppCode: RateLimitedCode})
+}
+
+func ClientClosedRequest(response http.ResponseWriter, message string) {
+	writeError(response, StatusClientClosedRequest, ErrorResponse{Message: message, AppCode: RequestCancelledCode})
+}
+
+func GatewayTimeout(response http.ResponseWriter, message string) {
+	writeError(response, http.StatusGatewayTimeout, ErrorResponse{Message: message, AppCode: TimeoutCode})
 }
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Router registers handlers using method patterns (GET /api/v1/products/{sku}) on top of http.ServeMux, answering
+// unknown routes and unsupported methods with JSON errors. Middlewares given to NewRouter wrap every request while
+// the ones given to With or Handle only wrap the routes registered through them
+type Router struct {
+	routes      *routes
+	middlewares []Middleware
+}
+
+type routes struct {
+	mux     *http.ServeMux
+	mu      sync.RWMutex
+	methods map[string][]string
+	handler http.Handler
+}
+
+func NewRouter(middlewares ...Middleware) *Router {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(response http.ResponseWriter, request *http.Request) {
+		NotFound(response, "route not found")
+	})
+
+	return &Router{
+		routes: &routes{
+			mux:     mux,
+			methods: make(map[string][]string),
+			handler: Chain(middlewares...)(mux),
+		},
+	}
+}
+
+// With returns a router sharing the same routes whose registrations are wrapped by middlewares
+func (r *Router) With(middlewares ...Middleware) *Router {
+	return &Router{
+		routes:      r.routes,
+		middlewares: append(slices.Clone(r.middlewares), middlewares...),
+	}
+}
+
+func (r *Router) Handle(method, path string, handler http.Handler, middlewares ...Middleware) {
+	r.routes.mu.Lock()
+	defer r.routes.mu.Unlock()
+
+	if _, ok := r.routes.methods[path]; !ok {
+		r.routes.mux.Handle(path, matchRoute(path, r.routes.methodNotAllowed(path)))
+	}
+
+	r.routes.methods[path] = append(r.routes.methods[path], method)
+	r.routes.mux.Handle(method+" "+path, matchRoute(path, Chain(append(slices.Clone(r.middlewares), middlewares...)...)(handler)))
+}
+
+func (r *Router) HandleFunc(method, path string, handler http.HandlerFunc, middlewares ...Middleware) {
+	r.Handle(method, path, handler, middlewares...)
+}
+
+func (r *Router) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+	r.routes.handler.ServeHTTP(response, request)
+}
+
+func (r *routes) methodNotAllowed(path string) http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		r.mu.RLock()
+		allowed := slices.Clone(r.methods[path])
+		r.mu.RUnlock()
+
+		if slices.Contains(allowed, http.MethodGet) {
+			allowed = append(allowed, http.MethodHead)
+		}
+
+		slices.Sort(allowed)
+		response.Header().Set("Allow", strings.Join(allowed, ", "))
+		methodNotAllowed(response)
+	}
+}
+
+type matchedRouteKey struct{}
+
+type matchedRoute struct {
+	path string
+}
+
+// withMatchedRoute lets middlewares running before the mux find out which route pattern served the request, the
+// request seen by the mux is a copy so the pattern can't be read back from it. Middlewares share the same route
+func withMatchedRoute(ctx context.Context) (context.Context, *matchedRoute) {
+	if route, ok := ctx.Value(matchedRouteKey{}).(*matchedRoute); ok {
+		return ctx, route
+	}
+
+	route := &matchedRoute{}
+
+	return context.WithValue(ctx, matchedRouteKey{}, route), route
+}
+
+// routeFromContext the route pattern serving the request, empty before the request is routed
+func routeFromContext(ctx context.Context) string {
+	if route, ok := ctx.Value(matchedRouteKey{}).(*matchedRoute); ok {
+		return route.path
+	}
+
+	return ""
+}
+
+// matchRoute records the route pattern for the middlewares running before the mux and for the ones wrapping the route
+func matchRoute(path string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		ctx, route := withMatchedRoute(request.Context())
+		route.path = path
+
+		next.ServeHTTP(response, request.WithContext(ctx))
+	})
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter(t *testing.T) {
+	assertions := require.New(t)
+
+	trace := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+				response.Header().Add("X-Trace", name)
+				next.ServeHTTP(response, request)
+			})
+		}
+	}
+
+	router := NewRouter(trace("global"))
+	router.HandleFunc(http.MethodGet, "/items/{id}", func(response http.ResponseWriter, request *http.Request) {
+		Success(response, GetPathParam(request, "id"))
+	}, trace("route"))
+	router.With(trace("group")).HandleFunc(http.MethodDelete, "/items/{id}", func(response http.ResponseWriter, request *http.Request) {
+		NoContent(response)
+	})
+
+	tests := []struct {
+		name               string
+		method             string
+		path               string
+		expectedStatusCode int
+		expectedBody       string
+		expectedTrace      []string
+		expectedAllow      string
+	}{
+		{
+			name:               "Matching route runs global and route middlewares",
+			method:             http.MethodGet,
+			path:               "/items/1",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"content": "1"}`,
+			expectedTrace:      []string{"global", "route"},
+		},
+		{
+			name:               "Second method on the same path runs its group middlewares",
+			method:             http.MethodDelete,
+			path:               "/items/1",
+			expectedStatusCode: http.StatusNoContent,
+			expectedTrace:      []string{"global", "group"},
+		},
+		{
+			name:               "Unsupported method returns a JSON 405 with the allowed methods",
+			method:             http.MethodPost,
+			path:               "/items/1",
+			expectedStatusCode: http.StatusMethodNotAllowed,
+			expectedBody:       `{"message": "Method not allowed", "app_code": "METHOD_NOT_ALLOWED"}`,
+			expectedTrace:      []string{"global"},
+			expectedAllow:      "DELETE, GET, HEAD",
+		},
+		{
+			name:               "Unknown route returns a JSON 404",
+			method:             http.MethodGet,
+			path:               "/unknown",
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       `{"message": "route not found", "app_code": "NOT_FOUND"}`,
+			expectedTrace:      []string{"global"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.path, nil))
+
+			assertions.Equal(tt.expectedStatusCode, recorder.Code)
+			assertions.Equal(tt.expectedTrace, recorder.Header().Values("X-Trace"))
+			assertions.Equal(tt.expectedAllow, recorder.Header().Get("Allow"))
+			if tt.expectedBody != "" {
+				assertions.JSONEq(tt.expectedBody, recorder.Body.String())
+			}
+		})
+	}
+}
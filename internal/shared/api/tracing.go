@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go-products.com/m/internal/shared/tracing"
+)
+
+// Tracing starts a server span for every request continuing the trace sent by the caller in the traceparent header,
+// the span is named after the matched route once the request has been served
+func Tracing() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			ctx := otel.GetTextMapPropagator().Extract(request.Context(), propagation.HeaderCarrier(request.Header))
+			ctx, route := withMatchedRoute(ctx)
+			ctx, span := tracing.Start(ctx, request.Method,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(request.Method),
+					semconv.URLPath(request.URL.Path),
+					semconv.UserAgentOriginal(request.UserAgent()),
+				),
+			)
+			defer span.End()
+
+			recorder := NewStatusRecorder(response)
+			next.ServeHTTP(recorder, request.WithContext(ctx))
+
+			status := recorder.Status()
+			span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+			if route.path != "" {
+				span.SetName(request.Method + " " + route.path)
+				span.SetAttributes(semconv.HTTPRoute(route.path))
+			}
+
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+		})
+	}
+}
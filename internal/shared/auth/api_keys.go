@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+// APIKeyPrefix every issued key starts with it so leaked keys are easy to spot
+const APIKeyPrefix = "pk_"
+
+// APIKey only the hash of the key is stored, the key itself is shown once when it is issued
+type APIKey struct {
+	ID        string
+	Name      string
+	Role      Role
+	Hash      string
+	CreatedAt time.Time
+	RevokedAt *time.Time
+}
+
+func (k APIKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
+
+//go:generate moq -out api_key_repository_mock.go . APIKeyRepository
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key APIKey) error
+	// GetAPIKeyByHash fails with ErrAPIKeyNotFound when no key, revoked or not, has the hash
+	GetAPIKeyByHash(ctx context.Context, hash string) (*APIKey, error)
+	GetAPIKeys(ctx context.Context) ([]APIKey, error)
+	// RevokeAPIKey fails with ErrAPIKeyNotFound when there is no active key with the id
+	RevokeAPIKey(ctx context.Context, id string, revokedAt time.Time) error
+}
+
+// NewAPIKey generates a key with role, the returned token must be handed to the caller as it can't be recovered
+func NewAPIKey(name string, role Role) (APIKey, string, error) {
+	id := make([]byte, 8)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return APIKey{}, "", err
+	}
+
+	if _, err := rand.Read(secret); err != nil {
+		return APIKey{}, "", err
+	}
+
+	token := APIKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	return APIKey{
+		ID:        hex.EncodeToString(id),
+		Name:      name,
+		Role:      role,
+		Hash:      HashAPIKey(token),
+		CreatedAt: time.Now().UTC(),
+	}, token, nil
+}
+
+// HashAPIKey keys are random 256 bit values so a fast hash is enough, there is nothing to brute force
+func HashAPIKey(token string) string {
+	hash := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// APIKeyAuthenticator authenticates the keys stored in repository, revoked keys are rejected
+type APIKeyAuthenticator struct {
+	repository APIKeyRepository
+}
+
+func NewAPIKeyAuthenticator(repository APIKeyRepository) APIKeyAuthenticator {
+	return APIKeyAuthenticator{repository: repository}
+}
+
+func (a APIKeyAuthenticator) Authenticate(ctx context.Context, token string) (Principal, error) {
+	if !strings.HasPrefix(token, APIKeyPrefix) {
+		return Principal{}, ErrInvalidCredentials
+	}
+
+	key, err := a.repository.GetAPIKeyByHash(ctx, HashAPIKey(token))
+	if errors.Is(err, ErrAPIKeyNotFound) {
+		return Principal{}, ErrInvalidCredentials
+	}
+
+	if err != nil {
+		return Principal{}, err
+	}
+
+	if key.IsRevoked() {
+		return Principal{}, ErrInvalidCredentials
+	}
+
+	return Principal{Subject: key.ID, Name: key.Name, Role: key.Role}, nil
+}
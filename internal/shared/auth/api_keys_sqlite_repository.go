@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type APIKeysSQLiteRepository struct {
+	db *sql.DB
+}
+
+func NewAPIKeysSQLiteRepository(db *sql.DB) *APIKeysSQLiteRepository {
+	return &APIKeysSQLiteRepository{db: db}
+}
+
+func (r *APIKeysSQLiteRepository) CreateAPIKey(ctx context.Context, key APIKey) error {
+	_, err := r.db.ExecContext(ctx, "INSERT INTO api_keys (id, name, role, key_hash, created_at) VALUES (?, ?, ?, ?, ?);",
+		key.ID, key.Name, string(key.Role), key.Hash, key.CreatedAt.UnixMilli())
+
+	return err
+}
+
+func (r *APIKeysSQLiteRepository) GetAPIKeyByHash(ctx context.Context, hash string) (*APIKey, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT id, name, role, key_hash, created_at, revoked_at FROM api_keys WHERE key_hash = ?;", hash)
+
+	key, err := scanAPIKey(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAPIKeyNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func (r *APIKeysSQLiteRepository) GetAPIKeys(ctx context.Context) ([]APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT id, name, role, key_hash, created_at, revoked_at FROM api_keys ORDER BY created_at, id;")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (r *APIKeysSQLiteRepository) RevokeAPIKey(ctx context.Context, id string, revokedAt time.Time) error {
+	result, err := r.db.ExecContext(ctx, "UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL;", revokedAt.UnixMilli(), id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAPIKey(row scanner) (APIKey, error) {
+	var key APIKey
+	var role string
+	var createdAt int64
+	var revokedAt sql.NullInt64
+	if err := row.Scan(&key.ID, &key.Name, &role, &key.Hash, &createdAt, &revokedAt); err != nil {
+		return APIKey{}, err
+	}
+
+	key.Role = Role(role)
+	key.CreatedAt = time.UnixMilli(createdAt).UTC()
+	if revokedAt.Valid {
+		revoked := time.UnixMilli(revokedAt.Int64).UTC()
+		key.RevokedAt = &revoked
+	}
+
+	return key, nil
+}
@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+type Role string
+
+const (
+	// RoleViewer reads the admin views of the catalog
+	RoleViewer Role = "viewer"
+	// RoleEditor creates and updates products and categories
+	RoleEditor Role = "editor"
+	// RoleAdmin deletes and restores products, every other role is granted too
+	RoleAdmin Role = "admin"
+)
+
+// roles ordered from least to most privileged, a role is granted everything the previous ones are
+var roles = []Role{RoleViewer, RoleEditor, RoleAdmin}
+
+var (
+	// ErrInvalidCredentials the token is unknown, expired or revoked
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrAPIKeyNotFound     = errors.New("api key not found")
+)
+
+func ParseRole(role string) (Role, error) {
+	if !slices.Contains(roles, Role(role)) {
+		return "", fmt.Errorf("invalid role %q, must be %s, %s or %s", role, RoleViewer, RoleEditor, RoleAdmin)
+	}
+
+	return Role(role), nil
+}
+
+// Grants reports whether the role is allowed to reach routes requiring required
+func (r Role) Grants(required Role) bool {
+	index := slices.Index(roles, r)
+
+	return index >= 0 && index >= slices.Index(roles, required)
+}
+
+// Principal who is making the request
+type Principal struct {
+	// Subject identifies the caller, the api key id
+	Subject string
+	Name    string
+	Role    Role
+	// Attributes verified facts about the caller taken from its credentials, nil when there are none
+	Attributes map[string]string
+}
+
+// Authenticator resolves the bearer token of a request, ErrInvalidCredentials is returned for tokens that must be
+// rejected
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (Principal, error)
+}
+
+// AuthenticatorFunc allows using a function as Authenticator
+type AuthenticatorFunc func(ctx context.Context, token string) (Principal, error)
+
+func (f AuthenticatorFunc) Authenticate(ctx context.Context, token string) (Principal, error) {
+	return f(ctx, token)
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext the authenticated caller, false for anonymous requests
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+
+	return principal, ok
+}
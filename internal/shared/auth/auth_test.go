@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go-products.com/m/internal/product/infrastructure/persistance/migrations"
+	"go-products.com/m/internal/shared/database"
+)
+
+func TestRole_Grants(t *testing.T) {
+	assertions := require.New(t)
+
+	tests := []struct {
+		role     Role
+		required Role
+		granted  bool
+	}{
+		{role: RoleViewer, required: RoleViewer, granted: true},
+		{role: RoleViewer, required: RoleEditor, granted: false},
+		{role: RoleEditor, required: RoleViewer, granted: true},
+		{role: RoleEditor, required: RoleAdmin, granted: false},
+		{role: RoleAdmin, required: RoleEditor, granted: true},
+		{role: Role("owner"), required: RoleViewer, granted: false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.role)+" "+string(tt.required), func(t *testing.T) {
+			assertions.Equal(tt.granted, tt.role.Grants(tt.required))
+		})
+	}
+}
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	assertions := require.New(t)
+
+	key, token, err := NewAPIKey("ci", RoleEditor)
+	assertions.NoError(err)
+	assertions.True(strings.HasPrefix(token, APIKeyPrefix))
+	assertions.NotContains(key.Hash, token)
+
+	revokedAt := time.Now()
+	revoked := key
+	revoked.RevokedAt = &revokedAt
+
+	tests := []struct {
+		name       string
+		token      string
+		stored     *APIKey
+		storeErr   error
+		principal  Principal
+		wantErr    error
+		wantLookup bool
+	}{
+		{
+			name:       "Active key",
+			token:      token,
+			stored:     &key,
+			principal:  Principal{Subject: key.ID, Name: "ci", Role: RoleEditor},
+			wantLookup: true,
+		},
+		{name: "Revoked key", token: token, stored: &revoked, wantErr: ErrInvalidCredentials, wantLookup: true},
+		{name: "Unknown key", token: token, storeErr: ErrAPIKeyNotFound, wantErr: ErrInvalidCredentials, wantLookup: true},
+		{name: "Not an api key", token: "eyJhbGciOi", wantErr: ErrInvalidCredentials},
+		{name: "Repository error", token: token, storeErr: errors.New("database is locked"), wantErr: errors.New("database is locked"), wantLookup: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &APIKeyRepositoryMock{
+				GetAPIKeyByHashFunc: func(ctx context.Context, hash string) (*APIKey, error) {
+					assertions.Equal(HashAPIKey(token), hash)
+
+					return tt.stored, tt.storeErr
+				},
+			}
+
+			principal, err := NewAPIKeyAuthenticator(repository).Authenticate(context.Background(), tt.token)
+
+			assertions.Equal(tt.wantLookup, len(repository.GetAPIKeyByHashCalls()) == 1)
+			if tt.wantErr != nil {
+				assertions.EqualError(err, tt.wantErr.Error())
+
+				return
+			}
+
+			assertions.NoError(err)
+			assertions.Equal(tt.principal, principal)
+		})
+	}
+}
+
+func TestAPIKeysSQLiteRepository(t *testing.T) {
+	assertions := require.New(t)
+	ctx := context.Background()
+
+	db, err := database.GenerateDatabaseConnection(database.DatabaseConnection{
+		DatabaseName: filepath.Join(t.TempDir(), "products.db"),
+	}, migrations.CreateProductsDatabase)
+	assertions.NoError(err)
+	defer db.Close()
+
+	repository := NewAPIKeysSQLiteRepository(db)
+	key, token, err := NewAPIKey("ci", RoleAdmin)
+	assertions.NoError(err)
+	assertions.NoError(repository.CreateAPIKey(ctx, key))
+
+	stored, err := repository.GetAPIKeyByHash(ctx, HashAPIKey(token))
+	assertions.NoError(err)
+	assertions.Equal(key.ID, stored.ID)
+	assertions.Equal(RoleAdmin, stored.Role)
+	assertions.False(stored.IsRevoked())
+
+	assertions.NoError(repository.RevokeAPIKey(ctx, key.ID, time.Now()))
+	assertions.ErrorIs(repository.RevokeAPIKey(ctx, key.ID, time.Now()), ErrAPIKeyNotFound)
+
+	stored, err = repository.GetAPIKeyByHash(ctx, HashAPIKey(token))
+	assertions.NoError(err)
+	assertions.True(stored.IsRevoked())
+
+	_, err = repository.GetAPIKeyByHash(ctx, HashAPIKey("pk_unknown"))
+	assertions.ErrorIs(err, ErrAPIKeyNotFound)
+
+	keys, err := repository.GetAPIKeys(ctx)
+	assertions.NoError(err)
+	assertions.Len(keys, 1)
+}
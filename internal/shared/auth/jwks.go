@@ -0,0 +1,301 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"go-products.com/m/internal/shared/cache"
+	"go-products.com/m/internal/shared/logging"
+)
+
+// ErrKeySetUnavailable the signing keys could not be loaded, tokens can't be verified until they are
+var ErrKeySetUnavailable = errors.New("jwks unavailable")
+
+// JWKSSource returns the content of a JSON Web Key Set
+type JWKSSource func(ctx context.Context) ([]byte, error)
+
+func JWKSFromFile(path string) JWKSSource {
+	return func(ctx context.Context) ([]byte, error) {
+		return os.ReadFile(path)
+	}
+}
+
+// JWKSFromURL fetches the key set from an endpoint, meant for a gateway reachable inside the network
+func JWKSFromURL(url string, client *http.Client) JWKSSource {
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+
+	return func(ctx context.Context) ([]byte, error) {
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		response, err := client.Do(request)
+		if err != nil {
+			return nil, err
+		}
+		defer response.Body.Close()
+
+		if response.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetching %s: status %d", url, response.StatusCode)
+		}
+
+		return io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	}
+}
+
+type JWKSOptions struct {
+	// RefreshInterval how long loaded keys are used before reloading them
+	RefreshInterval time.Duration
+	// MinRefreshInterval tokens signed with an unknown key trigger a reload, at most once per interval
+	MinRefreshInterval time.Duration
+}
+
+var DefaultJWKSOptions = JWKSOptions{
+	RefreshInterval:    15 * time.Minute,
+	MinRefreshInterval: 30 * time.Second,
+}
+
+// JWKS public keys by key id, reloaded periodically and when a token names an unknown key so keys rotated by the
+// issuer are picked up. Keys already loaded keep being used when a reload fails. Reloads happen outside the lock, so
+// requests with known keys never wait on the source, and concurrent reloads share a single fetch
+type JWKS struct {
+	source  JWKSSource
+	options JWKSOptions
+	now     func() time.Time
+	loads   cache.Group[struct{}, map[string]crypto.PublicKey]
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+	// attemptedAt last time a reload started, failed ones included, reloads are throttled on it
+	attemptedAt time.Time
+	// err of the last reload, returned while no keys were ever loaded
+	err error
+}
+
+func NewJWKS(source JWKSSource, options JWKSOptions) *JWKS {
+	return &JWKS{source: source, options: options, now: time.Now}
+}
+
+// Refresh loads the keys, calling it at startup reports a wrong source before any token is received
+func (j *JWKS) Refresh(ctx context.Context) error {
+	_, err := j.reload(ctx)
+
+	return err
+}
+
+// Key the public key with kid, ErrInvalidCredentials is returned when the key set doesn't have it and
+// ErrKeySetUnavailable when no key set was ever loaded
+func (j *JWKS) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	j.mu.Lock()
+	keys, fetchedAt := j.keys, j.fetchedAt
+	j.mu.Unlock()
+
+	if (keys == nil || j.now().Sub(fetchedAt) >= j.options.RefreshInterval) && j.canReload() {
+		reloaded, err := j.reload(ctx)
+		switch {
+		case err == nil:
+			keys = reloaded
+		case keys != nil:
+			logging.FromContext(ctx).Warn("reloading jwks, keeping the previous keys", slog.Any("error", err))
+		}
+	}
+
+	if keys == nil {
+		j.mu.Lock()
+		defer j.mu.Unlock()
+
+		return nil, j.err
+	}
+
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+
+	// the issuer may have rotated its keys, an unknown key stays invalid credentials when the reload fails
+	if j.canReload() {
+		reloaded, err := j.reload(ctx)
+		if err != nil {
+			logging.FromContext(ctx).Warn("reloading jwks for an unknown key", slog.String("kid", kid), slog.Any("error", err))
+		}
+
+		if key, ok := reloaded[kid]; ok {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidCredentials, kid)
+}
+
+// canReload whether MinRefreshInterval passed since the last reload started
+func (j *JWKS) canReload() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.attemptedAt.IsZero() || j.now().Sub(j.attemptedAt) >= j.options.MinRefreshInterval
+}
+
+// reload fetches the key set once for every caller waiting on it. The fetch is not cancelled with the request that
+// started it, as the others share it, sources bound the time it takes
+func (j *JWKS) reload(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	keys, err, _ := j.loads.Do(struct{}{}, func() (map[string]crypto.PublicKey, error) {
+		j.mu.Lock()
+		j.attemptedAt = j.now()
+		j.mu.Unlock()
+
+		keys, err := j.fetch(context.WithoutCancel(ctx))
+
+		j.mu.Lock()
+		defer j.mu.Unlock()
+
+		j.err = err
+		if err != nil {
+			return nil, err
+		}
+
+		j.keys, j.fetchedAt = keys, j.now()
+
+		return keys, nil
+	})
+
+	return keys, err
+}
+
+func (j *JWKS) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	content, err := j.source(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrKeySetUnavailable, err)
+	}
+
+	keys, err := ParseJWKS(content)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrKeySetUnavailable, err)
+	}
+
+	return keys, nil
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// ParseJWKS reads the RSA and EC signing keys of a key set, keys meant for encryption or of other types are skipped
+func ParseJWKS(content []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(content, &set); err != nil {
+		return nil, fmt.Errorf("parsing jwks: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		var publicKey crypto.PublicKey
+		var err error
+		switch key.Kty {
+		case "RSA":
+			publicKey, err = key.rsaPublicKey()
+		case "EC":
+			publicKey, err = key.ecdsaPublicKey()
+		default:
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("parsing key %q: %w", key.Kid, err)
+		}
+
+		keys[key.Kid] = publicKey
+	}
+
+	return keys, nil
+}
+
+func (k jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := decodeBigInt(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("modulus: %w", err)
+	}
+
+	e, err := decodeBigInt(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("exponent: %w", err)
+	}
+
+	if !e.IsInt64() || e.Int64() < 3 {
+		return nil, errors.New("invalid exponent")
+	}
+
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func (k jsonWebKey) ecdsaPublicKey() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch k.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+	}
+
+	x, err := decodeBigInt(k.X)
+	if err != nil {
+		return nil, fmt.Errorf("x: %w", err)
+	}
+
+	y, err := decodeBigInt(k.Y)
+	if err != nil {
+		return nil, fmt.Errorf("y: %w", err)
+	}
+
+	if !curve.IsOnCurve(x, y) {
+		return nil, errors.New("point is not on the curve")
+	}
+
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	content, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(content) == 0 {
+		return nil, errors.New("empty value")
+	}
+
+	return new(big.Int).SetBytes(content), nil
+}
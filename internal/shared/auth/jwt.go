@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type JWTOptions struct {
+	Issuer   string
+	Audience string
+	// RolesClaim claim listing the roles of the caller, either a string or an array of strings
+	RolesClaim string
+	// Roles maps the values of RolesClaim to roles, the most privileged mapped role is granted and values without
+	// mapping are ignored
+	Roles map[string]Role
+	// Attributes maps claims to the attributes of the principal they are copied to, claims missing or not holding a
+	// string are left out
+	Attributes map[string]string
+	// Leeway tolerated clock skew with the issuer
+	Leeway time.Duration
+}
+
+// jwtAlgorithms only asymmetric algorithms are accepted, a token can't pick its own verification method
+var jwtAlgorithms = []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()}
+
+// JWTAuthenticator verifies tokens signed by the keys of a JWKS, checking issuer, audience and expiration
+type JWTAuthenticator struct {
+	keys    *JWKS
+	options JWTOptions
+	parser  *jwt.Parser
+}
+
+func NewJWTAuthenticator(keys *JWKS, options JWTOptions) JWTAuthenticator {
+	return JWTAuthenticator{
+		keys:    keys,
+		options: options,
+		parser: jwt.NewParser(
+			jwt.WithValidMethods(jwtAlgorithms),
+			jwt.WithIssuer(options.Issuer),
+			jwt.WithAudience(options.Audience),
+			jwt.WithExpirationRequired(),
+			jwt.WithLeeway(options.Leeway),
+		),
+	}
+}
+
+func (a JWTAuthenticator) Authenticate(ctx context.Context, token string) (Principal, error) {
+	claims := jwt.MapClaims{}
+	_, err := a.parser.ParseWithClaims(token, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		return a.keys.Key(ctx, kid)
+	})
+	if errors.Is(err, ErrKeySetUnavailable) {
+		return Principal{}, err
+	}
+
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return Principal{}, fmt.Errorf("%w: missing sub claim", ErrInvalidCredentials)
+	}
+
+	name, _ := claims["name"].(string)
+
+	return Principal{
+		Subject:    subject,
+		Name:       name,
+		Role:       a.role(claims[a.options.RolesClaim]),
+		Attributes: a.attributes(claims),
+	}, nil
+}
+
+func (a JWTAuthenticator) attributes(claims jwt.MapClaims) map[string]string {
+	var attributes map[string]string
+	for claim, attribute := range a.options.Attributes {
+		if value, ok := claims[claim].(string); ok && value != "" {
+			if attributes == nil {
+				attributes = make(map[string]string)
+			}
+
+			attributes[attribute] = value
+		}
+	}
+
+	return attributes
+}
+
+// role the most privileged role mapped from the claim, empty when there is none so every role check fails
+func (a JWTAuthenticator) role(claim any) Role {
+	var values []string
+	switch claim := claim.(type) {
+	case string:
+		values = []string{claim}
+	case []any:
+		for _, value := range claim {
+			if value, ok := value.(string); ok {
+				values = append(values, value)
+			}
+		}
+	}
+
+	granted := Role("")
+	for _, value := range values {
+		role, ok := a.options.Roles[value]
+		if ok && slices.Index(roles, role) > slices.Index(roles, granted) {
+			granted = role
+		}
+	}
+
+	return granted
+}
+
+// FirstOf tries authenticators in order until one accepts the token, ErrInvalidCredentials is returned when they all
+// reject it and any other error stops the search
+func FirstOf(authenticators ...Authenticator) Authenticator {
+	return AuthenticatorFunc(func(ctx context.Context, token string) (Principal, error) {
+		for _, authenticator := range authenticators {
+			principal, err := authenticator.Authenticate(ctx, token)
+			if errors.Is(err, ErrInvalidCredentials) {
+				continue
+			}
+
+			return principal, err
+		}
+
+		return Principal{}, ErrInvalidCredentials
+	})
+}
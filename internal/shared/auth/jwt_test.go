@@ -0,0 +1,347 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIssuer   = "https://gateway.internal"
+	testAudience = "products"
+)
+
+var testJWTOptions = JWTOptions{
+	Issuer:     testIssuer,
+	Audience:   testAudience,
+	RolesClaim: "roles",
+	Roles:      map[string]Role{"catalog-reader": RoleViewer, "catalog-writer": RoleEditor},
+	Attributes: map[string]string{"https://gateway.internal/channel": "channel", "segment": "segment"},
+}
+
+type signingKey struct {
+	kid    string
+	method jwt.SigningMethod
+	key    crypto.Signer
+}
+
+func newRSAKey(t *testing.T, kid string) signingKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	return signingKey{kid: kid, method: jwt.SigningMethodRS256, key: key}
+}
+
+func newECKey(t *testing.T, kid string) signingKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	return signingKey{kid: kid, method: jwt.SigningMethodES256, key: key}
+}
+
+func (k signingKey) sign(t *testing.T, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(k.method, claims)
+	token.Header["kid"] = k.kid
+
+	signed, err := token.SignedString(k.key)
+	require.NoError(t, err)
+
+	return signed
+}
+
+func encodeJWKS(t *testing.T, keys ...signingKey) []byte {
+	encode := func(value *big.Int) string {
+		return base64.RawURLEncoding.EncodeToString(value.Bytes())
+	}
+
+	set := make([]jsonWebKey, 0, len(keys))
+	for _, key := range keys {
+		switch publicKey := key.key.Public().(type) {
+		case *rsa.PublicKey:
+			set = append(set, jsonWebKey{Kty: "RSA", Kid: key.kid, Use: "sig", N: encode(publicKey.N), E: encode(big.NewInt(int64(publicKey.E)))})
+		case *ecdsa.PublicKey:
+			set = append(set, jsonWebKey{Kty: "EC", Kid: key.kid, Crv: "P-256", X: encode(publicKey.X), Y: encode(publicKey.Y)})
+		}
+	}
+
+	content, err := json.Marshal(map[string]any{"keys": set})
+	require.NoError(t, err)
+
+	return content
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":   testIssuer,
+		"aud":   testAudience,
+		"sub":   "user-42",
+		"name":  "Ada",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"catalog-reader", "catalog-writer", "unrelated"},
+	}
+}
+
+func withClaims(values map[string]any) jwt.MapClaims {
+	claims := validClaims()
+	for name, value := range values {
+		claims[name] = value
+	}
+
+	return claims
+}
+
+func withClaim(name string, value any) jwt.MapClaims {
+	claims := validClaims()
+	if value == nil {
+		delete(claims, name)
+	} else {
+		claims[name] = value
+	}
+
+	return claims
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	assertions := require.New(t)
+
+	rsaKey := newRSAKey(t, "rsa-1")
+	ecKey := newECKey(t, "ec-1")
+	unknownKey := newRSAKey(t, "rsa-unknown")
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	assertions.NoError(os.WriteFile(path, encodeJWKS(t, rsaKey, ecKey), 0o600))
+	authenticator := NewJWTAuthenticator(NewJWKS(JWKSFromFile(path), DefaultJWKSOptions), testJWTOptions)
+
+	hmacToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims()).SignedString([]byte("secret"))
+	assertions.NoError(err)
+
+	tests := []struct {
+		name      string
+		token     string
+		principal Principal
+		wantErr   string
+	}{
+		{
+			name:      "RS256 token",
+			token:     rsaKey.sign(t, validClaims()),
+			principal: Principal{Subject: "user-42", Name: "Ada", Role: RoleEditor},
+		},
+		{
+			name:      "ES256 token with a single role",
+			token:     ecKey.sign(t, withClaim("roles", "catalog-reader")),
+			principal: Principal{Subject: "user-42", Name: "Ada", Role: RoleViewer},
+		},
+		{
+			name:      "Token with attribute claims",
+			token:     rsaKey.sign(t, withClaims(map[string]any{"segment": "wholesale", "https://gateway.internal/channel": "app"})),
+			principal: Principal{Subject: "user-42", Name: "Ada", Role: RoleEditor, Attributes: map[string]string{"segment": "wholesale", "channel": "app"}},
+		},
+		{
+			name:      "Token without mapped roles",
+			token:     rsaKey.sign(t, withClaim("roles", []string{"unrelated"})),
+			principal: Principal{Subject: "user-42", Name: "Ada"},
+		},
+		{name: "Expired token", token: rsaKey.sign(t, withClaim("exp", time.Now().Add(-time.Hour).Unix())), wantErr: "token is expired"},
+		{name: "Token without expiration", token: rsaKey.sign(t, withClaim("exp", nil)), wantErr: "exp claim is required"},
+		{name: "Wrong audience", token: rsaKey.sign(t, withClaim("aud", "billing")), wantErr: "token has invalid audience"},
+		{name: "Wrong issuer", token: rsaKey.sign(t, withClaim("iss", "https://evil.example")), wantErr: "token has invalid issuer"},
+		{name: "Missing subject", token: rsaKey.sign(t, withClaim("sub", nil)), wantErr: "missing sub claim"},
+		{name: "Unknown key", token: unknownKey.sign(t, validClaims()), wantErr: `unknown key "rsa-unknown"`},
+		{name: "Symmetric algorithm", token: hmacToken, wantErr: "signing method HS256 is invalid"},
+		{name: "Malformed token", token: "not.a.jwt", wantErr: "token is malformed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := authenticator.Authenticate(context.Background(), tt.token)
+			if tt.wantErr != "" {
+				assertions.ErrorIs(err, ErrInvalidCredentials)
+				assertions.ErrorContains(err, tt.wantErr)
+
+				return
+			}
+
+			assertions.NoError(err)
+			assertions.Equal(tt.principal, principal)
+		})
+	}
+}
+
+func TestJWKS_Rotation(t *testing.T) {
+	assertions := require.New(t)
+
+	oldKey := newRSAKey(t, "2024")
+	newKey := newECKey(t, "2025")
+
+	jwks := encodeJWKS(t, oldKey)
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		fetches++
+		_, _ = response.Write(jwks)
+	}))
+	defer server.Close()
+
+	keys := NewJWKS(JWKSFromURL(server.URL, server.Client()), JWKSOptions{RefreshInterval: time.Hour, MinRefreshInterval: time.Minute})
+	now := time.Now()
+	keys.now = func() time.Time { return now }
+	authenticator := NewJWTAuthenticator(keys, testJWTOptions)
+
+	assertions.NoError(keys.Refresh(context.Background()))
+	_, err := authenticator.Authenticate(context.Background(), oldKey.sign(t, validClaims()))
+	assertions.NoError(err)
+	assertions.Equal(1, fetches)
+
+	// the issuer rotates its key, unknown keys reload the set at most once per MinRefreshInterval
+	jwks = encodeJWKS(t, newKey)
+	_, err = authenticator.Authenticate(context.Background(), newKey.sign(t, validClaims()))
+	assertions.ErrorIs(err, ErrInvalidCredentials)
+	assertions.Equal(1, fetches)
+
+	now = now.Add(time.Minute)
+	_, err = authenticator.Authenticate(context.Background(), newKey.sign(t, validClaims()))
+	assertions.NoError(err)
+	assertions.Equal(2, fetches)
+
+	_, err = authenticator.Authenticate(context.Background(), oldKey.sign(t, validClaims()))
+	assertions.ErrorIs(err, ErrInvalidCredentials)
+
+	// keys in use are kept when the endpoint fails
+	server.Config.Handler = http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		response.WriteHeader(http.StatusServiceUnavailable)
+	})
+	now = now.Add(2 * time.Hour)
+	_, err = authenticator.Authenticate(context.Background(), newKey.sign(t, validClaims()))
+	assertions.NoError(err)
+}
+
+func TestJWKS_Unavailable(t *testing.T) {
+	assertions := require.New(t)
+
+	keys := NewJWKS(JWKSFromFile(filepath.Join(t.TempDir(), "missing.json")), DefaultJWKSOptions)
+	assertions.ErrorIs(keys.Refresh(context.Background()), ErrKeySetUnavailable)
+
+	_, err := NewJWTAuthenticator(keys, testJWTOptions).Authenticate(context.Background(), newRSAKey(t, "1").sign(t, validClaims()))
+	assertions.ErrorIs(err, ErrKeySetUnavailable)
+	assertions.NotErrorIs(err, ErrInvalidCredentials)
+}
+
+func TestJWKS_FailedReloads(t *testing.T) {
+	assertions := require.New(t)
+
+	key := newRSAKey(t, "2024")
+	rotated := newRSAKey(t, "2025")
+
+	var mu sync.Mutex
+	fetches, failing := 0, false
+	keys := NewJWKS(func(ctx context.Context) ([]byte, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		fetches++
+		if failing {
+			return nil, errors.New("connection refused")
+		}
+
+		return encodeJWKS(t, key), nil
+	}, JWKSOptions{RefreshInterval: time.Hour, MinRefreshInterval: time.Minute})
+	now := time.Now()
+	keys.now = func() time.Time { return now }
+	authenticator := NewJWTAuthenticator(keys, testJWTOptions)
+
+	assertions.NoError(keys.Refresh(context.Background()))
+
+	// an unknown key is not a server error when the reload fails, and failed reloads are throttled too
+	failing = true
+	now = now.Add(time.Minute)
+	for i := 0; i < 2; i++ {
+		_, err := authenticator.Authenticate(context.Background(), rotated.sign(t, validClaims()))
+		assertions.ErrorIs(err, ErrInvalidCredentials)
+		assertions.NotErrorIs(err, ErrKeySetUnavailable)
+	}
+	assertions.Equal(2, fetches)
+
+	_, err := authenticator.Authenticate(context.Background(), key.sign(t, validClaims()))
+	assertions.NoError(err)
+}
+
+func TestJWKS_ConcurrentReloads(t *testing.T) {
+	assertions := require.New(t)
+
+	key := newRSAKey(t, "2024")
+	rotated := newRSAKey(t, "2025")
+
+	fetches := atomic.Int32{}
+	release := make(chan struct{})
+	keys := NewJWKS(func(ctx context.Context) ([]byte, error) {
+		if fetches.Add(1) > 1 {
+			<-release
+		}
+
+		return encodeJWKS(t, key, rotated), nil
+	}, JWKSOptions{RefreshInterval: time.Hour, MinRefreshInterval: time.Minute})
+	now := time.Now()
+	keys.now = func() time.Time { return now }
+	authenticator := NewJWTAuthenticator(keys, testJWTOptions)
+	assertions.NoError(keys.Refresh(context.Background()))
+	now = now.Add(time.Minute)
+
+	// tokens with an unknown key start a single reload, known keys are still verified while it runs
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := keys.Key(context.Background(), "unknown")
+			errs <- err
+		}()
+	}
+
+	require.Eventually(t, func() bool { return fetches.Load() == 2 }, time.Second, time.Millisecond)
+
+	_, err := authenticator.Authenticate(context.Background(), key.sign(t, validClaims()))
+	assertions.NoError(err)
+
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assertions.ErrorIs(err, ErrInvalidCredentials)
+	}
+	assertions.Equal(int32(2), fetches.Load())
+}
+
+func TestFirstOf(t *testing.T) {
+	assertions := require.New(t)
+
+	reject := AuthenticatorFunc(func(ctx context.Context, token string) (Principal, error) {
+		return Principal{}, ErrInvalidCredentials
+	})
+	accept := AuthenticatorFunc(func(ctx context.Context, token string) (Principal, error) {
+		return Principal{Subject: token, Role: RoleViewer}, nil
+	})
+
+	principal, err := FirstOf(reject, accept).Authenticate(context.Background(), "token")
+	assertions.NoError(err)
+	assertions.Equal("token", principal.Subject)
+
+	_, err = FirstOf(reject, reject).Authenticate(context.Background(), "token")
+	assertions.ErrorIs(err, ErrInvalidCredentials)
+}
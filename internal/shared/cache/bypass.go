@@ -0,0 +1,17 @@
+package cache
+
+import "context"
+
+type bypassKey struct{}
+
+// Bypass reads made with the returned context skip caches, for reads that must see the current state such as the ones
+// conditional writes are checked against
+func Bypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey{}, true)
+}
+
+func Bypassed(ctx context.Context) bool {
+	bypassed, _ := ctx.Value(bypassKey{}).(bool)
+
+	return bypassed
+}
@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRU(t *testing.T) {
+	assertions := require.New(t)
+
+	t.Run("Least recently used entry is evicted when capacity is exceeded", func(t *testing.T) {
+		lru := NewLRU[string, int](2, 0)
+		lru.Set("a", 1)
+		lru.Set("b", 2)
+
+		_, ok := lru.Get("a")
+		assertions.True(ok)
+
+		lru.Set("c", 3)
+
+		_, ok = lru.Get("b")
+		assertions.False(ok)
+		assertions.Equal(2, lru.Len())
+	})
+
+	t.Run("Expired entries are not returned", func(t *testing.T) {
+		now := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+		lru := NewLRU[string, int](2, time.Minute)
+		lru.now = func() time.Time { return now }
+		lru.Set("a", 1)
+
+		now = now.Add(2 * time.Minute)
+
+		_, ok := lru.Get("a")
+		assertions.False(ok)
+		assertions.Equal(0, lru.Len())
+	})
+}
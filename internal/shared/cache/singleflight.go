@@ -0,0 +1,46 @@
+package cache
+
+import "sync"
+
+// Group deduplicates concurrent loads of the same key, callers arriving while a load is in flight wait for it and
+// share its result instead of hitting the source again
+type Group[K comparable, V any] struct {
+	mu    sync.Mutex
+	calls map[K]*call[V]
+}
+
+type call[V any] struct {
+	wg    sync.WaitGroup
+	value V
+	err   error
+}
+
+func (g *Group[K, V]) Do(key K, load func() (V, error)) (V, error, bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[K]*call[V])
+	}
+
+	if inFlight, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		inFlight.wg.Wait()
+
+		return inFlight.value, inFlight.err, true
+	}
+
+	current := &call[V]{}
+	current.wg.Add(1)
+	g.calls[key] = current
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		current.wg.Done()
+	}()
+
+	current.value, current.err = load()
+
+	return current.value, current.err, false
+}
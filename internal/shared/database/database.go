@@ -8,6 +8,8 @@ import (
 
 type DatabaseConnection struct {
 	DatabaseName string
+	// MaxOpenConnections limits the connection pool, unlimited when 0
+	MaxOpenConnections int
 }
 
 func GenerateDatabaseConnection(params DatabaseConnection, initFunction func(db *sql.DB) error) (*sql.DB, error) {
@@ -16,6 +18,8 @@ func GenerateDatabaseConnection(params DatabaseConnection, initFunction func(db
 		return nil, err
 	}
 
+	db.SetMaxOpenConns(params.MaxOpenConnections)
+
 	if initFunction != nil {
 		if err := initFunction(db); err != nil {
 			return nil, err
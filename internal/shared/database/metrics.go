@@ -0,0 +1,29 @@
+package database
+
+import (
+	"database/sql"
+
+	"go-products.com/m/internal/shared/metrics"
+)
+
+// RegisterPoolMetrics exposes the connection pool statistics of db, they are read on every scrape
+func RegisterPoolMetrics(registry *metrics.Registry, db *sql.DB) {
+	registry.NewGaugeFunc("db_open_connections", "Established connections both in use and idle.", func() float64 {
+		return float64(db.Stats().OpenConnections)
+	})
+	registry.NewGaugeFunc("db_in_use_connections", "Connections currently in use.", func() float64 {
+		return float64(db.Stats().InUse)
+	})
+	registry.NewGaugeFunc("db_idle_connections", "Idle connections.", func() float64 {
+		return float64(db.Stats().Idle)
+	})
+	registry.NewGaugeFunc("db_max_open_connections", "Maximum number of open connections.", func() float64 {
+		return float64(db.Stats().MaxOpenConnections)
+	})
+	registry.NewCounterFunc("db_wait_count_total", "Connections waited for.", func() float64 {
+		return float64(db.Stats().WaitCount)
+	})
+	registry.NewCounterFunc("db_wait_duration_seconds_total", "Time blocked waiting for a new connection.", func() float64 {
+		return db.Stats().WaitDuration.Seconds()
+	})
+}
@@ -0,0 +1,211 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"go-products.com/m/internal/shared/logging"
+)
+
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+
+	// ReasonTimeout the check did not complete in time
+	ReasonTimeout = "timeout"
+	// ReasonPending the work the check waits for is not completed yet
+	ReasonPending = "pending"
+	// ReasonFailed any other failure, see the logs for the error
+	ReasonFailed = "failed"
+
+	// checkTimeout bounds every check so a hanging dependency can't hang the probe
+	checkTimeout = 2 * time.Second
+)
+
+// Check reports why a dependency is not ready, nil when it is
+type Check func(ctx context.Context) error
+
+// CheckResult the error of a failed check is only logged, probes are often reachable from outside the network, a
+// reason code is reported instead
+type CheckResult struct {
+	Status   string `json:"status"`
+	Reason   string `json:"reason,omitempty"`
+	Duration string `json:"duration"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// Checks named readiness checks, the service is ready when all of them pass
+type Checks struct {
+	mu     sync.RWMutex
+	checks map[string]Check
+}
+
+func NewChecks() *Checks {
+	return &Checks{checks: make(map[string]Check)}
+}
+
+func (c *Checks) Add(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checks[name] = check
+}
+
+// Run runs every check concurrently
+func (c *Checks) Run(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := make(map[string]Check, len(c.checks))
+	for name, check := range c.checks {
+		checks[name] = check
+	}
+	c.mu.RUnlock()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			result := run(ctx, name, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Checks[name] = result
+			if result.Status != StatusOK {
+				report.Status = StatusUnavailable
+			}
+		}()
+	}
+	wg.Wait()
+
+	return report
+}
+
+func run(ctx context.Context, name string, check Check) CheckResult {
+	checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(checkCtx)
+	result := CheckResult{Status: StatusOK, Duration: time.Since(start).String()}
+	if err != nil {
+		logging.FromContext(ctx).Warn("readiness check failed", slog.String("check", name), slog.Any("error", err))
+		result.Status = StatusUnavailable
+		result.Reason = reason(err)
+	}
+
+	return result
+}
+
+type reasonError struct {
+	reason string
+	err    error
+}
+
+func (e reasonError) Error() string {
+	return e.err.Error()
+}
+
+func (e reasonError) Unwrap() error {
+	return e.err
+}
+
+// WithReason reports err with reason instead of the one derived from it, nil when err is nil
+func WithReason(reason string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return reasonError{reason: reason, err: err}
+}
+
+func reason(err error) string {
+	var withReason reasonError
+	switch {
+	case errors.As(err, &withReason):
+		return withReason.reason
+	case errors.Is(err, ErrPending):
+		return ReasonPending
+	case errors.Is(err, context.DeadlineExceeded):
+		return ReasonTimeout
+	default:
+		return ReasonFailed
+	}
+}
+
+// Ping checks the database accepts connections
+func Ping(db *sql.DB) Check {
+	return db.PingContext
+}
+
+var ErrPending = errors.New("not completed yet")
+
+// Gate readiness check for one-off startup work such as seeding, it fails until Open is called
+type Gate struct {
+	once sync.Once
+	done chan struct{}
+}
+
+func NewGate() *Gate {
+	return &Gate{done: make(chan struct{})}
+}
+
+func (g *Gate) Open() {
+	g.once.Do(func() { close(g.done) })
+}
+
+// Done is closed once the gate is open
+func (g *Gate) Done() <-chan struct{} {
+	return g.done
+}
+
+func (g *Gate) Check(ctx context.Context) error {
+	select {
+	case <-g.done:
+		return nil
+	default:
+		return ErrPending
+	}
+}
+
+// LivenessHandler answers as long as the process is able to serve requests
+func LivenessHandler() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		write(writer, http.StatusOK, Report{Status: StatusOK})
+	}
+}
+
+// ReadinessHandler answers 503 while any of checks fails so no traffic is routed to the instance
+func ReadinessHandler(checks *Checks) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		report := checks.Run(request.Context())
+
+		status := http.StatusOK
+		if report.Status != StatusOK {
+			status = http.StatusServiceUnavailable
+		}
+
+		write(writer, status, report)
+	}
+}
+
+func write(writer http.ResponseWriter, status int, report Report) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.Header().Set("Cache-Control", "no-store")
+	writer.WriteHeader(status)
+	_ = json.NewEncoder(writer).Encode(report)
+}
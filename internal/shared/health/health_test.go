@@ -0,0 +1,119 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go-products.com/m/internal/shared/logging"
+)
+
+func TestReadinessHandler(t *testing.T) {
+	assertions := require.New(t)
+
+	seed := NewGate()
+	checks := NewChecks()
+	checks.Add("database", func(ctx context.Context) error { return nil })
+	checks.Add("seed", seed.Check)
+
+	recorder := httptest.NewRecorder()
+	ReadinessHandler(checks)(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	assertions.Equal(http.StatusServiceUnavailable, recorder.Code)
+	assertions.JSONEq(`{
+		"status": "unavailable",
+		"checks": {
+			"database": {"status": "ok", "duration": ""},
+			"seed": {"status": "unavailable", "reason": "pending", "duration": ""}
+		}
+	}`, withoutDurations(t, recorder.Body.Bytes()))
+
+	seed.Open()
+	seed.Open()
+
+	recorder = httptest.NewRecorder()
+	ReadinessHandler(checks)(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	assertions.Equal(http.StatusOK, recorder.Code)
+	assertions.JSONEq(`{
+		"status": "ok",
+		"checks": {
+			"database": {"status": "ok", "duration": ""},
+			"seed": {"status": "ok", "duration": ""}
+		}
+	}`, withoutDurations(t, recorder.Body.Bytes()))
+}
+
+func TestReadinessHandler_FailingCheck(t *testing.T) {
+	assertions := require.New(t)
+
+	var logs bytes.Buffer
+	checks := NewChecks()
+	checks.Add("database", func(ctx context.Context) error {
+		return errors.New("open /var/lib/products/products.db: permission denied")
+	})
+	checks.Add("search", func(ctx context.Context) error {
+		<-ctx.Done()
+
+		return fmt.Errorf("querying search index: %w", ctx.Err())
+	})
+	checks.Add("migrations", func(ctx context.Context) error {
+		return WithReason("pending_migrations", errors.New("2 pending migrations"))
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	// the probe deadline comes before the one of every check
+	ctx, cancel := context.WithTimeout(request.Context(), 50*time.Millisecond)
+	defer cancel()
+	request = request.WithContext(logging.WithLogger(ctx, slog.New(slog.NewTextHandler(&logs, nil))))
+	recorder := httptest.NewRecorder()
+	ReadinessHandler(checks)(recorder, request)
+
+	assertions.Equal(http.StatusServiceUnavailable, recorder.Code)
+	assertions.Equal("no-store", recorder.Header().Get("Cache-Control"))
+	assertions.JSONEq(`{
+		"status": "unavailable",
+		"checks": {
+			"database": {"status": "unavailable", "reason": "failed", "duration": ""},
+			"search": {"status": "unavailable", "reason": "timeout", "duration": ""},
+			"migrations": {"status": "unavailable", "reason": "pending_migrations", "duration": ""}
+		}
+	}`, withoutDurations(t, recorder.Body.Bytes()))
+	assertions.NotContains(recorder.Body.String(), "permission denied")
+	assertions.Contains(logs.String(), `msg="readiness check failed" check=database error="open /var/lib/products/products.db: permission denied"`)
+}
+
+func TestLivenessHandler(t *testing.T) {
+	assertions := require.New(t)
+
+	recorder := httptest.NewRecorder()
+	LivenessHandler()(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assertions.Equal(http.StatusOK, recorder.Code)
+	assertions.JSONEq(`{"status": "ok"}`, recorder.Body.String())
+}
+
+func withoutDurations(t *testing.T, body []byte) string {
+	var report Report
+	require.NoError(t, json.Unmarshal(body, &report))
+
+	for name, result := range report.Checks {
+		require.NotEmpty(t, result.Duration)
+		result.Duration = ""
+		report.Checks[name] = result
+	}
+
+	content, err := json.Marshal(report)
+	require.NoError(t, err)
+
+	return string(content)
+}
@@ -0,0 +1,53 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+type Options struct {
+	// Format json or text
+	Format string
+	// Level debug, info, warn or error
+	Level string
+}
+
+type loggerKey struct{}
+
+func New(writer io.Writer, options Options) (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(options.Level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %w", options.Level, err)
+	}
+
+	handlerOptions := &slog.HandlerOptions{Level: level}
+	switch strings.ToLower(options.Format) {
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(writer, handlerOptions)), nil
+	case FormatText:
+		return slog.New(slog.NewTextHandler(writer, handlerOptions)), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q, must be %s or %s", options.Format, FormatJSON, FormatText)
+	}
+}
+
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the request scoped logger, or the default one when the context carries none
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+
+	return slog.Default()
+}
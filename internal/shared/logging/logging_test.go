@@ -0,0 +1,51 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	assertions := require.New(t)
+
+	tests := []struct {
+		name    string
+		options Options
+		wantErr bool
+	}{
+		{name: "JSON logger", options: Options{Format: FormatJSON, Level: "debug"}},
+		{name: "Text logger", options: Options{Format: FormatText, Level: "warn"}},
+		{name: "Unknown format returns error", options: Options{Format: "xml", Level: "info"}, wantErr: true},
+		{name: "Unknown level returns error", options: Options{Format: FormatJSON, Level: "verbose"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, err := New(&bytes.Buffer{}, tt.options)
+			assertions.Equal(err != nil, tt.wantErr)
+			assertions.Equal(logger == nil, tt.wantErr)
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	assertions := require.New(t)
+
+	assertions.Equal(slog.Default(), FromContext(context.Background()))
+
+	output := &bytes.Buffer{}
+	logger, err := New(output, Options{Format: FormatJSON, Level: "info"})
+	assertions.NoError(err)
+
+	ctx := WithLogger(context.Background(), logger.With(slog.String("request_id", "abc")))
+	FromContext(ctx).Info("message", slog.Int("rows", 3))
+
+	var entry map[string]interface{}
+	assertions.NoError(json.Unmarshal(output.Bytes(), &entry))
+	assertions.Equal("abc", entry["request_id"])
+	assertions.Equal(float64(3), entry["rows"])
+}
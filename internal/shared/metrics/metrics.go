@@ -0,0 +1,248 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets latency buckets in seconds, from 1ms to 10s
+var DefaultBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type descriptor struct {
+	metricName string
+	help       string
+	kind       string
+	labels     []string
+}
+
+func (d descriptor) name() string {
+	return d.metricName
+}
+
+func (d descriptor) writeHeader(writer *bufio.Writer) {
+	fmt.Fprintf(writer, "# HELP %s %s\n# TYPE %s %s\n", d.metricName, escapeHelp(d.help), d.metricName, d.kind)
+}
+
+func (d descriptor) key(labelValues []string) string {
+	if len(labelValues) != len(d.labels) {
+		panic(fmt.Sprintf("metric %s expects %d label values, got %d", d.metricName, len(d.labels), len(labelValues)))
+	}
+
+	return strings.Join(labelValues, "\xff")
+}
+
+type CounterVec struct {
+	descriptor
+	mu     sync.Mutex
+	values map[string]*counterValue
+}
+
+type counterValue struct {
+	labelValues []string
+	value       float64
+}
+
+func (r *Registry) NewCounter(name, help string, labels ...string) *CounterVec {
+	counter := &CounterVec{
+		descriptor: descriptor{metricName: name, help: help, kind: "counter", labels: labels},
+		values:     make(map[string]*counterValue),
+	}
+	r.register(counter)
+
+	return counter
+}
+
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *CounterVec) Add(value float64, labelValues ...string) {
+	key := c.key(labelValues)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	current, ok := c.values[key]
+	if !ok {
+		current = &counterValue{labelValues: append([]string(nil), labelValues...)}
+		c.values[key] = current
+	}
+
+	current.value += value
+}
+
+// Value current value for the given labels, mostly useful in tests
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if current, ok := c.values[c.key(labelValues)]; ok {
+		return current.value
+	}
+
+	return 0
+}
+
+func (c *CounterVec) write(writer *bufio.Writer) {
+	c.writeHeader(writer)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range sortedKeys(c.values) {
+		current := c.values[key]
+		fmt.Fprintf(writer, "%s%s %s\n", c.metricName, formatLabels(c.labels, current.labelValues), formatValue(current.value))
+	}
+}
+
+type HistogramVec struct {
+	descriptor
+	buckets []float64
+	mu      sync.Mutex
+	values  map[string]*histogramValue
+}
+
+type histogramValue struct {
+	labelValues []string
+	counts      []uint64
+	sum         float64
+	count       uint64
+}
+
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	histogram := &HistogramVec{
+		descriptor: descriptor{metricName: name, help: help, kind: "histogram", labels: labels},
+		buckets:    append([]float64(nil), buckets...),
+		values:     make(map[string]*histogramValue),
+	}
+	sort.Float64s(histogram.buckets)
+	r.register(histogram)
+
+	return histogram
+}
+
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	key := h.key(labelValues)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	current, ok := h.values[key]
+	if !ok {
+		current = &histogramValue{labelValues: append([]string(nil), labelValues...), counts: make([]uint64, len(h.buckets))}
+		h.values[key] = current
+	}
+
+	for i, bucket := range h.buckets {
+		if value <= bucket {
+			current.counts[i]++
+		}
+	}
+
+	current.sum += value
+	current.count++
+}
+
+// Count number of observations for the given labels, mostly useful in tests
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if current, ok := h.values[h.key(labelValues)]; ok {
+		return current.count
+	}
+
+	return 0
+}
+
+func (h *HistogramVec) write(writer *bufio.Writer) {
+	h.writeHeader(writer)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	bucketLabels := append(append([]string(nil), h.labels...), "le")
+	for _, key := range sortedKeys(h.values) {
+		current := h.values[key]
+		for i, bucket := range h.buckets {
+			labels := formatLabels(bucketLabels, append(append([]string(nil), current.labelValues...), formatValue(bucket)))
+			fmt.Fprintf(writer, "%s_bucket%s %d\n", h.metricName, labels, current.counts[i])
+		}
+
+		infLabels := formatLabels(bucketLabels, append(append([]string(nil), current.labelValues...), "+Inf"))
+		fmt.Fprintf(writer, "%s_bucket%s %d\n", h.metricName, infLabels, current.count)
+		fmt.Fprintf(writer, "%s_sum%s %s\n", h.metricName, formatLabels(h.labels, current.labelValues), formatValue(current.sum))
+		fmt.Fprintf(writer, "%s_count%s %d\n", h.metricName, formatLabels(h.labels, current.labelValues), current.count)
+	}
+}
+
+// funcMetric reads its value when scraped, used to expose state owned by someone else such as connection pools
+type funcMetric struct {
+	descriptor
+	read func() float64
+}
+
+func (r *Registry) NewGaugeFunc(name, help string, read func() float64) {
+	r.register(&funcMetric{descriptor: descriptor{metricName: name, help: help, kind: "gauge"}, read: read})
+}
+
+func (r *Registry) NewCounterFunc(name, help string, read func() float64) {
+	r.register(&funcMetric{descriptor: descriptor{metricName: name, help: help, kind: "counter"}, read: read})
+}
+
+func (f *funcMetric) write(writer *bufio.Writer) {
+	f.writeHeader(writer)
+	fmt.Fprintf(writer, "%s %s\n", f.metricName, formatValue(f.read()))
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(names))
+	for i, name := range names {
+		pairs = append(pairs, name+`="`+escapeLabelValue(values[i])+`"`)
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+var (
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Render(t *testing.T) {
+	assertions := require.New(t)
+
+	registry := NewRegistry()
+	counter := registry.NewCounter("requests_total", "Requests served.", "route", "status")
+	histogram := registry.NewHistogram("latency_seconds", "Latency.", []float64{0.1, 1}, "route")
+	registry.NewGaugeFunc("open_connections", "Open connections.", func() float64 { return 3 })
+
+	counter.Inc("/products", "200")
+	counter.Add(2, "/products", "200")
+	counter.Inc(`/say "hi"`, "500")
+	histogram.Observe(0.05, "/products")
+	histogram.Observe(0.5, "/products")
+
+	var output strings.Builder
+	assertions.NoError(registry.Render(&output))
+
+	assertions.Equal(`# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="/products",le="0.1"} 1
+latency_seconds_bucket{route="/products",le="1"} 2
+latency_seconds_bucket{route="/products",le="+Inf"} 2
+latency_seconds_sum{route="/products"} 0.55
+latency_seconds_count{route="/products"} 2
+# HELP open_connections Open connections.
+# TYPE open_connections gauge
+open_connections 3
+# HELP requests_total Requests served.
+# TYPE requests_total counter
+requests_total{route="/products",status="200"} 3
+requests_total{route="/say \"hi\"",status="500"} 1
+`, output.String())
+}
+
+func TestCounterVec_WrongLabelsPanics(t *testing.T) {
+	counter := NewRegistry().NewCounter("requests_total", "Requests served.", "route")
+
+	require.Panics(t, func() { counter.Inc("/products", "200") })
+}
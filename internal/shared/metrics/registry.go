@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// Registry holds metrics and renders them in the Prometheus text exposition format
+type Registry struct {
+	mu      sync.RWMutex
+	metrics map[string]metric
+}
+
+type metric interface {
+	name() string
+	write(writer *bufio.Writer)
+}
+
+// Default registry used by package level metrics and served at /metrics
+var Default = NewRegistry()
+
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]metric)}
+}
+
+// register a metric with an already registered name replaces the previous one, this allows wiring the same collectors
+// again when a server is rebuilt
+func (r *Registry) register(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.metrics[m.name()] = m
+}
+
+// Render writes every registered metric sorted by name
+func (r *Registry) Render(writer io.Writer) error {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+
+	metrics := make([]metric, 0, len(names))
+	sort.Strings(names)
+	for _, name := range names {
+		metrics = append(metrics, r.metrics[name])
+	}
+	r.mu.RUnlock()
+
+	buffered := bufio.NewWriter(writer)
+	for _, m := range metrics {
+		m.write(buffered)
+	}
+
+	return buffered.Flush()
+}
+
+// Handler serves the registry for Prometheus scrapes
+func Handler(registry *Registry) http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		response.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		response.WriteHeader(http.StatusOK)
+		_ = registry.Render(response)
+	}
+}
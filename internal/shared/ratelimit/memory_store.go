@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepEvery takes between removals of idle buckets, it keeps clients seen once from growing the store forever
+const sweepEvery = 1024
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	fullAt    time.Time
+}
+
+// MemoryStore keeps the buckets in the process, they are lost on restart
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]bucket
+	takes   int
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]bucket)}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.takes++
+	if s.takes%sweepEvery == 0 {
+		s.sweep(now)
+	}
+
+	tokens := float64(limit.Burst)
+	if current, ok := s.buckets[key]; ok {
+		tokens = refill(current.tokens, current.updatedAt, now, limit)
+	}
+
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+	}
+
+	s.buckets[key] = bucket{tokens: tokens, updatedAt: now, fullAt: now.Add(limit.fullAfter())}
+
+	return newResult(tokens, allowed, limit), nil
+}
+
+// Len number of buckets kept
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.buckets)
+}
+
+// sweep forgets full buckets, they are recreated full on the next request
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, current := range s.buckets {
+		if !now.Before(current.fullAt) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+const (
+	StoreMemory = "memory"
+	StoreSQLite = "sqlite"
+)
+
+// Limit token bucket settings, a bucket holds up to Burst tokens and is refilled with Rate tokens per second. Every
+// request takes a token and is rejected when none is left
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Enabled a zero rate or burst disables limiting
+func (l Limit) Enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// fullAfter how long an empty bucket takes to be full again, buckets idle for longer can be forgotten
+func (l Limit) fullAfter() time.Duration {
+	return seconds(float64(l.Burst) / l.Rate)
+}
+
+type Result struct {
+	Allowed bool
+	Limit   int
+	// Remaining requests allowed right now
+	Remaining int
+	// RetryAfter when the next request will be allowed, zero for allowed requests
+	RetryAfter time.Duration
+	// Reset when the bucket will be full again
+	Reset time.Duration
+}
+
+// Store keeps the buckets, Take must refill and take from the bucket of key atomically so concurrent requests can't
+// take the same token
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
+
+// refill tokens in a bucket last updated at updatedAt, never more than the burst
+func refill(tokens float64, updatedAt, now time.Time, limit Limit) float64 {
+	elapsed := now.Sub(updatedAt).Seconds()
+	if elapsed < 0 {
+		elapsed = 0
+	}
+
+	return math.Min(float64(limit.Burst), tokens+elapsed*limit.Rate)
+}
+
+// newResult describes a bucket left with tokens after a request was allowed or rejected
+func newResult(tokens float64, allowed bool, limit Limit) Result {
+	result := Result{
+		Allowed:   allowed,
+		Limit:     limit.Burst,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(limit.Burst) - tokens) / limit.Rate),
+	}
+
+	if !allowed {
+		result.RetryAfter = seconds((1 - tokens) / limit.Rate)
+	}
+
+	return result
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(math.Ceil(value * float64(time.Second)))
+}
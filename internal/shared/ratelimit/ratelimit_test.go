@@ -0,0 +1,122 @@
+package ratelimit
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go-products.com/m/internal/product/infrastructure/persistance/migrations"
+	"go-products.com/m/internal/shared/database"
+)
+
+func newSQLiteStore(t *testing.T) *SQLiteStore {
+	db, err := database.GenerateDatabaseConnection(database.DatabaseConnection{
+		DatabaseName:       filepath.Join(t.TempDir(), "products.db"),
+		MaxOpenConnections: 1,
+	}, migrations.CreateProductsDatabase)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	return NewSQLiteStore(db)
+}
+
+func stores(t *testing.T) map[string]Store {
+	return map[string]Store{
+		"memory": NewMemoryStore(),
+		"sqlite": newSQLiteStore(t),
+	}
+}
+
+func TestStore_Take(t *testing.T) {
+	limit := Limit{Rate: 2, Burst: 3}
+	start := time.UnixMilli(1_700_000_000_000)
+
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			assertions := require.New(t)
+			ctx := context.Background()
+
+			for remaining := 2; remaining >= 0; remaining-- {
+				result, err := store.Take(ctx, "client", limit, start)
+				assertions.NoError(err)
+				assertions.True(result.Allowed)
+				assertions.Equal(remaining, result.Remaining)
+				assertions.Equal(3, result.Limit)
+			}
+
+			result, err := store.Take(ctx, "client", limit, start)
+			assertions.NoError(err)
+			assertions.Equal(Result{Allowed: false, Limit: 3, Remaining: 0, RetryAfter: 500 * time.Millisecond, Reset: 1500 * time.Millisecond}, result)
+
+			other, err := store.Take(ctx, "other-client", limit, start)
+			assertions.NoError(err)
+			assertions.True(other.Allowed, "buckets are kept by key")
+
+			// half a second refills one token
+			result, err = store.Take(ctx, "client", limit, start.Add(500*time.Millisecond))
+			assertions.NoError(err)
+			assertions.True(result.Allowed)
+			assertions.Equal(0, result.Remaining)
+
+			// refills never exceed the burst
+			result, err = store.Take(ctx, "client", limit, start.Add(time.Hour))
+			assertions.NoError(err)
+			assertions.Equal(2, result.Remaining)
+			assertions.Equal(500*time.Millisecond, result.Reset)
+		})
+	}
+}
+
+func TestStore_TakeConcurrently(t *testing.T) {
+	limit := Limit{Rate: 0.001, Burst: 10}
+	now := time.Now()
+
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			assertions := require.New(t)
+
+			var wg sync.WaitGroup
+			var mu sync.Mutex
+			allowed := 0
+			for i := 0; i < 30; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+
+					result, err := store.Take(context.Background(), "client", limit, now)
+					assertions.NoError(err)
+
+					mu.Lock()
+					defer mu.Unlock()
+					if result.Allowed {
+						allowed++
+					}
+				}()
+			}
+			wg.Wait()
+
+			assertions.Equal(10, allowed)
+		})
+	}
+}
+
+func TestMemoryStore_ForgetsFullBuckets(t *testing.T) {
+	assertions := require.New(t)
+
+	store := NewMemoryStore()
+	limit := Limit{Rate: 1, Burst: 1}
+	now := time.Now()
+	for i := 0; i < sweepEvery-1; i++ {
+		_, err := store.Take(context.Background(), string(rune('a'+i%26))+time.Duration(i).String(), limit, now)
+		assertions.NoError(err)
+	}
+	assertions.Equal(sweepEvery-1, store.Len())
+
+	_, err := store.Take(context.Background(), "late", limit, now.Add(time.Minute))
+	assertions.NoError(err)
+	assertions.Equal(1, store.Len())
+}
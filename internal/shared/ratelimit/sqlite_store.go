@@ -0,0 +1,48 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+	"time"
+)
+
+// takeQuery refills and takes from the bucket in a single statement so concurrent requests, even from other
+// processes sharing the database, can't take the same token. ?1 key, ?2 burst, ?3 now in milliseconds, ?4 rate per
+// second, ?5 when the bucket will be full
+const takeQuery = `INSERT INTO rate_limits (key, tokens, allowed, updated_at, full_at) VALUES (?1, ?2 - 1, 1, ?3, ?5)
+ON CONFLICT (key) DO UPDATE SET
+	tokens = min(?2, tokens + max(?3 - updated_at, 0) * ?4 / 1000.0) - (min(?2, tokens + max(?3 - updated_at, 0) * ?4 / 1000.0) >= 1),
+	allowed = min(?2, tokens + max(?3 - updated_at, 0) * ?4 / 1000.0) >= 1,
+	updated_at = ?3,
+	full_at = ?5
+RETURNING tokens, allowed;`
+
+// SQLiteStore keeps the buckets in the rate_limits table so limits survive restarts and are shared between processes.
+// Concurrent writers fail with SQLITE_BUSY unless the pool has a single connection or a busy_timeout pragma is set
+type SQLiteStore struct {
+	db    *sql.DB
+	takes atomic.Int64
+}
+
+func NewSQLiteStore(db *sql.DB) *SQLiteStore {
+	return &SQLiteStore{db: db}
+}
+
+func (s *SQLiteStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	if s.takes.Add(1)%sweepEvery == 0 {
+		if _, err := s.db.ExecContext(ctx, "DELETE FROM rate_limits WHERE full_at <= ?;", now.UnixMilli()); err != nil {
+			return Result{}, err
+		}
+	}
+
+	var tokens float64
+	var allowed bool
+	err := s.db.QueryRowContext(ctx, takeQuery, key, limit.Burst, now.UnixMilli(), limit.Rate, now.Add(limit.fullAfter()).UnixMilli()).
+		Scan(&tokens, &allowed)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return newResult(tokens, allowed, limit), nil
+}
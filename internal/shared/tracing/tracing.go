@@ -0,0 +1,88 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ExporterNone = "none"
+	ExporterOTLP = "otlp"
+
+	// instrumentationName tracer name used by every layer of the application
+	instrumentationName = "go-products.com/m"
+)
+
+type Options struct {
+	ServiceName string
+	// Exporter none disables exporting while still propagating trace context, otlp sends spans over OTLP/HTTP to the
+	// endpoint configured through the standard OTEL_EXPORTER_OTLP_* environment variables
+	Exporter string
+	// SampleRatio fraction of new traces recorded, traces started by a caller follow the caller decision
+	SampleRatio float64
+}
+
+// NewExporter builds the span exporter selected in options, nil when exporting is disabled
+func NewExporter(ctx context.Context, options Options) (sdktrace.SpanExporter, error) {
+	switch options.Exporter {
+	case "", ExporterNone:
+		return nil, nil
+	case ExporterOTLP:
+		return otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", options.Exporter)
+	}
+}
+
+// NewTracerProvider tracer provider sending spans to exporter, any sdktrace.SpanExporter can be plugged such as
+// tracetest.InMemoryExporter in tests. It is registered as the global provider together with the W3C trace context
+// propagator, callers must Shutdown it to flush pending spans
+func NewTracerProvider(exporter sdktrace.SpanExporter, options Options) *sdktrace.TracerProvider {
+	providerOptions := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(options.ServiceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(options.SampleRatio))),
+	}
+
+	if exporter != nil {
+		providerOptions = append(providerOptions, sdktrace.WithBatcher(exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(providerOptions...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider
+}
+
+// Start starts a span named name as child of the span in ctx
+func Start(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, options...)
+}
+
+// End records err in span when present and ends it. Errors reporting expected outcomes, such as a missing resource or
+// an invalid request, are answered to the caller and don't mark the span as failed
+func End(span trace.Span, err error) {
+	if err != nil && !isExpected(err) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// isExpected errors anywhere in the chain of err implementing Expected() bool tell whether they are expected
+func isExpected(err error) bool {
+	var expected interface{ Expected() bool }
+
+	return errors.As(err, &expected) && expected.Expected()
+}
@@ -0,0 +1,72 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNewExporter(t *testing.T) {
+	assertions := require.New(t)
+
+	exporter, err := NewExporter(context.Background(), Options{Exporter: ExporterNone})
+	assertions.NoError(err)
+	assertions.Nil(exporter)
+
+	exporter, err = NewExporter(context.Background(), Options{Exporter: ExporterOTLP})
+	assertions.NoError(err)
+	assertions.NotNil(exporter)
+	assertions.NoError(exporter.Shutdown(context.Background()))
+
+	_, err = NewExporter(context.Background(), Options{Exporter: "zipkin"})
+	assertions.Error(err)
+}
+
+// expectedError an outcome reported to the caller rather than a failure
+type expectedError struct{}
+
+func (expectedError) Error() string {
+	return "not found"
+}
+
+func (expectedError) Expected() bool {
+	return true
+}
+
+func TestEnd(t *testing.T) {
+	assertions := require.New(t)
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := NewTracerProvider(exporter, Options{ServiceName: "test", SampleRatio: 1})
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus codes.Code
+		expectedEvents int
+	}{
+		{name: "Succeeded", expectedStatus: codes.Unset},
+		{name: "Failed", err: errors.New("database is locked"), expectedStatus: codes.Error, expectedEvents: 1},
+		{name: "Expected", err: fmt.Errorf("getting product: %w", expectedError{}), expectedStatus: codes.Unset},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter.Reset()
+
+			_, span := Start(context.Background(), tt.name)
+			End(span, tt.err)
+
+			assertions.NoError(provider.ForceFlush(context.Background()))
+			spans := exporter.GetSpans()
+			assertions.Len(spans, 1)
+			assertions.Equal(tt.expectedStatus, spans[0].Status.Code)
+			assertions.Len(spans[0].Events, tt.expectedEvents)
+		})
+	}
+}